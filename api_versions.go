@@ -0,0 +1,59 @@
+package main
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// apiV1Prefix is the path prefix of version 1 of the HTTP API. A change
+// that breaks existing clients, such as removing or renaming a response
+// field, goes into a new version served alongside it; additions do not.
+const apiV1Prefix = "/api/v1"
+
+// legacyAPIDeprecatedAt is when the data endpoints served before the API
+// was versioned were superseded by their /api/v1 counterparts.
+var legacyAPIDeprecatedAt = time.Date(2023, 10, 28, 0, 0, 0, 0, time.UTC)
+
+var deprecatedRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "myapp_deprecated_requests_total",
+	Help: "Total number of requests to deprecated API routes",
+}, []string{"route"})
+
+// apiRoutes registers the data endpoints of the HTTP API.
+type apiRoutes struct {
+	app   *fiber.App
+	v1    fiber.Router
+	store *configStore
+}
+
+func newAPIRoutes(app *fiber.App, store *configStore) apiRoutes {
+	return apiRoutes{app: app, v1: app.Group(apiV1Prefix), store: store}
+}
+
+// legacy registers handlers for path under /api/v1 and, deprecated, at
+// path itself, where they were served before the API was versioned.
+func (r apiRoutes) legacy(method, path string, handlers ...fiber.Handler) {
+	r.v1.Add(method, path, handlers...)
+	r.app.Add(method, path, append([]fiber.Handler{deprecatedRoute(r.store, apiV1Prefix)}, handlers...)...)
+}
+
+// deprecatedRoute is middleware for routes superseded by the same path
+// under successor. Responses carry a Deprecation header and a Link to the
+// successor, and each use is counted in myapp_deprecated_requests_total
+// so that remaining clients can be found. Once the api.legacy_routes flag
+// is switched off, the routes answer 410 Gone.
+func deprecatedRoute(store *configStore, successor string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Set("Deprecation", "@"+strconv.FormatInt(legacyAPIDeprecatedAt.Unix(), 10))
+		c.Set(fiber.HeaderLink, "<"+successor+c.OriginalURL()+`>; rel="successor-version"`)
+		deprecatedRequests.WithLabelValues(c.Route().Path).Inc()
+		if !store.Get().Flags.Enabled(flagLegacyRoutes) {
+			return fiber.NewError(fiber.StatusGone, "this endpoint has moved to "+successor+c.Path())
+		}
+		return c.Next()
+	}
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// ArchiveConfig names the S3-compatible bucket that raw API responses are
+// copied to. Endpoint is only needed for non-AWS stores such as MinIO,
+// which usually also want PathStyle. Credentials and region come from the
+// same default AWS provider chain as the secret backends. Archival is off
+// while Bucket is empty.
+type ArchiveConfig struct {
+	Bucket    string
+	Prefix    string
+	Endpoint  string
+	PathStyle bool
+}
+
+func (a ArchiveConfig) Enabled() bool {
+	return a.Bucket != ""
+}
+
+// responseArchiver uploads response bodies to the configured bucket.
+// Collectors share the single responseArchive through doRequest.
+type responseArchiver struct {
+	mu     sync.RWMutex
+	cfg    ArchiveConfig
+	client *s3.Client
+}
+
+var responseArchive = &responseArchiver{}
+
+// Configure points the archiver at cfg's bucket, or disables it. The S3
+// client is only rebuilt when the archive settings change.
+func (a *responseArchiver) Configure(ctx context.Context, cfg *Config) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if !cfg.Archive.Enabled() {
+		a.cfg, a.client = cfg.Archive, nil
+		return nil
+	}
+	if a.client != nil && a.cfg == cfg.Archive {
+		return nil
+	}
+
+	client, err := newS3Client(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	a.cfg, a.client = cfg.Archive, client
+	return nil
+}
+
+// newS3Client returns a client for the S3-compatible store described by
+// cfg.AWS and the archive's Endpoint and PathStyle settings, which also
+// apply to Parquet exports.
+func newS3Client(ctx context.Context, cfg *Config) (*s3.Client, error) {
+	awsCfg, err := loadAWSConfig(ctx, cfg.AWS)
+	if err != nil {
+		return nil, err
+	}
+	return s3.NewFromConfig(awsCfg, func(o *s3.Options) {
+		if cfg.Archive.Endpoint != "" {
+			o.BaseEndpoint = aws.String(cfg.Archive.Endpoint)
+		}
+		o.UsePathStyle = cfg.Archive.PathStyle
+	}), nil
+}
+
+// Save uploads body under
+// <prefix>/<source>/<framework>/<yyyy>/<mm>/<dd>/<time>-<hash>.<ext>, where
+// source and framework come from the request's fetch scope and hash is
+// derived from the request URL. Failures are logged rather than returned so
+// that archival never interrupts collection.
+func (a *responseArchiver) Save(req *http.Request, header http.Header, body []byte) {
+	a.mu.RLock()
+	cfg, client := a.cfg, a.client
+	a.mu.RUnlock()
+	if client == nil {
+		return
+	}
+
+	scope := fetchScopeFrom(req.Context())
+	source, framework := scope.Source, scope.Framework
+	if source == "" {
+		source = req.URL.Hostname()
+	}
+	if framework == "" {
+		framework = "all"
+	}
+
+	now := time.Now().UTC()
+	sum := sha1.Sum([]byte(req.URL.String()))
+	name := fmt.Sprintf("%s-%s%s", now.Format("150405.000000000"), hex.EncodeToString(sum[:6]), archiveExtension(header.Get("Content-Type")))
+	key := path.Join(cfg.Prefix, source, framework, now.Format("2006/01/02"), name)
+
+	_, err := client.PutObject(req.Context(), &s3.PutObjectInput{
+		Bucket:      aws.String(cfg.Bucket),
+		Key:         aws.String(key),
+		Body:        bytes.NewReader(body),
+		ContentType: aws.String(header.Get("Content-Type")),
+		Metadata:    map[string]string{"source-url": redactedURL(req.URL)},
+	})
+	if err != nil {
+		log.Printf("Error archiving response from %s: %v", redactedURL(req.URL), err)
+	}
+}
+
+func archiveExtension(contentType string) string {
+	switch {
+	case strings.Contains(contentType, "json"):
+		return ".json"
+	case strings.Contains(contentType, "xml"):
+		return ".xml"
+	default:
+		return ".raw"
+	}
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"gorm.io/gorm"
+)
+
+var arxivAPICalls = promauto.NewCounter(prometheus.CounterOpts{
+	Name: "myapp_arxiv_api_calls_total",
+	Help: "Total number of API calls to the arXiv API",
+})
+
+// arxivRequestInterval is the delay between consecutive arXiv queries
+// asked for by the API's terms of use.
+const arxivRequestInterval = 3 * time.Second
+
+// Paper is an arXiv paper matching one of a framework's search terms.
+type Paper struct {
+	ArxivID    string    `json:"arxiv_id" gorm:"primaryKey"`
+	Framework  string    `json:"framework" gorm:"primaryKey"`
+	Query      string    `json:"query"`
+	Title      string    `json:"title"`
+	Abstract   string    `json:"abstract"`
+	Authors    []string  `json:"authors" gorm:"serializer:json"`
+	Categories []string  `json:"categories" gorm:"serializer:json"`
+	URL        string    `json:"url"`
+	PDFURL     string    `json:"pdf_url"`
+	Published  time.Time `json:"published"`
+	Updated    time.Time `json:"updated"`
+}
+
+type arxivFeed struct {
+	Entries []struct {
+		ID        string    `xml:"id"`
+		Title     string    `xml:"title"`
+		Summary   string    `xml:"summary"`
+		Published time.Time `xml:"published"`
+		Updated   time.Time `xml:"updated"`
+		Authors   []struct {
+			Name string `xml:"name"`
+		} `xml:"author"`
+		Links []struct {
+			Href  string `xml:"href,attr"`
+			Rel   string `xml:"rel,attr"`
+			Title string `xml:"title,attr"`
+		} `xml:"link"`
+		Categories []struct {
+			Term string `xml:"term,attr"`
+		} `xml:"category"`
+	} `xml:"entry"`
+}
+
+func init() {
+	registerSource(func(cfg *Config) Source { return arxivSource{cfg} })
+}
+
+// arxivSource collects papers matching a framework's arXiv search terms.
+type arxivSource struct{ cfg *Config }
+
+func (arxivSource) Name() string { return "arxiv" }
+
+func (s arxivSource) Fetch(ctx context.Context, framework Framework) ([]Item, error) {
+	papers, err := fetchArxivPapers(ctx, s.cfg, framework)
+	return asItems(papers), err
+}
+
+// fetchArxivPapers returns the most recently submitted papers for each of
+// the framework's search terms, matched as phrases against all fields.
+func fetchArxivPapers(ctx context.Context, cfg *Config, framework Framework) ([]Paper, error) {
+	var papers []Paper
+	for i, term := range framework.ArxivQueries {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				return papers, ctx.Err()
+			case <-time.After(arxivRequestInterval):
+			}
+		}
+		arxivAPICalls.Inc()
+
+		query := url.Values{
+			"search_query": {fmt.Sprintf("all:%q", term)},
+			"sortBy":       {"submittedDate"},
+			"sortOrder":    {"descending"},
+			"max_results":  {fmt.Sprint(cfg.FetchLimit)},
+		}
+		var feed arxivFeed
+		if err := getXML(ctx, "https://export.arxiv.org/api/query?"+query.Encode(), &feed); err != nil {
+			return papers, err
+		}
+
+		for _, e := range feed.Entries {
+			paper := Paper{
+				ArxivID:   strings.TrimPrefix(strings.TrimPrefix(e.ID, "http://arxiv.org/abs/"), "https://arxiv.org/abs/"),
+				Framework: framework.Name,
+				Query:     term,
+				Title:     strings.Join(strings.Fields(e.Title), " "),
+				Abstract:  strings.TrimSpace(e.Summary),
+				URL:       e.ID,
+				Published: e.Published,
+				Updated:   e.Updated,
+			}
+			for _, a := range e.Authors {
+				paper.Authors = append(paper.Authors, a.Name)
+			}
+			for _, c := range e.Categories {
+				paper.Categories = append(paper.Categories, c.Term)
+			}
+			for _, l := range e.Links {
+				if l.Title == "pdf" {
+					paper.PDFURL = l.Href
+				}
+			}
+			papers = append(papers, paper)
+		}
+	}
+	return papers, nil
+}
+
+func (paper Paper) Save(db *gorm.DB) error {
+	return upsert(db, &paper)
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"crypto/subtle"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// adminAuth protects operator endpoints with the bearer token from
+// ADMIN_TOKEN, or a JWT with the admin role. When no token is configured
+// the endpoints are disabled, except to such JWTs.
+func adminAuth(store *configStore) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if p := principalOf(c); p != nil && p.Role >= roleAdmin {
+			return c.Next()
+		}
+		want := store.Get().AdminToken
+		if want == "" {
+			return fiber.NewError(fiber.StatusForbidden, "admin endpoints are disabled; set ADMIN_TOKEN to enable them")
+		}
+
+		got, ok := strings.CutPrefix(c.Get(fiber.HeaderAuthorization), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(want)) != 1 {
+			return fiber.NewError(fiber.StatusUnauthorized, "invalid or missing admin token")
+		}
+		return c.Next()
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+)
+
+// AWSConfig locates credentials in AWS Secrets Manager or SSM Parameter
+// Store. AWS credentials themselves come from the default provider chain
+// (environment, shared config, or the ECS/EKS task role).
+type AWSConfig struct {
+	Region   string
+	SecretID string
+	SSMPath  string
+}
+
+func loadAWSConfig(ctx context.Context, ac AWSConfig) (aws.Config, error) {
+	var opts []func(*awsconfig.LoadOptions) error
+	if ac.Region != "" {
+		opts = append(opts, awsconfig.WithRegion(ac.Region))
+	}
+	awsCfg, err := awsconfig.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return aws.Config{}, fmt.Errorf("loading AWS config: %w", err)
+	}
+	return awsCfg, nil
+}
+
+// secretsManagerBackend reads a single secret whose string value is a JSON
+// object of secret keys to values.
+type secretsManagerBackend struct {
+	client   *secretsmanager.Client
+	secretID string
+}
+
+func newSecretsManagerBackend(ctx context.Context, ac AWSConfig) (SecretBackend, error) {
+	awsCfg, err := loadAWSConfig(ctx, ac)
+	if err != nil {
+		return nil, err
+	}
+	return secretsManagerBackend{client: secretsmanager.NewFromConfig(awsCfg), secretID: ac.SecretID}, nil
+}
+
+func (b secretsManagerBackend) Secrets(ctx context.Context) (map[string]string, error) {
+	out, err := b.client.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{
+		SecretId: aws.String(b.secretID),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("reading secret %s: %w", b.secretID, err)
+	}
+
+	values := map[string]string{}
+	if err := json.Unmarshal([]byte(aws.ToString(out.SecretString)), &values); err != nil {
+		return nil, fmt.Errorf("decoding secret %s: %w", b.secretID, err)
+	}
+	return values, nil
+}
+
+// ssmBackend reads every parameter under a path, using the last path
+// segment as the secret key (e.g. /my-assignment/github_token).
+type ssmBackend struct {
+	client *ssm.Client
+	path   string
+}
+
+func newSSMBackend(ctx context.Context, ac AWSConfig) (SecretBackend, error) {
+	awsCfg, err := loadAWSConfig(ctx, ac)
+	if err != nil {
+		return nil, err
+	}
+	return ssmBackend{client: ssm.NewFromConfig(awsCfg), path: ac.SSMPath}, nil
+}
+
+func (b ssmBackend) Secrets(ctx context.Context) (map[string]string, error) {
+	values := map[string]string{}
+	paginator := ssm.NewGetParametersByPathPaginator(b.client, &ssm.GetParametersByPathInput{
+		Path:           aws.String(b.path),
+		WithDecryption: aws.Bool(true),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("reading SSM parameters under %s: %w", b.path, err)
+		}
+		for _, p := range page.Parameters {
+			values[path.Base(aws.ToString(p.Name))] = aws.ToString(p.Value)
+		}
+	}
+	return values, nil
+}
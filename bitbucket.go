@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"gorm.io/gorm"
+)
+
+var bitbucketAPICalls = promauto.NewCounter(prometheus.CounterOpts{
+	Name: "myapp_bitbucket_api_calls_total",
+	Help: "Total number of API calls to Bitbucket Cloud",
+})
+
+// BitbucketConfig holds optional Bitbucket Cloud credentials. Public issue
+// trackers can be read anonymously; an app password is needed for private
+// repositories.
+type BitbucketConfig struct {
+	Username    string
+	AppPassword string
+}
+
+// BitbucketIssue is an issue from a framework's Bitbucket issue tracker.
+// Issue IDs are only unique within a repository.
+type BitbucketIssue struct {
+	Repo      string    `json:"repo" gorm:"primaryKey"`
+	ID        int       `json:"id" gorm:"primaryKey;autoIncrement:false"`
+	Framework string    `json:"framework" gorm:"index"`
+	Title     string    `json:"title"`
+	Body      string    `json:"body"`
+	State     string    `json:"state"`
+	Kind      string    `json:"kind"`
+	Priority  string    `json:"priority"`
+	Reporter  string    `json:"reporter"`
+	Votes     int       `json:"votes"`
+	WebURL    string    `json:"web_url"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func init() {
+	registerSource(func(cfg *Config) Source { return bitbucketSource{cfg} })
+}
+
+// bitbucketSource collects issues from a framework's Bitbucket repository.
+type bitbucketSource struct{ cfg *Config }
+
+func (bitbucketSource) Name() string { return "bitbucket" }
+
+func (s bitbucketSource) Fetch(ctx context.Context, framework Framework) ([]Item, error) {
+	issues, err := fetchBitbucketIssues(ctx, s.cfg, framework)
+	return asItems(issues), err
+}
+
+// fetchBitbucketIssues returns the most recently updated issues of the
+// framework's Bitbucket repository.
+func fetchBitbucketIssues(ctx context.Context, cfg *Config, framework Framework) ([]BitbucketIssue, error) {
+	if framework.BitbucketRepo == "" {
+		return nil, nil
+	}
+	bitbucketAPICalls.Inc()
+
+	var result struct {
+		Values []struct {
+			ID       int    `json:"id"`
+			Title    string `json:"title"`
+			State    string `json:"state"`
+			Kind     string `json:"kind"`
+			Priority string `json:"priority"`
+			Votes    int    `json:"votes"`
+			Content  struct {
+				Raw string `json:"raw"`
+			} `json:"content"`
+			Reporter *struct {
+				DisplayName string `json:"display_name"`
+			} `json:"reporter"`
+			Links struct {
+				HTML struct {
+					Href string `json:"href"`
+				} `json:"html"`
+			} `json:"links"`
+			CreatedOn time.Time `json:"created_on"`
+			UpdatedOn time.Time `json:"updated_on"`
+		} `json:"values"`
+	}
+
+	query := url.Values{
+		"sort":    {"-updated_on"},
+		"pagelen": {fmt.Sprint(cfg.FetchLimit)},
+	}
+	u := fmt.Sprintf("https://api.bitbucket.org/2.0/repositories/%s/issues?%s", framework.BitbucketRepo, query.Encode())
+	var header http.Header
+	if cfg.Bitbucket.AppPassword != "" {
+		header = http.Header{}
+		header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(cfg.Bitbucket.Username+":"+cfg.Bitbucket.AppPassword)))
+	}
+	if err := getJSON(ctx, u, header, &result); err != nil {
+		return nil, err
+	}
+
+	issues := make([]BitbucketIssue, 0, len(result.Values))
+	for _, v := range result.Values {
+		issue := BitbucketIssue{
+			Repo:      framework.BitbucketRepo,
+			ID:        v.ID,
+			Framework: framework.Name,
+			Title:     v.Title,
+			Body:      v.Content.Raw,
+			State:     v.State,
+			Kind:      v.Kind,
+			Priority:  v.Priority,
+			Votes:     v.Votes,
+			WebURL:    v.Links.HTML.Href,
+			CreatedAt: v.CreatedOn,
+			UpdatedAt: v.UpdatedOn,
+		}
+		if v.Reporter != nil {
+			issue.Reporter = v.Reporter.DisplayName
+		}
+		issues = append(issues, issue)
+	}
+	return issues, nil
+}
+
+func (issue BitbucketIssue) Save(db *gorm.DB) error {
+	return upsert(db, &issue)
+}
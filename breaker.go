@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/sony/gobreaker"
+)
+
+var sourceBreakerState = promauto.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "myapp_source_breaker_state",
+	Help: "State of each source's circuit breaker: 0 closed, 1 half-open, 2 open",
+}, []string{"source"})
+
+// breakerRegistry keeps a circuit breaker per source. After a source's
+// fetches fail the configured number of times in a row, its breaker opens
+// and the source is not called for the cooldown; a single trial fetch then
+// closes it again or reopens it. Other sources are collected as usual.
+type breakerRegistry struct {
+	mu       sync.Mutex
+	failures int
+	cooldown time.Duration
+	breakers map[string]*gobreaker.CircuitBreaker
+}
+
+var sourceBreakers = &breakerRegistry{}
+
+// Configure sets the consecutive failures that open a breaker, zero
+// disabling them, and how long it stays open. Changing either resets
+// every breaker.
+func (r *breakerRegistry) Configure(failures int, cooldown time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.breakers != nil && failures == r.failures && cooldown == r.cooldown {
+		return
+	}
+	r.failures, r.cooldown = failures, cooldown
+	r.breakers = map[string]*gobreaker.CircuitBreaker{}
+	sourceBreakerState.Reset()
+}
+
+// breaker returns the breaker of source, or nil when breakers are
+// disabled.
+func (r *breakerRegistry) breaker(source string) *gobreaker.CircuitBreaker {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.failures <= 0 {
+		return nil
+	}
+	if r.breakers == nil {
+		r.breakers = map[string]*gobreaker.CircuitBreaker{}
+	}
+	cb := r.breakers[source]
+	if cb == nil {
+		failures := uint32(r.failures)
+		cb = gobreaker.NewCircuitBreaker(gobreaker.Settings{
+			Name:    source,
+			Timeout: r.cooldown,
+			ReadyToTrip: func(counts gobreaker.Counts) bool {
+				return counts.ConsecutiveFailures >= failures
+			},
+			OnStateChange: func(name string, from, to gobreaker.State) {
+				log.Printf("Circuit breaker for %s source: %s -> %s", name, from, to)
+				sourceBreakerState.WithLabelValues(name).Set(float64(to))
+			},
+		})
+		r.breakers[source] = cb
+		sourceBreakerState.WithLabelValues(source).Set(float64(gobreaker.StateClosed))
+	}
+	return cb
+}
+
+// Fetch calls src.Fetch for framework through the source's breaker. While
+// the breaker is open it fails with gobreaker.ErrOpenState without calling
+// the source. A fetch cut short by ctx does not count against the source.
+func (r *breakerRegistry) Fetch(ctx context.Context, src Source, framework Framework) ([]Item, error) {
+	cb := r.breaker(src.Name())
+	if cb == nil {
+		return src.Fetch(ctx, framework)
+	}
+
+	var items []Item
+	var fetchErr error
+	_, err := cb.Execute(func() (interface{}, error) {
+		items, fetchErr = src.Fetch(ctx, framework)
+		if ctx.Err() != nil {
+			return nil, nil
+		}
+		return nil, fetchErr
+	})
+	if fetchErr == nil && err != nil {
+		// The breaker refused the call.
+		return nil, err
+	}
+	return items, fetchErr
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// cacheGenerationKey holds a counter that is part of every cache key.
+// Bumping it invalidates all cached responses at once without scanning.
+const cacheGenerationKey = "cache:generation"
+
+// CacheConfig enables the Redis response cache for read endpoints. TTL
+// applies to every cached route unless RouteTTLs has an entry whose key is
+// a prefix of the request path; the longest matching prefix wins. Caching
+// is off while RedisURL is empty.
+type CacheConfig struct {
+	RedisURL  string
+	TTL       time.Duration
+	RouteTTLs map[string]time.Duration
+}
+
+func (c CacheConfig) Enabled() bool {
+	return c.RedisURL != ""
+}
+
+// ttlFor returns the TTL for a request path. Prefixes also match the
+// path without its API version, so that /search covers /api/v1/search.
+func (c CacheConfig) ttlFor(path string) time.Duration {
+	ttl, longest := c.TTL, -1
+	unversioned := strings.TrimPrefix(path, apiV1Prefix)
+	for prefix, d := range c.RouteTTLs {
+		if (strings.HasPrefix(path, prefix) || strings.HasPrefix(unversioned, prefix)) && len(prefix) > longest {
+			ttl, longest = d, len(prefix)
+		}
+	}
+	return ttl
+}
+
+// parseRouteTTLs parses CACHE_ROUTE_TTLS, a comma-separated list of
+// path-prefix=duration pairs such as /search=30s,/frameworks=5m.
+func parseRouteTTLs(value string) (map[string]time.Duration, error) {
+	ttls := map[string]time.Duration{}
+	for _, entry := range splitList(value) {
+		prefix, raw, ok := strings.Cut(entry, "=")
+		if !ok || !strings.HasPrefix(prefix, "/") {
+			return nil, fmt.Errorf("CACHE_ROUTE_TTLS entry %q must look like /path=30s", entry)
+		}
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return nil, fmt.Errorf("CACHE_ROUTE_TTLS entry %q: %w", entry, err)
+		}
+		ttls[prefix] = d
+	}
+	return ttls, nil
+}
+
+// responseCache caches successful GET responses in Redis. It fails open:
+// when Redis is unreachable, requests are served uncached.
+type responseCache struct {
+	mu     sync.RWMutex
+	cfg    CacheConfig
+	url    string
+	client *redis.Client
+}
+
+var readCache = &responseCache{}
+
+// Configure connects to cfg's Redis, or disables the cache. The client is
+// only replaced when the Redis URL changes.
+func (rc *responseCache) Configure(cfg *Config) error {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	rc.cfg = cfg.Cache
+	if rc.client != nil && rc.url == cfg.Cache.RedisURL {
+		return nil
+	}
+	if rc.client != nil {
+		rc.client.Close()
+		rc.client = nil
+	}
+	rc.url = cfg.Cache.RedisURL
+	if !cfg.Cache.Enabled() {
+		return nil
+	}
+
+	opts, err := redis.ParseURL(cfg.Cache.RedisURL)
+	if err != nil {
+		return fmt.Errorf("parsing REDIS_URL: %w", err)
+	}
+	rc.client = redis.NewClient(opts)
+	return nil
+}
+
+func (rc *responseCache) snapshot() (CacheConfig, *redis.Client) {
+	rc.mu.RLock()
+	defer rc.mu.RUnlock()
+	return rc.cfg, rc.client
+}
+
+// Ping checks that Redis is reachable.
+func (rc *responseCache) Ping(ctx context.Context) error {
+	_, client := rc.snapshot()
+	if client == nil {
+		return errors.New("cache is not configured")
+	}
+	return client.Ping(ctx).Err()
+}
+
+// Middleware serves cached copies of the route's responses, keyed by
+// method, path and query string, and stores successful responses for the
+// route's TTL. The X-Cache header reports HIT or MISS.
+func (rc *responseCache) Middleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		cfg, client := rc.snapshot()
+		ttl := cfg.ttlFor(c.Path())
+		if client == nil || ttl <= 0 || c.Method() != fiber.MethodGet {
+			return c.Next()
+		}
+
+		ctx := c.UserContext()
+		generation, err := client.Get(ctx, cacheGenerationKey).Result()
+		if err != nil && !errors.Is(err, redis.Nil) {
+			log.Printf("Response cache unavailable: %v", err)
+			return c.Next()
+		}
+		key := "cache:" + generation + ":" + c.OriginalURL()
+
+		if cached, err := client.Get(ctx, key).Bytes(); err == nil {
+			if contentType, body, ok := bytes.Cut(cached, []byte("\n")); ok {
+				c.Set("X-Cache", "HIT")
+				c.Set(fiber.HeaderContentType, string(contentType))
+				return c.Send(body)
+			}
+		}
+
+		c.Set("X-Cache", "MISS")
+		if err := c.Next(); err != nil {
+			return err
+		}
+		if c.Response().StatusCode() != fiber.StatusOK {
+			return nil
+		}
+		contentType, body := c.Response().Header.ContentType(), c.Response().Body()
+		entry := make([]byte, 0, len(contentType)+1+len(body))
+		entry = append(append(append(entry, contentType...), '\n'), body...)
+		if err := client.Set(ctx, key, entry, ttl).Err(); err != nil {
+			log.Printf("Error caching %s: %v", c.Path(), err)
+		}
+		return nil
+	}
+}
+
+// Invalidate drops every cached response, e.g. after a fetch has stored
+// new data.
+func (rc *responseCache) Invalidate(ctx context.Context) {
+	_, client := rc.snapshot()
+	if client == nil {
+		return
+	}
+	if err := client.Incr(ctx, cacheGenerationKey).Err(); err != nil {
+		log.Printf("Error invalidating response cache: %v", err)
+	}
+}
+
+// InvalidateAfter is middleware for routes that modify data read through
+// the cache; it invalidates the cache once the handler has succeeded.
+func (rc *responseCache) InvalidateAfter() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if err := c.Next(); err != nil {
+			return err
+		}
+		if status := c.Response().StatusCode(); status >= 200 && status < 300 {
+			rc.Invalidate(c.UserContext())
+		}
+		return nil
+	}
+}
@@ -0,0 +1,307 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/spf13/cobra"
+	"gorm.io/gorm"
+)
+
+func newRootCmd() *cobra.Command {
+	root := &cobra.Command{
+		Use:           "my-assignment",
+		Short:         "Collects StackOverflow and GitHub data for tracked frameworks",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return loadDotEnv(".env")
+		},
+	}
+
+	root.AddCommand(newServeCmd(), newFetchCmd(), newMigrateCmd(), newExportCmd(), newAPIKeyCmd())
+	return root
+}
+
+func newServeCmd() *cobra.Command {
+	var autoMigrate bool
+
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Run the HTTP API and Prometheus metrics servers",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := loadConfig()
+			if err != nil {
+				return err
+			}
+
+			db, err := connectDatabase(cfg)
+			if err != nil {
+				return err
+			}
+			if autoMigrate {
+				if err := migrateDatabase(db); err != nil {
+					return err
+				}
+				if err := partitionTables(db, cfg.Database.PartitionBy); err != nil {
+					return err
+				}
+			}
+			if err := seedFrameworks(db, cfg.Frameworks); err != nil {
+				return err
+			}
+
+			if cfg.SecretBackend == "vault" {
+				go renewVaultToken(context.Background(), cfg.Vault)
+			}
+
+			store := newConfigStore(cfg)
+			go store.Watch(context.Background())
+			fetchWorkers.Start(db, cfg)
+			fetchSchedule.Start(store)
+			go runDailySnapshots(context.Background(), db, store)
+			if cfg.ExportInterval > 0 {
+				go runScheduledExports(context.Background(), db, store, cfg.ExportInterval)
+			}
+
+			return runServer(db, store)
+		},
+	}
+
+	cmd.Flags().BoolVar(&autoMigrate, "auto-migrate", true, "apply schema migrations before starting")
+	return cmd
+}
+
+func newFetchCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "fetch",
+		Short: "Run a single collection pass and exit",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := loadConfig()
+			if err != nil {
+				return err
+			}
+
+			db, err := connectDatabase(cfg)
+			if err != nil {
+				return err
+			}
+
+			if err := seedFrameworks(db, cfg.Frameworks); err != nil {
+				return err
+			}
+
+			return fetchOnce(context.Background(), db, cfg)
+		},
+	}
+}
+
+// fetchOnce runs a collection pass and returns its outcome, so that the
+// exit status of fetch tells cron and CI jobs whether the pass succeeded.
+func fetchOnce(ctx context.Context, db *gorm.DB, cfg *Config) error {
+	job := newFetchJob("")
+	runFetch(ctx, db, cfg, job, fetchScope{})
+	return job.Err()
+}
+
+func newExportCmd() *cobra.Command {
+	var dest string
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Write stored posts and issues to partitioned Parquet files and exit",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := loadConfig()
+			if err != nil {
+				return err
+			}
+			if dest == "" {
+				dest = cfg.ExportDest
+			}
+
+			db, err := connectDatabase(cfg)
+			if err != nil {
+				return err
+			}
+			return exportDatasets(cmd.Context(), db, cfg, dest)
+		},
+	}
+
+	cmd.Flags().StringVar(&dest, "dest", "", "directory or s3://bucket/prefix to write to (default EXPORT_DEST)")
+	return cmd
+}
+
+func newMigrateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Apply pending database schema migrations and exit",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, db, err := openDatabase()
+			if err != nil {
+				return err
+			}
+
+			if err := migrateDatabase(db); err != nil {
+				return err
+			}
+			if err := partitionTables(db, cfg.Database.PartitionBy); err != nil {
+				return err
+			}
+			return seedFrameworks(db, cfg.Frameworks)
+		},
+	}
+
+	cmd.AddCommand(newMigrateDownCmd(), newMigrateStatusCmd())
+	return cmd
+}
+
+func newMigrateDownCmd() *cobra.Command {
+	var steps int
+
+	cmd := &cobra.Command{
+		Use:   "down",
+		Short: "Roll back the most recently applied migrations and exit",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if steps < 1 {
+				return fmt.Errorf("--steps must be at least 1")
+			}
+			_, db, err := openDatabase()
+			if err != nil {
+				return err
+			}
+			return rollbackDatabase(db, steps)
+		},
+	}
+
+	cmd.Flags().IntVar(&steps, "steps", 1, "number of migrations to roll back")
+	return cmd
+}
+
+func newMigrateStatusCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Short: "List schema migrations and whether each has been applied",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_, db, err := openDatabase()
+			if err != nil {
+				return err
+			}
+			ids, applied, err := migrationStatus(db)
+			if err != nil {
+				return err
+			}
+			for _, id := range ids {
+				state := "pending"
+				if applied[id] {
+					state = "applied"
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "%-8s %s\n", state, id)
+			}
+			return nil
+		},
+	}
+}
+
+func newAPIKeyCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "apikey",
+		Short: "Manage the API keys accepted in the X-API-Key header",
+	}
+	cmd.AddCommand(newAPIKeyCreateCmd(), newAPIKeyListCmd(), newAPIKeyRevokeCmd())
+	return cmd
+}
+
+func newAPIKeyCreateCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "create NAME",
+		Short: "Create an API key and print it; it cannot be shown again",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_, db, err := openDatabase()
+			if err != nil {
+				return err
+			}
+			_, key, err := createAPIKey(db, args[0])
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), key)
+			return nil
+		},
+	}
+}
+
+func newAPIKeyListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List the API keys by name and prefix",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_, db, err := openDatabase()
+			if err != nil {
+				return err
+			}
+			var keys []APIKey
+			if err := db.Order("name").Find(&keys).Error; err != nil {
+				return err
+			}
+			for _, key := range keys {
+				lastUsed := "never"
+				if key.LastUsedAt != nil {
+					lastUsed = key.LastUsedAt.Format(time.RFC3339)
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "%-24s %s...  created %s  last used %s\n",
+					key.Name, key.Prefix, key.CreatedAt.Format(time.RFC3339), lastUsed)
+			}
+			return nil
+		},
+	}
+}
+
+func newAPIKeyRevokeCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "revoke NAME",
+		Short: "Delete an API key so that it is no longer accepted",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_, db, err := openDatabase()
+			if err != nil {
+				return err
+			}
+			result := primary(db).Where("name = ?", args[0]).Delete(&APIKey{})
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return fmt.Errorf("no API key named %q", args[0])
+			}
+			return nil
+		},
+	}
+}
+
+// openDatabase loads the configuration and connects to its database.
+func openDatabase() (*Config, *gorm.DB, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+	db, err := connectDatabase(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cfg, db, nil
+}
+
+// loadDotEnv exports the variables in a local .env file, if there is one.
+// Variables already set in the environment are left untouched so that real
+// env vars always win.
+func loadDotEnv(path string) error {
+	if err := godotenv.Load(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("loading %s: %w", path, err)
+	}
+	return nil
+}
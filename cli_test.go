@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestFetchOnceFailsWhenASourceFails(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	if err := migrateDatabase(db); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Create(&Framework{Name: "react"}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	saved := sourceFactories
+	t.Cleanup(func() { sourceFactories = saved })
+	sourceFactories = []sourceFactory{func(*Config) Source {
+		return stubSource{err: errors.New("upstream unavailable")}
+	}}
+	cfg := &Config{
+		StorageBackend: storageSQL,
+		Database:       DatabaseConfig{Driver: driverSQLite},
+		Flags:          FeatureFlags{"source.stub": true},
+	}
+
+	err = fetchOnce(context.Background(), db, cfg)
+	if err == nil {
+		t.Fatal("fetch succeeded, want the source's error")
+	}
+	if !strings.Contains(err.Error(), "stub: react: upstream unavailable") {
+		t.Errorf("got error %q, want it to name the failing source", err)
+	}
+
+	sourceFactories = []sourceFactory{func(*Config) Source { return stubSource{} }}
+	if err := fetchOnce(context.Background(), db, cfg); err != nil {
+		t.Errorf("fetch with a working source failed: %v", err)
+	}
+}
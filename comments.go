@@ -0,0 +1,168 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type StackOverflowComment struct {
+	CommentID    int       `json:"comment_id" gorm:"primaryKey;autoIncrement:false"`
+	QuestionID   int       `json:"question_id" gorm:"index"`
+	Site         string    `json:"site"`
+	Body         string    `json:"body"`
+	Score        int       `json:"score"`
+	Owner        string    `json:"owner"`
+	CreationDate time.Time `json:"creation_date"`
+}
+
+type GitHubIssueComment struct {
+	ID        int       `json:"id" gorm:"primaryKey;autoIncrement:false"`
+	IssueID   int       `json:"issue_id" gorm:"index"`
+	Author    string    `json:"author"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// fetchStackOverflowComments returns up to cfg.CommentLimit comments for
+// each question, paging through batched /questions/{ids}/comments results.
+func fetchStackOverflowComments(ctx context.Context, cfg *Config, posts []StackOverflowPost) ([]StackOverflowComment, error) {
+	bySite := map[string][]int{}
+	var sites []string
+	for _, post := range posts {
+		if _, ok := bySite[post.Site]; !ok {
+			sites = append(sites, post.Site)
+		}
+		bySite[post.Site] = append(bySite[post.Site], post.QuestionID)
+	}
+
+	var comments []StackOverflowComment
+	perQuestion := map[int]int{}
+	for _, site := range sites {
+		questionIDs := bySite[site]
+		for start := 0; start < len(questionIDs); start += stackExchangeBatchSize {
+			end := start + stackExchangeBatchSize
+			if end > len(questionIDs) {
+				end = len(questionIDs)
+			}
+			ids := make([]string, 0, end-start)
+			for _, id := range questionIDs[start:end] {
+				ids = append(ids, strconv.Itoa(id))
+			}
+
+			for page := 1; ; page++ {
+				if seQuota.Exhausted(cfg.StackExchangeQuotaReserve) {
+					return comments, errors.New("StackExchange quota nearly exhausted")
+				}
+				seQuota.WaitBackoff()
+				stackoverflowAPICalls.Inc()
+
+				query := url.Values{
+					"filter":   {"withbody"},
+					"sort":     {"creation"},
+					"order":    {"asc"},
+					"pagesize": {"100"},
+					"page":     {strconv.Itoa(page)},
+				}
+
+				var result struct {
+					stackExchangeWrapper
+					Items []struct {
+						CommentID    int    `json:"comment_id"`
+						PostID       int    `json:"post_id"`
+						Body         string `json:"body"`
+						Score        int    `json:"score"`
+						CreationDate int64  `json:"creation_date"`
+						Owner        struct {
+							DisplayName string `json:"display_name"`
+						} `json:"owner"`
+					} `json:"items"`
+				}
+				if err := stackExchangeGet(ctx, cfg, fmt.Sprintf("/questions/%s/comments", strings.Join(ids, ";")), site, query, &result); err != nil {
+					return comments, err
+				}
+				seQuota.Update(result.QuotaRemaining, result.QuotaMax, result.Backoff)
+
+				for _, item := range result.Items {
+					if perQuestion[item.PostID] >= cfg.CommentLimit {
+						continue
+					}
+					perQuestion[item.PostID]++
+					comments = append(comments, StackOverflowComment{
+						CommentID:    item.CommentID,
+						QuestionID:   item.PostID,
+						Site:         site,
+						Body:         item.Body,
+						Score:        item.Score,
+						Owner:        item.Owner.DisplayName,
+						CreationDate: time.Unix(item.CreationDate, 0).UTC(),
+					})
+				}
+				if !result.HasMore {
+					break
+				}
+			}
+		}
+	}
+	return comments, nil
+}
+
+// fetchGitHubIssueComments returns up to cfg.CommentLimit comments for each
+// issue that has any, following the issue's comments_url page by page.
+func fetchGitHubIssueComments(ctx context.Context, cfg *Config, issues []GitHubIssue) ([]GitHubIssueComment, error) {
+	var comments []GitHubIssueComment
+	for _, issue := range issues {
+		if issue.Comments == 0 || issue.CommentsURL == "" {
+			continue
+		}
+
+		count := 0
+		for page := 1; count < cfg.CommentLimit; page++ {
+			var list []struct {
+				ID        int         `json:"id"`
+				Body      string      `json:"body"`
+				User      githubActor `json:"user"`
+				CreatedAt time.Time   `json:"created_at"`
+				UpdatedAt time.Time   `json:"updated_at"`
+			}
+			u := fmt.Sprintf("%s?per_page=100&page=%d", issue.CommentsURL, page)
+			if err := githubGet(ctx, u, &list); err != nil {
+				return comments, err
+			}
+
+			for _, c := range list {
+				if count == cfg.CommentLimit {
+					break
+				}
+				count++
+				comments = append(comments, GitHubIssueComment{
+					ID:        c.ID,
+					IssueID:   issue.ID,
+					Author:    c.User.Login,
+					Body:      c.Body,
+					CreatedAt: c.CreatedAt,
+					UpdatedAt: c.UpdatedAt,
+				})
+			}
+			if len(list) < 100 {
+				break
+			}
+		}
+	}
+	return comments, nil
+}
+
+func (comment StackOverflowComment) Save(db *gorm.DB) error {
+	return upsert(db, &comment)
+}
+
+func (comment GitHubIssueComment) Save(db *gorm.DB) error {
+	return upsert(db, &comment)
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/compress"
+)
+
+// compressionLevels maps the values of COMPRESSION_LEVEL to the levels of
+// the compress middleware.
+var compressionLevels = map[string]compress.Level{
+	"off":     compress.LevelDisabled,
+	"speed":   compress.LevelBestSpeed,
+	"default": compress.LevelDefault,
+	"best":    compress.LevelBestCompression,
+}
+
+// compression returns middleware compressing responses with brotli or
+// gzip, whichever the client's Accept-Encoding prefers, at level, one of
+// the keys of compressionLevels. Bodies too small to benefit are sent as
+// they are. The event stream is left uncompressed so that each event is
+// delivered as soon as it is written.
+func compression(level string) fiber.Handler {
+	return compress.New(compress.Config{
+		Next:  func(c *fiber.Ctx) bool { return c.Path() == apiV1Prefix+"/stream" || c.Path() == "/stream" },
+		Level: compressionLevels[level],
+	})
+}
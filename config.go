@@ -0,0 +1,654 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"gopkg.in/yaml.v3"
+)
+
+// Config holds the runtime settings for the service. Values come from
+// environment variables, falling back to local-development defaults. The
+// list of tracked frameworks is read from the YAML file named by
+// CONFIG_FILE (config.yaml by default). When a secret backend is selected
+// via SECRET_BACKEND, the credentials stored there take precedence over the
+// environment. APP_ENV selects a profile (dev, staging, prod) that sets
+// the defaults for the database driver, log level, and fetch limits.
+type Config struct {
+	Env        string
+	LogLevel   string
+	FetchLimit int
+	// FetchWorkers run the queued collection passes, of which at most
+	// FetchQueueSize wait; see fetchQueue. A pass is cancelled after
+	// FetchJobTimeout unless it is zero.
+	FetchWorkers    int
+	FetchQueueSize  int
+	FetchJobTimeout time.Duration
+	// FetchMaxAttempts bounds the tries of a GitHub or StackExchange call
+	// failing with a 5xx status or a timeout. The wait between tries
+	// starts around FetchRetryDelay and doubles; see retryPolicy.
+	FetchMaxAttempts int
+	FetchRetryDelay  time.Duration
+	// BreakerFailures consecutive failed fetches of a source stop calls
+	// to it for BreakerCooldown; see breakerRegistry. Zero disables the
+	// breakers.
+	BreakerFailures int
+	BreakerCooldown time.Duration
+	// CommentLimit caps the comments collected per question or issue.
+	CommentLimit int
+	HTTPPort     string
+	MetricsPort  string
+	// GRPCPort serves the gRPC API alongside the HTTP one when set.
+	GRPCPort string
+	// MetricsOnAppPort serves /metrics from the Fiber app on HTTPPort
+	// instead of a separate server on MetricsPort.
+	MetricsOnAppPort bool
+	// CompressionLevel is off, speed, default or best; see compression.
+	CompressionLevel string
+	// AdminToken is the bearer token required by operator endpoints such
+	// as GET /config. Those endpoints are disabled when it is empty.
+	AdminToken string
+	// RequireAPIKey makes every data endpoint require an X-API-Key or a
+	// JWT; those that trigger collection or administer the service always
+	// do.
+	RequireAPIKey bool
+	JWT           JWTConfig
+	Database      DatabaseConfig
+	// StorageBackend selects where collected items are stored: sql, or
+	// mongodb for StackOverflow posts and GitHub issues as documents.
+	StorageBackend string
+	MongoDB        MongoDBConfig
+	Elasticsearch  ElasticsearchConfig
+	ClickHouse     ClickHouseConfig
+	Milvus         MilvusConfig
+	Cache          CacheConfig
+	RateLimit      RateLimitConfig
+	CORS           CORSConfig
+	Embedding      EmbeddingConfig
+	// Archive receives a copy of every successful API response body.
+	Archive ArchiveConfig
+	// ExportDest is the directory or s3://bucket/prefix that Parquet
+	// exports are written to; `serve` exports there every ExportInterval
+	// unless it is zero.
+	ExportDest     string
+	ExportInterval time.Duration
+	// FetchSchedule is the cron expression on which `serve` runs each
+	// enabled source, and SourceSchedules overrides it per source, from
+	// FETCH_SCHEDULE_<SOURCE>. off or an empty expression disables the
+	// schedule; see fetchScheduler.
+	FetchSchedule   string
+	SourceSchedules map[string]string
+	GitHubTokens    []string
+	// GitHubAPI selects the REST or GraphQL path for issue collection.
+	GitHubAPI        string
+	StackExchangeKey string
+	// StackOverflowTeamsToken is the personal access token used for
+	// frameworks that set StackOverflowTeam.
+	StackOverflowTeamsToken string
+	// NVDAPIKey is optional; it raises the NVD rate limit tenfold.
+	NVDAPIKey string
+	// LibrariesIOKey enables the Libraries.io collector.
+	LibrariesIOKey string
+	// YouTubeAPIKey enables the YouTube collector.
+	YouTubeAPIKey string
+	// StackExchangeQuotaReserve is the number of daily StackExchange
+	// requests left untouched; collection stops once the quota reaches it.
+	StackExchangeQuotaReserve int
+	Frameworks                []Framework
+	Flags                     FeatureFlags
+	GitLab                    GitLabConfig
+	Jira                      JiraConfig
+	Bitbucket                 BitbucketConfig
+	// MastodonInstances are the host names searched for framework hashtags.
+	MastodonInstances []string
+	SecretBackend     string
+	Vault             VaultConfig
+	AWS               AWSConfig
+}
+
+// Framework is a project tracked across StackOverflow and GitHub. The
+// frameworks table is the live registry; entries from the config file seed
+// it when it is empty.
+type Framework struct {
+	ID               uint   `yaml:"-" json:"id" gorm:"primaryKey"`
+	Name             string `yaml:"name" json:"name" gorm:"uniqueIndex;size:191;not null"`
+	StackOverflowTag string `yaml:"stackoverflow_tag" json:"stackoverflow_tag"`
+	GitHubRepo       string `yaml:"github_repo" json:"github_repo"`
+	// StackExchangeSites lists the StackExchange sites searched for
+	// StackOverflowTag; it defaults to stackoverflow alone.
+	StackExchangeSites []string `yaml:"stackexchange_sites" json:"stackexchange_sites" gorm:"serializer:json"`
+	// StackOverflowTeam is the slug of a private Stack Overflow for Teams
+	// instance searched for StackOverflowTag in addition to the public sites.
+	StackOverflowTeam string `yaml:"stackoverflow_team" json:"stackoverflow_team"`
+
+	// Optional per-source settings; a source skips frameworks that leave
+	// its setting empty.
+	Subreddits      []string `yaml:"subreddits" json:"subreddits" gorm:"serializer:json"`
+	HackerNewsQuery string   `yaml:"hackernews_query" json:"hackernews_query"`
+	GitLabProject   string   `yaml:"gitlab_project" json:"gitlab_project"`
+	BitbucketRepo   string   `yaml:"bitbucket_repo" json:"bitbucket_repo"`
+	JiraJQL         string   `yaml:"jira_jql" json:"jira_jql"`
+	DevToTag        string   `yaml:"devto_tag" json:"devto_tag"`
+	NpmPackage      string   `yaml:"npm_package" json:"npm_package"`
+	PyPIPackage     string   `yaml:"pypi_package" json:"pypi_package"`
+	GoModule        string   `yaml:"go_module" json:"go_module"`
+	NVDKeyword      string   `yaml:"nvd_keyword" json:"nvd_keyword"`
+	YouTubeQuery    string   `yaml:"youtube_query" json:"youtube_query"`
+
+	// DockerImages are Docker Hub repositories; official images may omit
+	// the library/ namespace.
+	DockerImages     []string `yaml:"docker_images" json:"docker_images" gorm:"serializer:json"`
+	MastodonHashtags []string `yaml:"mastodon_hashtags" json:"mastodon_hashtags" gorm:"serializer:json"`
+	ArxivQueries     []string `yaml:"arxiv_queries" json:"arxiv_queries" gorm:"serializer:json"`
+	LobstersTags     []string `yaml:"lobsters_tags" json:"lobsters_tags" gorm:"serializer:json"`
+
+	DiscourseURL        string   `yaml:"discourse_url" json:"discourse_url"`
+	DiscourseCategories []string `yaml:"discourse_categories" json:"discourse_categories" gorm:"serializer:json"`
+
+	// Feeds lists RSS or Atom feed URLs, such as project blogs.
+	Feeds []string `yaml:"feeds" json:"feeds" gorm:"serializer:json"`
+}
+
+// hasSource reports whether f gives any source something to collect.
+func (f Framework) hasSource() bool {
+	for _, setting := range []string{f.StackOverflowTag, f.GitHubRepo, f.HackerNewsQuery, f.GitLabProject, f.BitbucketRepo,
+		f.JiraJQL, f.DevToTag, f.NpmPackage, f.PyPIPackage, f.GoModule, f.NVDKeyword, f.YouTubeQuery, f.DiscourseURL} {
+		if strings.TrimSpace(setting) != "" {
+			return true
+		}
+	}
+	for _, setting := range [][]string{f.Subreddits, f.DockerImages, f.MastodonHashtags, f.ArxivQueries, f.LobstersTags, f.Feeds} {
+		if len(setting) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (f Framework) stackExchangeSites() []string {
+	sites := f.StackExchangeSites
+	if len(sites) == 0 {
+		sites = []string{"stackoverflow"}
+	}
+	if f.StackOverflowTeam != "" {
+		sites = append(sites[:len(sites):len(sites)], stackExchangeTeamSite(f.StackOverflowTeam))
+	}
+	return sites
+}
+
+// fileConfig mirrors the layout of the YAML config file.
+type fileConfig struct {
+	Frameworks   []Framework                  `yaml:"frameworks"`
+	Flags        FeatureFlags                 `yaml:"flags"`
+	Environments map[string]environmentConfig `yaml:"environments"`
+}
+
+// environmentConfig holds the per-APP_ENV overrides in the config file.
+type environmentConfig struct {
+	Flags FeatureFlags `yaml:"flags"`
+}
+
+// defaultFrameworks is used when no config file is present.
+var defaultFrameworks = []Framework{
+	{Name: "Prometheus", StackOverflowTag: "prometheus", GitHubRepo: "prometheus/prometheus", DockerImages: []string{"prom/prometheus"}, Subreddits: []string{"PrometheusMonitoring"}, DevToTag: "prometheus", GoModule: "github.com/prometheus/client_golang", PyPIPackage: "prometheus-client", MastodonHashtags: []string{"prometheus"}, Feeds: []string{"https://prometheus.io/blog/feed.xml"}},
+	{Name: "Selenium", StackOverflowTag: "selenium", GitHubRepo: "SeleniumHQ/selenium", Subreddits: []string{"selenium"}, DevToTag: "selenium", NpmPackage: "selenium-webdriver", PyPIPackage: "selenium"},
+	{Name: "OpenAI", StackOverflowTag: "openai", GitHubRepo: "openai/openai-cookbook", Subreddits: []string{"OpenAI"}, DiscourseURL: "https://community.openai.com", DevToTag: "openai", NpmPackage: "openai", PyPIPackage: "openai", ArxivQueries: []string{"OpenAI API", "GPT-4"}},
+	{Name: "Docker", StackOverflowTag: "docker", GitHubRepo: "docker/docker", DockerImages: []string{"docker"}, StackExchangeSites: []string{"stackoverflow", "serverfault", "superuser", "devops"}, Subreddits: []string{"docker"}, DiscourseURL: "https://forums.docker.com", DevToTag: "docker", GoModule: "github.com/docker/docker", MastodonHashtags: []string{"docker"}},
+	{Name: "Milvus", StackOverflowTag: "milvus", GitHubRepo: "milvus-io/milvus", DockerImages: []string{"milvusdb/milvus"}, PyPIPackage: "pymilvus", GoModule: "github.com/milvus-io/milvus-sdk-go/v2", ArxivQueries: []string{"Milvus", "vector database"}},
+	{Name: "Go", StackOverflowTag: "golang", GitHubRepo: "golang/go", DockerImages: []string{"golang"}, Subreddits: []string{"golang"}, HackerNewsQuery: "golang", NVDKeyword: "golang", YouTubeQuery: "golang", DiscourseURL: "https://forum.golangbridge.org", DevToTag: "go", MastodonHashtags: []string{"golang", "go"}, LobstersTags: []string{"go"}, Feeds: []string{"https://go.dev/blog/feed.atom"}},
+}
+
+// DatabaseConfig describes the database connection. Driver defaults to
+// the APP_ENV profile's and can be forced with DB_DRIVER (postgres, mysql
+// or sqlite). For Postgres and MySQL/MariaDB, URL, when set, is used
+// verbatim instead of the individual fields; for MySQL it must be a
+// go-sql-driver DSN such as user:pass@tcp(host:3306)/db?parseTime=true.
+// SQLitePath is only used by the sqlite driver. ReplicaURLs are optional
+// read-only DSNs in the same format as URL; when set, queries outside
+// transactions are spread across them and writes go to the primary.
+// PartitionBy, Postgres only, partitions the posts and issues tables by
+// month or by framework; see partitionTables.
+//
+// The pool settings bound the connections kept by database/sql: a zero
+// MaxOpenConns means unlimited, and a zero ConnMaxLifetime or
+// ConnMaxIdleTime keeps connections indefinitely.
+type DatabaseConfig struct {
+	Driver     string
+	SQLitePath string
+	URL        string
+	Host       string
+	Port       string
+	User       string
+	Password   string
+	Name       string
+	SSLMode    string
+
+	ReplicaURLs []string
+	PartitionBy string
+
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+}
+
+// DSN returns the Postgres or MySQL connection string for the configured
+// database.
+func (d DatabaseConfig) DSN() string {
+	if d.URL != "" {
+		return d.URL
+	}
+	if d.Driver == driverMySQL {
+		mc := mysql.NewConfig()
+		mc.User = d.User
+		mc.Passwd = d.Password
+		mc.Net = "tcp"
+		mc.Addr = net.JoinHostPort(d.Host, d.Port)
+		mc.DBName = d.Name
+		mc.ParseTime = true
+		mc.Params = map[string]string{"charset": "utf8mb4"}
+		return mc.FormatDSN()
+	}
+	return fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=%s",
+		d.Host, d.User, d.Password, d.Name, d.Port, d.SSLMode)
+}
+
+// SQLiteDSN returns the sqlite connection string. WAL mode and a busy
+// timeout let the API keep reading while a fetch run is writing.
+func (d DatabaseConfig) SQLiteDSN() string {
+	return d.SQLitePath + "?_journal_mode=WAL&_busy_timeout=5000&_foreign_keys=on"
+}
+
+func loadConfig() (*Config, error) {
+	env := getEnv("APP_ENV", envProd)
+	prof, err := lookupProfile(env)
+	if err != nil {
+		return nil, err
+	}
+	fetchLimit, err := getEnvInt("FETCH_LIMIT", prof.fetchLimit)
+	if err != nil {
+		return nil, err
+	}
+	fetchWorkers, err := getEnvInt("FETCH_WORKERS", 2)
+	if err != nil {
+		return nil, err
+	}
+	fetchQueueSize, err := getEnvInt("FETCH_QUEUE_SIZE", 10)
+	if err != nil {
+		return nil, err
+	}
+	fetchJobTimeout, err := getEnvDuration("FETCH_JOB_TIMEOUT", 0)
+	if err != nil {
+		return nil, err
+	}
+	fetchMaxAttempts, err := getEnvInt("FETCH_MAX_ATTEMPTS", 3)
+	if err != nil {
+		return nil, err
+	}
+	fetchRetryDelay, err := getEnvDuration("FETCH_RETRY_DELAY", time.Second)
+	if err != nil {
+		return nil, err
+	}
+	breakerFailures, err := getEnvInt("BREAKER_FAILURES", 5)
+	if err != nil {
+		return nil, err
+	}
+	breakerCooldown, err := getEnvDuration("BREAKER_COOLDOWN", 5*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	commentLimit, err := getEnvInt("COMMENT_LIMIT", 50)
+	if err != nil {
+		return nil, err
+	}
+	quotaReserve, err := getEnvInt("STACKEXCHANGE_QUOTA_RESERVE", 10)
+	if err != nil {
+		return nil, err
+	}
+	metricsOnAppPort, err := getEnvBool("METRICS_SINGLE_PORT", false)
+	if err != nil {
+		return nil, err
+	}
+	requireAPIKey, err := getEnvBool("REQUIRE_API_KEY", false)
+	if err != nil {
+		return nil, err
+	}
+	var jwtPublicKey []byte
+	if path := os.Getenv("JWT_PUBLIC_KEY_FILE"); path != "" {
+		if jwtPublicKey, err = os.ReadFile(path); err != nil {
+			return nil, fmt.Errorf("reading JWT_PUBLIC_KEY_FILE: %w", err)
+		}
+	}
+
+	archivePathStyle, err := getEnvBool("ARCHIVE_PATH_STYLE", os.Getenv("ARCHIVE_ENDPOINT") != "")
+	if err != nil {
+		return nil, err
+	}
+
+	rateLimit, err := getEnvInt("RATE_LIMIT", 120)
+	if err != nil {
+		return nil, err
+	}
+	rateLimitWindow, err := getEnvDuration("RATE_LIMIT_WINDOW", time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	fetchRateLimit, err := getEnvInt("FETCH_RATE_LIMIT", 3)
+	if err != nil {
+		return nil, err
+	}
+	fetchRateLimitWindow, err := getEnvDuration("FETCH_RATE_LIMIT_WINDOW", time.Hour)
+	if err != nil {
+		return nil, err
+	}
+
+	corsCredentials, err := getEnvBool("CORS_ALLOW_CREDENTIALS", false)
+	if err != nil {
+		return nil, err
+	}
+	corsMaxAge, err := getEnvDuration("CORS_MAX_AGE", 10*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+
+	cacheTTL, err := getEnvDuration("CACHE_TTL", time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	routeTTLs, err := parseRouteTTLs(os.Getenv("CACHE_ROUTE_TTLS"))
+	if err != nil {
+		return nil, err
+	}
+	embeddingDims, err := getEnvInt("EMBEDDINGS_DIMENSIONS", 1536)
+	if err != nil {
+		return nil, err
+	}
+	exportInterval, err := getEnvDuration("EXPORT_INTERVAL", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	dbMaxOpen, err := getEnvInt("DB_MAX_OPEN_CONNS", 25)
+	if err != nil {
+		return nil, err
+	}
+	dbMaxIdle, err := getEnvInt("DB_MAX_IDLE_CONNS", 10)
+	if err != nil {
+		return nil, err
+	}
+	dbMaxLifetime, err := getEnvDuration("DB_CONN_MAX_LIFETIME", 30*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	dbMaxIdleTime, err := getEnvDuration("DB_CONN_MAX_IDLE_TIME", 5*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+
+	dbDriver := getEnv("DB_DRIVER", prof.dbDriver)
+	dbPort := "5432"
+	if dbDriver == driverMySQL {
+		dbPort = "3306"
+	}
+
+	cfg := &Config{
+		Env:              env,
+		LogLevel:         getEnv("LOG_LEVEL", prof.logLevel),
+		FetchLimit:       fetchLimit,
+		FetchWorkers:     fetchWorkers,
+		FetchQueueSize:   fetchQueueSize,
+		FetchJobTimeout:  fetchJobTimeout,
+		FetchMaxAttempts: fetchMaxAttempts,
+		FetchRetryDelay:  fetchRetryDelay,
+		BreakerFailures:  breakerFailures,
+		BreakerCooldown:  breakerCooldown,
+		CommentLimit:     commentLimit,
+		HTTPPort:         getEnv("PORT", "8080"),
+		MetricsPort:      getEnv("METRICS_PORT", "9091"),
+		GRPCPort:         os.Getenv("GRPC_PORT"),
+		MetricsOnAppPort: metricsOnAppPort,
+		CompressionLevel: getEnv("COMPRESSION_LEVEL", "speed"),
+		AdminToken:       os.Getenv("ADMIN_TOKEN"),
+		RequireAPIKey:    requireAPIKey,
+		JWT: JWTConfig{
+			Secret:    os.Getenv("JWT_SECRET"),
+			PublicKey: string(jwtPublicKey),
+			Issuer:    os.Getenv("JWT_ISSUER"),
+		},
+		Database: DatabaseConfig{
+			Driver:     dbDriver,
+			SQLitePath: getEnv("SQLITE_PATH", "dev.db"),
+			URL:        os.Getenv("DATABASE_URL"),
+			Host:       getEnv("DB_HOST", "localhost"),
+			Port:       getEnv("DB_PORT", dbPort),
+			User:       getEnv("DB_USER", "postgres"),
+			Password:   os.Getenv("DB_PASSWORD"),
+			Name:       getEnv("DB_NAME", "stackoverflowdb"),
+			SSLMode:    getEnv("DB_SSLMODE", "disable"),
+
+			ReplicaURLs: splitList(os.Getenv("DATABASE_REPLICA_URLS")),
+			PartitionBy: os.Getenv("DB_PARTITION_BY"),
+
+			MaxOpenConns:    dbMaxOpen,
+			MaxIdleConns:    dbMaxIdle,
+			ConnMaxLifetime: dbMaxLifetime,
+			ConnMaxIdleTime: dbMaxIdleTime,
+		},
+		StorageBackend: getEnv("STORAGE_BACKEND", storageSQL),
+		MongoDB: MongoDBConfig{
+			URI:      os.Getenv("MONGODB_URI"),
+			Database: getEnv("MONGODB_DATABASE", "stackoverflowdb"),
+		},
+		Elasticsearch: ElasticsearchConfig{
+			URL:    os.Getenv("ELASTICSEARCH_URL"),
+			APIKey: os.Getenv("ELASTICSEARCH_API_KEY"),
+			Index:  getEnv("ELASTICSEARCH_INDEX", "framework-content"),
+		},
+		ClickHouse: ClickHouseConfig{
+			URL:      os.Getenv("CLICKHOUSE_URL"),
+			Database: getEnv("CLICKHOUSE_DATABASE", "default"),
+		},
+		Archive: ArchiveConfig{
+			Bucket:    os.Getenv("ARCHIVE_BUCKET"),
+			Prefix:    getEnv("ARCHIVE_PREFIX", "raw"),
+			Endpoint:  os.Getenv("ARCHIVE_ENDPOINT"),
+			PathStyle: archivePathStyle,
+		},
+		Cache: CacheConfig{
+			RedisURL:  os.Getenv("REDIS_URL"),
+			TTL:       cacheTTL,
+			RouteTTLs: routeTTLs,
+		},
+		RateLimit: RateLimitConfig{
+			Requests:      rateLimit,
+			Window:        rateLimitWindow,
+			FetchRequests: fetchRateLimit,
+			FetchWindow:   fetchRateLimitWindow,
+		},
+		CORS: CORSConfig{
+			AllowedOrigins:   splitList(os.Getenv("CORS_ALLOWED_ORIGINS")),
+			AllowedMethods:   splitList(getEnv("CORS_ALLOWED_METHODS", "GET,POST,PUT,DELETE")),
+			AllowedHeaders:   splitList(getEnv("CORS_ALLOWED_HEADERS", "Authorization,Content-Type,X-API-Key,If-None-Match,If-Modified-Since")),
+			AllowCredentials: corsCredentials,
+			MaxAge:           corsMaxAge,
+		},
+		Milvus: MilvusConfig{
+			URL:        os.Getenv("MILVUS_URL"),
+			Token:      os.Getenv("MILVUS_TOKEN"),
+			Collection: getEnv("MILVUS_COLLECTION", "framework_content"),
+		},
+		Embedding: EmbeddingConfig{
+			URL:        getEnv("EMBEDDINGS_URL", "https://api.openai.com/v1/embeddings"),
+			APIKey:     os.Getenv("EMBEDDINGS_API_KEY"),
+			Model:      getEnv("EMBEDDINGS_MODEL", "text-embedding-3-small"),
+			Dimensions: embeddingDims,
+		},
+		ExportDest:                getEnv("EXPORT_DEST", "export"),
+		ExportInterval:            exportInterval,
+		FetchSchedule:             os.Getenv("FETCH_SCHEDULE"),
+		SourceSchedules:           sourceSchedules(),
+		GitHubTokens:              splitList(getEnv("GITHUB_TOKENS", os.Getenv("GITHUB_TOKEN"))),
+		GitHubAPI:                 getEnv("GITHUB_API", githubAPIREST),
+		StackExchangeKey:          os.Getenv("STACKEXCHANGE_KEY"),
+		StackOverflowTeamsToken:   os.Getenv("STACKOVERFLOW_TEAMS_TOKEN"),
+		NVDAPIKey:                 os.Getenv("NVD_API_KEY"),
+		LibrariesIOKey:            os.Getenv("LIBRARIES_IO_KEY"),
+		YouTubeAPIKey:             os.Getenv("YOUTUBE_API_KEY"),
+		StackExchangeQuotaReserve: quotaReserve,
+		Frameworks:                defaultFrameworks,
+		Vault: VaultConfig{
+			Addr:       os.Getenv("VAULT_ADDR"),
+			Token:      os.Getenv("VAULT_TOKEN"),
+			SecretPath: getEnv("VAULT_SECRET_PATH", "secret/data/my-assignment"),
+		},
+		GitLab: GitLabConfig{
+			URL:   getEnv("GITLAB_URL", "https://gitlab.com"),
+			Token: os.Getenv("GITLAB_TOKEN"),
+		},
+		Jira: JiraConfig{
+			URL:   os.Getenv("JIRA_URL"),
+			Email: os.Getenv("JIRA_EMAIL"),
+			Token: os.Getenv("JIRA_TOKEN"),
+		},
+		MastodonInstances: splitList(getEnv("MASTODON_INSTANCES", "mastodon.social,fosstodon.org")),
+		Bitbucket: BitbucketConfig{
+			Username:    os.Getenv("BITBUCKET_USERNAME"),
+			AppPassword: os.Getenv("BITBUCKET_APP_PASSWORD"),
+		},
+		AWS: AWSConfig{
+			Region:   os.Getenv("AWS_REGION"),
+			SecretID: os.Getenv("AWS_SECRET_ID"),
+			SSMPath:  os.Getenv("AWS_SSM_PATH"),
+		},
+	}
+
+	cfg.SecretBackend = os.Getenv("SECRET_BACKEND")
+	if cfg.SecretBackend == "" && cfg.Vault.Enabled() {
+		cfg.SecretBackend = "vault"
+	}
+
+	fc, err := readConfigFile(configPath())
+	if err != nil {
+		return nil, err
+	}
+	envFlags, err := parseFlagList(os.Getenv("FEATURE_FLAGS"))
+	if err != nil {
+		return nil, err
+	}
+	if fc != nil {
+		cfg.Frameworks = fc.Frameworks
+		cfg.Flags = mergeFlags(fc.Flags, fc.Environments[env].Flags, envFlags)
+	} else {
+		cfg.Flags = envFlags
+	}
+
+	if err := applySecrets(context.Background(), cfg); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+func configPath() string {
+	return getEnv("CONFIG_FILE", "config.yaml")
+}
+
+// readConfigFile parses the YAML config at path. A missing file is not an
+// error; it returns nil so the built-in defaults apply.
+func readConfigFile(path string) (*fileConfig, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading config file %s: %w", path, err)
+	}
+
+	var fc fileConfig
+	if err := yaml.Unmarshal(data, &fc); err != nil {
+		return nil, fmt.Errorf("parsing config file %s: %w", path, err)
+	}
+	if len(fc.Frameworks) == 0 {
+		return nil, fmt.Errorf("config file %s defines no frameworks", path)
+	}
+	return &fc, nil
+}
+
+func getEnv(key, fallback string) string {
+	if value, ok := os.LookupEnv(key); ok && value != "" {
+		return value
+	}
+	return fallback
+}
+
+func getEnvInt(key string, fallback int) (int, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be an integer, got %q", key, value)
+	}
+	return n, nil
+}
+
+func getEnvDuration(key string, fallback time.Duration) (time.Duration, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be a duration such as 24h, got %q", key, value)
+	}
+	return d, nil
+}
+
+// sourceSchedules collects the FETCH_SCHEDULE_<SOURCE> variables, keyed
+// by source name, such as github_pulls for FETCH_SCHEDULE_GITHUB_PULLS.
+func sourceSchedules() map[string]string {
+	schedules := map[string]string{}
+	for _, entry := range os.Environ() {
+		key, value, _ := strings.Cut(entry, "=")
+		if source, ok := strings.CutPrefix(key, "FETCH_SCHEDULE_"); ok && source != "" {
+			schedules[strings.ToLower(source)] = value
+		}
+	}
+	return schedules
+}
+
+// splitList parses a comma-separated setting, dropping empty entries.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func getEnvBool(key string, fallback bool) (bool, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("%s must be a boolean, got %q", key, value)
+	}
+	return b, nil
+}
@@ -0,0 +1,305 @@
+package main
+
+import (
+	"net/url"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const redacted = "[REDACTED]"
+
+// configView is the JSON shape of the effective configuration returned by
+// GET /config. Credentials are never included verbatim.
+type configView struct {
+	Env              string               `json:"env"`
+	LogLevel         string               `json:"log_level"`
+	FetchLimit       int                  `json:"fetch_limit"`
+	FetchWorkers     int                  `json:"fetch_workers"`
+	FetchQueueSize   int                  `json:"fetch_queue_size"`
+	FetchJobTimeout  string               `json:"fetch_job_timeout"`
+	FetchMaxAttempts int                  `json:"fetch_max_attempts"`
+	FetchRetryDelay  string               `json:"fetch_retry_delay"`
+	BreakerFailures  int                  `json:"breaker_failures"`
+	BreakerCooldown  string               `json:"breaker_cooldown"`
+	CommentLimit     int                  `json:"comment_limit"`
+	HTTPPort         string               `json:"http_port"`
+	MetricsPort      string               `json:"metrics_port"`
+	GRPCPort         string               `json:"grpc_port"`
+	MetricsOnAppPort bool                 `json:"metrics_on_app_port"`
+	CompressionLevel string               `json:"compression_level"`
+	RequireAPIKey    bool                 `json:"require_api_key"`
+	JWT              jwtConfigView        `json:"jwt"`
+	Database         databaseConfigView   `json:"database"`
+	StorageBackend   string               `json:"storage_backend"`
+	MongoDB          mongoDBConfigView    `json:"mongodb"`
+	Elasticsearch    elasticConfigView    `json:"elasticsearch"`
+	ClickHouse       clickHouseConfigView `json:"clickhouse"`
+	Cache            cacheConfigView      `json:"cache"`
+	RateLimit        rateLimitConfigView  `json:"rate_limit"`
+	CORS             corsConfigView       `json:"cors"`
+	Milvus           milvusConfigView     `json:"milvus"`
+	Embedding        embeddingConfigView  `json:"embedding"`
+	Archive          archiveConfigView    `json:"archive"`
+	ExportDest       string               `json:"export_dest"`
+	ExportInterval   string               `json:"export_interval"`
+	FetchSchedule    string               `json:"fetch_schedule"`
+	SourceSchedules  map[string]string    `json:"source_schedules,omitempty"`
+	SecretBackend    string               `json:"secret_backend"`
+	Flags            map[string]bool      `json:"flags"`
+	GitHubTokens     []string             `json:"github_tokens"`
+	GitHubAPI        string               `json:"github_api"`
+	StackExchangeKey string               `json:"stackexchange_key"`
+	TeamsToken       string               `json:"stackoverflow_teams_token"`
+	NVDAPIKey        string               `json:"nvd_api_key"`
+	LibrariesIOKey   string               `json:"libraries_io_key"`
+	YouTubeAPIKey    string               `json:"youtube_api_key"`
+	QuotaReserve     int                  `json:"stackexchange_quota_reserve"`
+	GitLab           gitLabConfigView     `json:"gitlab"`
+	Jira             jiraConfigView       `json:"jira"`
+	Bitbucket        bitbucketConfigView  `json:"bitbucket"`
+	Mastodon         []string             `json:"mastodon_instances"`
+	Frameworks       []Framework          `json:"frameworks"`
+}
+
+type gitLabConfigView struct {
+	URL   string `json:"url"`
+	Token string `json:"token"`
+}
+
+type jiraConfigView struct {
+	URL   string `json:"url"`
+	Email string `json:"email"`
+	Token string `json:"token"`
+}
+
+type bitbucketConfigView struct {
+	Username    string `json:"username"`
+	AppPassword string `json:"app_password"`
+}
+
+type mongoDBConfigView struct {
+	URI      string `json:"uri,omitempty"`
+	Database string `json:"database"`
+}
+
+type elasticConfigView struct {
+	URL    string `json:"url,omitempty"`
+	APIKey string `json:"api_key,omitempty"`
+	Index  string `json:"index"`
+}
+
+type clickHouseConfigView struct {
+	URL      string `json:"url,omitempty"`
+	Database string `json:"database"`
+}
+
+type cacheConfigView struct {
+	RedisURL  string            `json:"redis_url,omitempty"`
+	TTL       string            `json:"ttl"`
+	RouteTTLs map[string]string `json:"route_ttls,omitempty"`
+}
+
+type jwtConfigView struct {
+	Secret    string `json:"secret"`
+	PublicKey bool   `json:"public_key"`
+	Issuer    string `json:"issuer"`
+}
+
+type corsConfigView struct {
+	AllowedOrigins   []string `json:"allowed_origins"`
+	AllowedMethods   []string `json:"allowed_methods"`
+	AllowedHeaders   []string `json:"allowed_headers"`
+	AllowCredentials bool     `json:"allow_credentials"`
+	MaxAge           string   `json:"max_age"`
+}
+
+type rateLimitConfigView struct {
+	Requests      int    `json:"requests"`
+	Window        string `json:"window"`
+	FetchRequests int    `json:"fetch_requests"`
+	FetchWindow   string `json:"fetch_window"`
+}
+
+type milvusConfigView struct {
+	URL        string `json:"url,omitempty"`
+	Token      string `json:"token,omitempty"`
+	Collection string `json:"collection"`
+}
+
+type embeddingConfigView struct {
+	URL        string `json:"url"`
+	APIKey     string `json:"api_key,omitempty"`
+	Model      string `json:"model"`
+	Dimensions int    `json:"dimensions"`
+}
+
+type archiveConfigView struct {
+	Bucket    string `json:"bucket,omitempty"`
+	Prefix    string `json:"prefix"`
+	Endpoint  string `json:"endpoint,omitempty"`
+	PathStyle bool   `json:"path_style"`
+}
+
+type databaseConfigView struct {
+	Driver     string `json:"driver"`
+	SQLitePath string `json:"sqlite_path,omitempty"`
+	URL        string `json:"url,omitempty"`
+	Host       string `json:"host,omitempty"`
+	Port       string `json:"port,omitempty"`
+	User       string `json:"user,omitempty"`
+	Password   string `json:"password,omitempty"`
+	Name       string `json:"name,omitempty"`
+	SSLMode    string `json:"sslmode,omitempty"`
+
+	ReplicaURLs []string `json:"replica_urls,omitempty"`
+	PartitionBy string   `json:"partition_by,omitempty"`
+
+	MaxOpenConns    int    `json:"max_open_conns"`
+	MaxIdleConns    int    `json:"max_idle_conns"`
+	ConnMaxLifetime string `json:"conn_max_lifetime"`
+	ConnMaxIdleTime string `json:"conn_max_idle_time"`
+}
+
+func newConfigView(cfg *Config) configView {
+	tokens := make([]string, len(cfg.GitHubTokens))
+	for i := range tokens {
+		tokens[i] = redacted
+	}
+
+	return configView{
+		Env:              cfg.Env,
+		LogLevel:         cfg.LogLevel,
+		FetchLimit:       cfg.FetchLimit,
+		FetchWorkers:     cfg.FetchWorkers,
+		FetchQueueSize:   cfg.FetchQueueSize,
+		FetchJobTimeout:  cfg.FetchJobTimeout.String(),
+		FetchMaxAttempts: cfg.FetchMaxAttempts,
+		FetchRetryDelay:  cfg.FetchRetryDelay.String(),
+		BreakerFailures:  cfg.BreakerFailures,
+		BreakerCooldown:  cfg.BreakerCooldown.String(),
+		CommentLimit:     cfg.CommentLimit,
+		HTTPPort:         cfg.HTTPPort,
+		MetricsPort:      cfg.MetricsPort,
+		GRPCPort:         cfg.GRPCPort,
+		MetricsOnAppPort: cfg.MetricsOnAppPort,
+		CompressionLevel: cfg.CompressionLevel,
+		RequireAPIKey:    cfg.RequireAPIKey,
+		JWT:              jwtConfigView{Secret: redact(cfg.JWT.Secret), PublicKey: cfg.JWT.PublicKey != "", Issuer: cfg.JWT.Issuer},
+		Database: databaseConfigView{
+			Driver:     cfg.Database.Driver,
+			SQLitePath: cfg.Database.SQLitePath,
+			URL:        redactURL(cfg.Database.URL),
+			Host:       cfg.Database.Host,
+			Port:       cfg.Database.Port,
+			User:       cfg.Database.User,
+			Password:   redact(cfg.Database.Password),
+			Name:       cfg.Database.Name,
+			SSLMode:    cfg.Database.SSLMode,
+
+			ReplicaURLs: redactURLs(cfg.Database.ReplicaURLs),
+			PartitionBy: cfg.Database.PartitionBy,
+
+			MaxOpenConns:    cfg.Database.MaxOpenConns,
+			MaxIdleConns:    cfg.Database.MaxIdleConns,
+			ConnMaxLifetime: cfg.Database.ConnMaxLifetime.String(),
+			ConnMaxIdleTime: cfg.Database.ConnMaxIdleTime.String(),
+		},
+		StorageBackend: cfg.StorageBackend,
+		MongoDB:        mongoDBConfigView{URI: redactURL(cfg.MongoDB.URI), Database: cfg.MongoDB.Database},
+		Elasticsearch: elasticConfigView{
+			URL:    redactURL(cfg.Elasticsearch.URL),
+			APIKey: redact(cfg.Elasticsearch.APIKey),
+			Index:  cfg.Elasticsearch.Index,
+		},
+		ClickHouse: clickHouseConfigView{URL: redactURL(cfg.ClickHouse.URL), Database: cfg.ClickHouse.Database},
+		Cache:      newCacheConfigView(cfg.Cache),
+		RateLimit: rateLimitConfigView{
+			Requests:      cfg.RateLimit.Requests,
+			Window:        cfg.RateLimit.Window.String(),
+			FetchRequests: cfg.RateLimit.FetchRequests,
+			FetchWindow:   cfg.RateLimit.FetchWindow.String(),
+		},
+		CORS: corsConfigView{
+			AllowedOrigins:   cfg.CORS.AllowedOrigins,
+			AllowedMethods:   cfg.CORS.AllowedMethods,
+			AllowedHeaders:   cfg.CORS.AllowedHeaders,
+			AllowCredentials: cfg.CORS.AllowCredentials,
+			MaxAge:           cfg.CORS.MaxAge.String(),
+		},
+		Milvus: milvusConfigView{URL: redactURL(cfg.Milvus.URL), Token: redact(cfg.Milvus.Token), Collection: cfg.Milvus.Collection},
+		Embedding: embeddingConfigView{
+			URL:        cfg.Embedding.URL,
+			APIKey:     redact(cfg.Embedding.APIKey),
+			Model:      cfg.Embedding.Model,
+			Dimensions: cfg.Embedding.Dimensions,
+		},
+		Archive:          archiveConfigView(cfg.Archive),
+		ExportDest:       redactURL(cfg.ExportDest),
+		ExportInterval:   cfg.ExportInterval.String(),
+		FetchSchedule:    cfg.FetchSchedule,
+		SourceSchedules:  cfg.SourceSchedules,
+		SecretBackend:    cfg.SecretBackend,
+		Flags:            cfg.Flags.All(),
+		GitHubTokens:     tokens,
+		GitHubAPI:        cfg.GitHubAPI,
+		StackExchangeKey: redact(cfg.StackExchangeKey),
+		TeamsToken:       redact(cfg.StackOverflowTeamsToken),
+		NVDAPIKey:        redact(cfg.NVDAPIKey),
+		LibrariesIOKey:   redact(cfg.LibrariesIOKey),
+		YouTubeAPIKey:    redact(cfg.YouTubeAPIKey),
+		QuotaReserve:     cfg.StackExchangeQuotaReserve,
+		GitLab:           gitLabConfigView{URL: cfg.GitLab.URL, Token: redact(cfg.GitLab.Token)},
+		Jira:             jiraConfigView{URL: cfg.Jira.URL, Email: cfg.Jira.Email, Token: redact(cfg.Jira.Token)},
+		Bitbucket:        bitbucketConfigView{Username: cfg.Bitbucket.Username, AppPassword: redact(cfg.Bitbucket.AppPassword)},
+		Mastodon:         cfg.MastodonInstances,
+		Frameworks:       cfg.Frameworks,
+	}
+}
+
+func newCacheConfigView(c CacheConfig) cacheConfigView {
+	view := cacheConfigView{RedisURL: redactURL(c.RedisURL), TTL: c.TTL.String()}
+	if len(c.RouteTTLs) > 0 {
+		view.RouteTTLs = map[string]string{}
+		for prefix, ttl := range c.RouteTTLs {
+			view.RouteTTLs[prefix] = ttl.String()
+		}
+	}
+	return view
+}
+
+func redact(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	return redacted
+}
+
+// redactURL masks the password in a connection URL, or the whole value if
+// it is not a URL (e.g. a keyword/value DSN).
+func redactURL(raw string) string {
+	if raw == "" {
+		return ""
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme == "" {
+		return redacted
+	}
+	return u.Redacted()
+}
+
+func redactURLs(raw []string) []string {
+	if len(raw) == 0 {
+		return nil
+	}
+	out := make([]string, len(raw))
+	for i, u := range raw {
+		out[i] = redactURL(u)
+	}
+	return out
+}
+
+func configHandler(store *configStore) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		return c.JSON(newConfigView(store.Get()))
+	}
+}
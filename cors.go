@@ -0,0 +1,58 @@
+package main
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/cors"
+)
+
+// CORSConfig lets browser applications served from AllowedOrigins call the
+// API. An origin of * allows every origin, which cannot be combined with
+// AllowCredentials. Preflight responses list AllowedMethods and
+// AllowedHeaders and may be cached by browsers for MaxAge. CORS headers
+// are not sent while AllowedOrigins is empty.
+type CORSConfig struct {
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	AllowCredentials bool
+	MaxAge           time.Duration
+}
+
+func (c CORSConfig) Enabled() bool {
+	return len(c.AllowedOrigins) > 0
+}
+
+// corsExposedHeaders are the response headers that browser applications
+// may read besides the CORS-safelisted ones.
+var corsExposedHeaders = []string{
+	fiber.HeaderETag,
+	fiber.HeaderLastModified,
+	fiber.HeaderRetryAfter,
+	"X-RateLimit-Limit",
+	"X-RateLimit-Remaining",
+	"X-RateLimit-Reset",
+	"X-Cache",
+	"Deprecation",
+	fiber.HeaderLink,
+	requestIDHeader,
+}
+
+// corsPolicy returns the CORS middleware for cfg, answering preflight
+// requests itself. It goes first so that preflights, which carry no
+// credentials, are not rate limited or rejected by authentication.
+func corsPolicy(cfg CORSConfig) fiber.Handler {
+	if !cfg.Enabled() {
+		return func(c *fiber.Ctx) error { return c.Next() }
+	}
+	return cors.New(cors.Config{
+		AllowOrigins:     strings.Join(cfg.AllowedOrigins, ","),
+		AllowMethods:     strings.Join(cfg.AllowedMethods, ","),
+		AllowHeaders:     strings.Join(cfg.AllowedHeaders, ","),
+		AllowCredentials: cfg.AllowCredentials,
+		ExposeHeaders:    strings.Join(corsExposedHeaders, ","),
+		MaxAge:           int(cfg.MaxAge.Seconds()),
+	})
+}
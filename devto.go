@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"gorm.io/gorm"
+)
+
+var devToAPICalls = promauto.NewCounter(prometheus.CounterOpts{
+	Name: "myapp_devto_api_calls_total",
+	Help: "Total number of API calls to the dev.to articles API",
+})
+
+// DevToArticle is a dev.to blog post tagged with a framework.
+type DevToArticle struct {
+	ID                     int       `json:"id" gorm:"primaryKey;autoIncrement:false"`
+	Framework              string    `json:"framework" gorm:"index"`
+	Title                  string    `json:"title"`
+	BodyMarkdown           string    `json:"body_markdown"`
+	URL                    string    `json:"url"`
+	Author                 string    `json:"author"`
+	Tags                   []string  `json:"tags" gorm:"serializer:json"`
+	PublicReactionsCount   int       `json:"public_reactions_count"`
+	PositiveReactionsCount int       `json:"positive_reactions_count"`
+	CommentsCount          int       `json:"comments_count"`
+	ReadingTimeMinutes     int       `json:"reading_time_minutes"`
+	PublishedAt            time.Time `json:"published_at"`
+}
+
+// fetchDevToArticles returns the latest articles carrying the framework's
+// dev.to tag. The listing omits body_markdown, so each article is fetched
+// individually as well.
+func fetchDevToArticles(ctx context.Context, cfg *Config, framework Framework) ([]DevToArticle, error) {
+	if framework.DevToTag == "" {
+		return nil, nil
+	}
+
+	devToAPICalls.Inc()
+	var list []struct {
+		ID int `json:"id"`
+	}
+	u := fmt.Sprintf("https://dev.to/api/articles/latest?tag=%s&per_page=%d",
+		url.QueryEscape(framework.DevToTag), cfg.FetchLimit)
+	if err := getJSON(ctx, u, nil, &list); err != nil {
+		return nil, err
+	}
+
+	articles := make([]DevToArticle, 0, len(list))
+	for _, entry := range list {
+		devToAPICalls.Inc()
+		var a struct {
+			ID                     int       `json:"id"`
+			Title                  string    `json:"title"`
+			BodyMarkdown           string    `json:"body_markdown"`
+			URL                    string    `json:"url"`
+			Tags                   []string  `json:"tags"`
+			PublicReactionsCount   int       `json:"public_reactions_count"`
+			PositiveReactionsCount int       `json:"positive_reactions_count"`
+			CommentsCount          int       `json:"comments_count"`
+			ReadingTimeMinutes     int       `json:"reading_time_minutes"`
+			PublishedAt            time.Time `json:"published_at"`
+			User                   struct {
+				Username string `json:"username"`
+			} `json:"user"`
+		}
+		if err := getJSON(ctx, fmt.Sprintf("https://dev.to/api/articles/%d", entry.ID), nil, &a); err != nil {
+			return articles, err
+		}
+
+		articles = append(articles, DevToArticle{
+			ID:                     a.ID,
+			Framework:              framework.Name,
+			Title:                  a.Title,
+			BodyMarkdown:           a.BodyMarkdown,
+			URL:                    a.URL,
+			Author:                 a.User.Username,
+			Tags:                   a.Tags,
+			PublicReactionsCount:   a.PublicReactionsCount,
+			PositiveReactionsCount: a.PositiveReactionsCount,
+			CommentsCount:          a.CommentsCount,
+			ReadingTimeMinutes:     a.ReadingTimeMinutes,
+			PublishedAt:            a.PublishedAt,
+		})
+	}
+	return articles, nil
+}
+
+func init() {
+	registerSource(func(cfg *Config) Source { return devToSource{cfg} })
+}
+
+// devToSource collects dev.to articles with a framework's tag.
+type devToSource struct{ cfg *Config }
+
+func (devToSource) Name() string { return "devto" }
+
+func (s devToSource) Fetch(ctx context.Context, framework Framework) ([]Item, error) {
+	articles, err := fetchDevToArticles(ctx, s.cfg, framework)
+	return asItems(articles), err
+}
+
+func (article DevToArticle) Save(db *gorm.DB) error {
+	return upsert(db, &article)
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"gorm.io/gorm"
+)
+
+var discourseAPICalls = promauto.NewCounter(prometheus.CounterOpts{
+	Name: "myapp_discourse_api_calls_total",
+	Help: "Total number of API calls to Discourse forums",
+})
+
+// DiscourseTopic is a forum topic. Topic IDs are only unique within a
+// forum, so the forum host is part of the key.
+type DiscourseTopic struct {
+	Forum        string    `json:"forum" gorm:"primaryKey"`
+	TopicID      int       `json:"topic_id" gorm:"primaryKey;autoIncrement:false"`
+	Framework    string    `json:"framework" gorm:"index"`
+	Category     string    `json:"category"`
+	Title        string    `json:"title"`
+	Body         string    `json:"body"`
+	ReplyCount   int       `json:"reply_count"`
+	Views        int       `json:"views"`
+	LikeCount    int       `json:"like_count"`
+	URL          string    `json:"url"`
+	CreatedAt    time.Time `json:"created_at"`
+	LastPostedAt time.Time `json:"last_posted_at"`
+}
+
+// fetchDiscourseTopics returns the latest topics from each configured
+// category of the framework's forum, or from the whole forum when no
+// categories are listed.
+func fetchDiscourseTopics(ctx context.Context, cfg *Config, framework Framework) ([]DiscourseTopic, error) {
+	if framework.DiscourseURL == "" {
+		return nil, nil
+	}
+	base := strings.TrimSuffix(framework.DiscourseURL, "/")
+	forum, err := url.Parse(base)
+	if err != nil {
+		return nil, fmt.Errorf("invalid discourse_url %q: %w", framework.DiscourseURL, err)
+	}
+
+	categories := framework.DiscourseCategories
+	if len(categories) == 0 {
+		categories = []string{""}
+	}
+
+	var topics []DiscourseTopic
+	for _, category := range categories {
+		listURL := base + "/latest.json"
+		if category != "" {
+			listURL = fmt.Sprintf("%s/c/%s/l/latest.json", base, url.PathEscape(category))
+		}
+
+		discourseAPICalls.Inc()
+		var list struct {
+			TopicList struct {
+				Topics []struct {
+					ID           int       `json:"id"`
+					Title        string    `json:"title"`
+					Slug         string    `json:"slug"`
+					ReplyCount   int       `json:"reply_count"`
+					Views        int       `json:"views"`
+					LikeCount    int       `json:"like_count"`
+					CreatedAt    time.Time `json:"created_at"`
+					LastPostedAt time.Time `json:"last_posted_at"`
+				} `json:"topics"`
+			} `json:"topic_list"`
+		}
+		if err := getJSON(ctx, listURL, nil, &list); err != nil {
+			return topics, err
+		}
+
+		for i, t := range list.TopicList.Topics {
+			if i == cfg.FetchLimit {
+				break
+			}
+			body, err := fetchDiscourseTopicBody(ctx, base, t.ID)
+			if err != nil {
+				return topics, err
+			}
+			topics = append(topics, DiscourseTopic{
+				Forum:        forum.Host,
+				TopicID:      t.ID,
+				Framework:    framework.Name,
+				Category:     category,
+				Title:        t.Title,
+				Body:         body,
+				ReplyCount:   t.ReplyCount,
+				Views:        t.Views,
+				LikeCount:    t.LikeCount,
+				URL:          fmt.Sprintf("%s/t/%s/%d", base, t.Slug, t.ID),
+				CreatedAt:    t.CreatedAt,
+				LastPostedAt: t.LastPostedAt,
+			})
+		}
+	}
+	return topics, nil
+}
+
+// fetchDiscourseTopicBody returns the rendered HTML of a topic's first post.
+func fetchDiscourseTopicBody(ctx context.Context, base string, topicID int) (string, error) {
+	discourseAPICalls.Inc()
+
+	var topic struct {
+		PostStream struct {
+			Posts []struct {
+				Cooked string `json:"cooked"`
+			} `json:"posts"`
+		} `json:"post_stream"`
+	}
+	if err := getJSON(ctx, fmt.Sprintf("%s/t/%d.json", base, topicID), nil, &topic); err != nil {
+		return "", err
+	}
+	if len(topic.PostStream.Posts) == 0 {
+		return "", nil
+	}
+	return topic.PostStream.Posts[0].Cooked, nil
+}
+
+func init() {
+	registerSource(func(cfg *Config) Source { return discourseSource{cfg} })
+}
+
+// discourseSource collects topics from a framework's Discourse forum.
+type discourseSource struct{ cfg *Config }
+
+func (discourseSource) Name() string { return "discourse" }
+
+func (s discourseSource) Fetch(ctx context.Context, framework Framework) ([]Item, error) {
+	topics, err := fetchDiscourseTopics(ctx, s.cfg, framework)
+	return asItems(topics), err
+}
+
+func (topic DiscourseTopic) Save(db *gorm.DB) error {
+	return upsert(db, &topic)
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"gorm.io/gorm"
+)
+
+var dockerHubAPICalls = promauto.NewCounter(prometheus.CounterOpts{
+	Name: "myapp_dockerhub_api_calls_total",
+	Help: "Total number of API calls to Docker Hub",
+})
+
+// DockerHubSnapshot records an image's pull and star counts for one UTC
+// day. Re-running the job on the same day overwrites that day's row.
+type DockerHubSnapshot struct {
+	Image      string    `json:"image" gorm:"primaryKey"`
+	Date       time.Time `json:"date" gorm:"primaryKey;type:date"`
+	Framework  string    `json:"framework" gorm:"index"`
+	PullCount  int64     `json:"pull_count"`
+	StarCount  int       `json:"star_count"`
+	RecordedAt time.Time `json:"recorded_at"`
+}
+
+// dockerHubRepository expands official image names such as "golang" to
+// the "library/golang" form the API expects.
+func dockerHubRepository(image string) string {
+	if !strings.Contains(image, "/") {
+		return "library/" + image
+	}
+	return image
+}
+
+func fetchDockerHubSnapshot(ctx context.Context, framework Framework, image string) (DockerHubSnapshot, error) {
+	dockerHubAPICalls.Inc()
+
+	var repo struct {
+		PullCount int64 `json:"pull_count"`
+		StarCount int   `json:"star_count"`
+	}
+	u := fmt.Sprintf("https://hub.docker.com/v2/repositories/%s/", dockerHubRepository(image))
+	if err := getJSON(ctx, u, nil, &repo); err != nil {
+		return DockerHubSnapshot{}, err
+	}
+
+	now := time.Now().UTC()
+	return DockerHubSnapshot{
+		Image:      dockerHubRepository(image),
+		Date:       now.Truncate(24 * time.Hour),
+		Framework:  framework.Name,
+		PullCount:  repo.PullCount,
+		StarCount:  repo.StarCount,
+		RecordedAt: now,
+	}, nil
+}
+
+// recordDockerHubSnapshots takes today's snapshot of every image attached
+// to a tracked framework.
+func recordDockerHubSnapshots(ctx context.Context, db *gorm.DB) {
+	frameworks, err := loadFrameworks(db)
+	if err != nil {
+		log.Printf("Error loading framework registry: %v", err)
+		return
+	}
+
+	for _, framework := range frameworks {
+		for _, image := range framework.DockerImages {
+			snapshot, err := fetchDockerHubSnapshot(ctx, framework, image)
+			if err != nil {
+				log.Printf("Error fetching Docker Hub snapshot of %s for %s: %v", image, framework.Name, err)
+				continue
+			}
+			if err := snapshot.Save(db); err != nil {
+				log.Printf("Error storing Docker Hub snapshot of %s for %s: %v", image, framework.Name, err)
+			}
+		}
+	}
+}
+
+func (snapshot DockerHubSnapshot) Save(db *gorm.DB) error {
+	return upsert(db, &snapshot)
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	_ "embed"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// openAPISpec documents every endpoint registered by runServer; keep it in
+// step with the routes.
+//
+//go:embed openapi.yaml
+var openAPISpec []byte
+
+// swaggerUIPage renders the spec with Swagger UI, loaded from a CDN so the
+// binary does not carry its assets.
+const swaggerUIPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>Microservices Data Fetcher API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js" crossorigin></script>
+  <script>
+    window.ui = SwaggerUIBundle({url: "/openapi.yaml", dom_id: "#swagger-ui"});
+  </script>
+</body>
+</html>`
+
+// openAPIHandler serves GET /openapi.yaml, the OpenAPI 3 document.
+func openAPIHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Set(fiber.HeaderContentType, "application/yaml")
+		return c.Send(openAPISpec)
+	}
+}
+
+// docsHandler serves GET /docs, Swagger UI for the OpenAPI document.
+func docsHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+		return c.SendString(swaggerUIPage)
+	}
+}
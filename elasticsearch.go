@@ -0,0 +1,236 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// esBulkSize is the number of documents sent per _bulk request.
+const esBulkSize = 500
+
+// ElasticsearchConfig locates the cluster used for full-text search. URL
+// may carry basic-auth credentials; APIKey, when set, is sent instead as an
+// ApiKey authorization header. Indexing and the Elasticsearch-backed
+// search endpoint are skipped entirely while URL is empty.
+type ElasticsearchConfig struct {
+	URL    string
+	APIKey string
+	Index  string
+}
+
+func (e ElasticsearchConfig) Enabled() bool {
+	return e.URL != ""
+}
+
+// esIndexSettings strips the HTML in StackExchange bodies before analysis
+// so that markup neither matches queries nor shows up in highlights.
+const esIndexSettings = `{
+  "settings": {
+    "analysis": {
+      "analyzer": {
+        "content": {"type": "custom", "char_filter": ["html_strip"], "tokenizer": "standard", "filter": ["lowercase", "asciifolding"]}
+      }
+    }
+  },
+  "mappings": {
+    "properties": {
+      "kind":       {"type": "keyword"},
+      "site":       {"type": "keyword"},
+      "ref":        {"type": "integer"},
+      "title":      {"type": "text", "analyzer": "content"},
+      "body":       {"type": "text", "analyzer": "content"},
+      "indexed_at": {"type": "date"}
+    }
+  }
+}`
+
+// searchDocument is the Elasticsearch representation of a post or issue.
+// Ref is the question ID or issue number.
+type searchDocument struct {
+	ID        string    `json:"-"`
+	Kind      string    `json:"kind"`
+	Site      string    `json:"site,omitempty"`
+	Ref       int       `json:"ref"`
+	Title     string    `json:"title"`
+	Body      string    `json:"body"`
+	IndexedAt time.Time `json:"indexed_at"`
+}
+
+// searchable is implemented by items indexed for full-text search.
+type searchable interface {
+	Item
+	searchDocument() searchDocument
+}
+
+func (post StackOverflowPost) searchDocument() searchDocument {
+	return searchDocument{
+		ID:    fmt.Sprintf("post:%s:%d", post.Site, post.QuestionID),
+		Kind:  "post",
+		Site:  post.Site,
+		Ref:   post.QuestionID,
+		Title: post.Title,
+		Body:  post.Body,
+	}
+}
+
+func (issue GitHubIssue) searchDocument() searchDocument {
+	return searchDocument{
+		ID:    fmt.Sprintf("issue:%d", issue.ID),
+		Kind:  "issue",
+		Ref:   issue.Number,
+		Title: issue.Title,
+		Body:  issue.Body,
+	}
+}
+
+// searchIndexer wraps a Store and additionally indexes every searchable
+// item into Elasticsearch, batching documents into _bulk requests. Pending
+// documents are flushed on Close.
+type searchIndexer struct {
+	Store
+	es ElasticsearchConfig
+
+	mu      sync.Mutex
+	pending []searchDocument
+	ready   bool
+}
+
+func newSearchIndexer(es ElasticsearchConfig, next Store) *searchIndexer {
+	return &searchIndexer{Store: next, es: es}
+}
+
+func (s *searchIndexer) Save(ctx context.Context, items ...Item) error {
+	if err := s.Store.Save(ctx, items...); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, item := range items {
+		doc, ok := item.(searchable)
+		if !ok {
+			continue
+		}
+		d := doc.searchDocument()
+		d.IndexedAt = time.Now().UTC()
+		s.pending = append(s.pending, d)
+		if len(s.pending) < esBulkSize {
+			continue
+		}
+		if err := s.flush(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *searchIndexer) Close(ctx context.Context) error {
+	s.mu.Lock()
+	err := s.flush(ctx)
+	s.mu.Unlock()
+	if closeErr := s.Store.Close(ctx); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// flush sends the pending documents in one _bulk request, creating the
+// index first if needed. The caller must hold s.mu.
+func (s *searchIndexer) flush(ctx context.Context) error {
+	if len(s.pending) == 0 {
+		return nil
+	}
+	if !s.ready {
+		if err := ensureSearchIndex(ctx, s.es); err != nil {
+			return err
+		}
+		s.ready = true
+	}
+
+	var body bytes.Buffer
+	enc := json.NewEncoder(&body)
+	for _, doc := range s.pending {
+		enc.Encode(map[string]interface{}{"index": map[string]string{"_id": doc.ID}})
+		enc.Encode(doc)
+	}
+	count := len(s.pending)
+	s.pending = s.pending[:0]
+
+	var result struct {
+		Errors bool `json:"errors"`
+		Items  []map[string]struct {
+			ID    string          `json:"_id"`
+			Error json.RawMessage `json:"error"`
+		} `json:"items"`
+	}
+	if err := esDo(ctx, s.es, http.MethodPost, "/"+s.es.Index+"/_bulk", "application/x-ndjson", &body, &result); err != nil {
+		return fmt.Errorf("indexing %d documents: %w", count, err)
+	}
+	if result.Errors {
+		for _, item := range result.Items {
+			for _, op := range item {
+				if len(op.Error) > 0 {
+					return fmt.Errorf("indexing document %s: %s", op.ID, truncate(string(op.Error), 200))
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// ensureSearchIndex creates the search index with its mapping unless it
+// already exists.
+func ensureSearchIndex(ctx context.Context, es ElasticsearchConfig) error {
+	err := esDo(ctx, es, http.MethodHead, "/"+es.Index, "", nil, nil)
+	if err == nil {
+		return nil
+	}
+	if !strings.Contains(err.Error(), "status 404") {
+		return err
+	}
+	return esDo(ctx, es, http.MethodPut, "/"+es.Index, "application/json", strings.NewReader(esIndexSettings), nil)
+}
+
+// esDo sends a request to Elasticsearch and decodes the JSON response into
+// out, if out is not nil. Unlike doRequest, the response does not count
+// towards the collected-bytes metric.
+func esDo(ctx context.Context, es ElasticsearchConfig, method, path, contentType string, body io.Reader, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(es.URL, "/")+path, body)
+	if err != nil {
+		return err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if es.APIKey != "" {
+		req.Header.Set("Authorization", "ApiKey "+es.APIKey)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("reading response from %s: %w", req.URL.Redacted(), err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%s %s: status %d: %s", method, req.URL.Redacted(), resp.StatusCode, truncate(strings.TrimSpace(string(data)), 200))
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("decoding response from %s: %w", req.URL.Redacted(), err)
+	}
+	return nil
+}
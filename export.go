@@ -0,0 +1,271 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/parquet-go/parquet-go"
+	"gorm.io/gorm"
+)
+
+// exportBatchSize is the number of rows buffered before each Parquet write.
+const exportBatchSize = 1000
+
+// postRow and issueRow are the Parquet schemas of the exported datasets.
+type postRow struct {
+	QuestionID int64  `parquet:"question_id"`
+	Site       string `parquet:"site,dict"`
+	Title      string `parquet:"title"`
+	Body       string `parquet:"body"`
+}
+
+type answerRow struct {
+	AnswerID     int64     `parquet:"answer_id"`
+	QuestionID   int64     `parquet:"question_id"`
+	Site         string    `parquet:"site,dict"`
+	Body         string    `parquet:"body"`
+	Score        int64     `parquet:"score"`
+	IsAccepted   bool      `parquet:"is_accepted"`
+	Owner        string    `parquet:"owner"`
+	CreationDate time.Time `parquet:"creation_date,timestamp"`
+}
+
+type issueRow struct {
+	ID        int64    `parquet:"id"`
+	Number    int64    `parquet:"number"`
+	Title     string   `parquet:"title"`
+	Body      string   `parquet:"body"`
+	Labels    []string `parquet:"labels,list"`
+	Comments  int64    `parquet:"comments"`
+	Reactions int64    `parquet:"reactions"`
+	State     string   `parquet:"state,dict"`
+	Author    string   `parquet:"author"`
+	Assignees []string `parquet:"assignees,list"`
+
+	CreatedAt time.Time  `parquet:"created_at,timestamp"`
+	UpdatedAt time.Time  `parquet:"updated_at,timestamp"`
+	ClosedAt  *time.Time `parquet:"closed_at,timestamp,optional"`
+}
+
+// exportSink creates the files of an export, either below a local
+// directory or below an s3://bucket/prefix location.
+type exportSink interface {
+	Create(ctx context.Context, name string) (io.WriteCloser, error)
+}
+
+type localSink struct{ dir string }
+
+func (s localSink) Create(_ context.Context, name string) (io.WriteCloser, error) {
+	p := filepath.Join(s.dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return nil, err
+	}
+	return os.Create(p)
+}
+
+// s3Sink stages each file in a temporary file and uploads it on Close, as
+// the Parquet footer is only known once all rows are written.
+type s3Sink struct {
+	client *s3.Client
+	bucket string
+	prefix string
+}
+
+type s3Upload struct {
+	*os.File
+	ctx  context.Context
+	sink s3Sink
+	key  string
+}
+
+func (s s3Sink) Create(ctx context.Context, name string) (io.WriteCloser, error) {
+	f, err := os.CreateTemp("", "export-*.parquet")
+	if err != nil {
+		return nil, err
+	}
+	return &s3Upload{File: f, ctx: ctx, sink: s, key: path.Join(s.prefix, name)}, nil
+}
+
+func (u *s3Upload) Close() error {
+	defer os.Remove(u.Name())
+	defer u.File.Close()
+
+	if _, err := u.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	_, err := u.sink.client.PutObject(u.ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(u.sink.bucket),
+		Key:         aws.String(u.key),
+		Body:        u.File,
+		ContentType: aws.String("application/vnd.apache.parquet"),
+	})
+	if err != nil {
+		return fmt.Errorf("uploading s3://%s/%s: %w", u.sink.bucket, u.key, err)
+	}
+	return nil
+}
+
+// newExportSink resolves dest, a directory or s3://bucket/prefix URL.
+func newExportSink(ctx context.Context, cfg *Config, dest string) (exportSink, error) {
+	rest, ok := strings.CutPrefix(dest, "s3://")
+	if !ok {
+		return localSink{dest}, nil
+	}
+	bucket, prefix, _ := strings.Cut(rest, "/")
+	client, err := newS3Client(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return s3Sink{client: client, bucket: bucket, prefix: prefix}, nil
+}
+
+// exportDatasets writes the stored posts, answers and issues to dest as
+// Hive-partitioned Parquet files that Spark and DuckDB can read directly:
+//
+//	posts/site=<site>/export_date=<date>/part-<time>.parquet
+//	answers/site=<site>/export_date=<date>/part-<time>.parquet
+//	issues/export_date=<date>/part-<time>.parquet
+func exportDatasets(ctx context.Context, db *gorm.DB, cfg *Config, dest string) error {
+	sink, err := newExportSink(ctx, cfg, dest)
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	partition := "export_date=" + now.Format("2006-01-02")
+	part := "part-" + now.Format("150405") + ".parquet"
+
+	var sites []string
+	if err := db.Model(&StackOverflowPost{}).Distinct("site").Order("site").Pluck("site", &sites).Error; err != nil {
+		return fmt.Errorf("listing post sites: %w", err)
+	}
+	for _, site := range sites {
+		name := path.Join("posts", "site="+site, partition, part)
+		n, err := exportTable(ctx, db.Model(&StackOverflowPost{}).Where("site = ?", site), sink, name, func(post StackOverflowPost) postRow {
+			return postRow{
+				QuestionID: int64(post.QuestionID),
+				Site:       post.Site,
+				Title:      post.Title,
+				Body:       post.Body,
+			}
+		})
+		if err != nil {
+			return fmt.Errorf("exporting %s posts: %w", site, err)
+		}
+		log.Printf("Exported %d %s posts to %s", n, site, name)
+
+		name = path.Join("answers", "site="+site, partition, part)
+		n, err = exportTable(ctx, db.Model(&StackOverflowAnswer{}).Where("site = ?", site).Order("answer_id"), sink, name, func(answer StackOverflowAnswer) answerRow {
+			return answerRow{
+				AnswerID:     int64(answer.AnswerID),
+				QuestionID:   int64(answer.QuestionID),
+				Site:         answer.Site,
+				Body:         answer.Body,
+				Score:        int64(answer.Score),
+				IsAccepted:   answer.IsAccepted,
+				Owner:        answer.Owner,
+				CreationDate: answer.CreationDate,
+			}
+		})
+		if err != nil {
+			return fmt.Errorf("exporting %s answers: %w", site, err)
+		}
+		log.Printf("Exported %d %s answers to %s", n, site, name)
+	}
+
+	name := path.Join("issues", partition, part)
+	n, err := exportTable(ctx, db.Model(&GitHubIssue{}).Order("id"), sink, name, func(issue GitHubIssue) issueRow {
+		return issueRow{
+			ID:        int64(issue.ID),
+			Number:    int64(issue.Number),
+			Title:     issue.Title,
+			Body:      issue.Body,
+			Labels:    issue.Labels,
+			Comments:  int64(issue.Comments),
+			Reactions: int64(issue.Reactions),
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("exporting issues: %w", err)
+	}
+	log.Printf("Exported %d issues to %s", n, name)
+	return nil
+}
+
+// exportTable streams the rows selected by query into a single Parquet
+// file, converting each record with toRow.
+func exportTable[M any, R any](ctx context.Context, query *gorm.DB, sink exportSink, name string, toRow func(M) R) (int, error) {
+	rows, err := query.Rows()
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	out, err := sink.Create(ctx, name)
+	if err != nil {
+		return 0, err
+	}
+	w := parquet.NewGenericWriter[R](out, parquet.Compression(&parquet.Zstd))
+
+	count := 0
+	batch := make([]R, 0, exportBatchSize)
+	for rows.Next() {
+		var record M
+		if err := query.ScanRows(rows, &record); err != nil {
+			out.Close()
+			return count, err
+		}
+		batch = append(batch, toRow(record))
+		if len(batch) == exportBatchSize {
+			if _, err := w.Write(batch); err != nil {
+				out.Close()
+				return count, err
+			}
+			count += len(batch)
+			batch = batch[:0]
+		}
+	}
+	if err := rows.Err(); err != nil {
+		out.Close()
+		return count, err
+	}
+	if _, err := w.Write(batch); err != nil {
+		out.Close()
+		return count, err
+	}
+	count += len(batch)
+
+	if err := w.Close(); err != nil {
+		out.Close()
+		return count, err
+	}
+	return count, out.Close()
+}
+
+// runScheduledExports exports the datasets to cfg.ExportDest every
+// cfg.ExportInterval.
+func runScheduledExports(ctx context.Context, db *gorm.DB, store *configStore, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		cfg := store.Get()
+		if err := exportDatasets(ctx, db, cfg, cfg.ExportDest); err != nil {
+			log.Printf("Error exporting datasets: %v", err)
+		}
+	}
+}
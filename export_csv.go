@@ -0,0 +1,216 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// streamBatchSize is the number of records loaded per query while
+// streaming an export.
+const streamBatchSize = 500
+
+// exportFilter is the dataset selected by the ?entity=, ?framework=,
+// ?from=, ?to= and ?limit= parameters of the streaming export endpoints.
+type exportFilter struct {
+	Entity    string
+	Framework string
+	TimeRange
+	Limit int
+}
+
+// exportParams parses the parameters of the export endpoints. The limit
+// defaults to defaultLimit rows and may be at most maxLimit; zero for
+// both leaves the export unlimited unless ?limit= is given.
+func exportParams(c *fiber.Ctx, defaultLimit, maxLimit int) (exportFilter, error) {
+	exp := exportFilter{Entity: c.Query("entity"), Framework: c.Query("framework")}
+	switch exp.Entity {
+	case "posts", "answers", "issues":
+	default:
+		return exp, fiber.NewError(fiber.StatusBadRequest, "entity must be posts, answers or issues")
+	}
+	period, err := timeRangeParams(c)
+	if err != nil {
+		return exp, err
+	}
+	exp.TimeRange = period
+	limit, err := strconv.Atoi(c.Query("limit", strconv.Itoa(defaultLimit)))
+	switch {
+	case maxLimit > 0 && (err != nil || limit < 1 || limit > maxLimit):
+		return exp, fiber.NewError(fiber.StatusBadRequest, "limit must be between 1 and "+strconv.Itoa(maxLimit))
+	case err != nil || limit < 0:
+		return exp, fiber.NewError(fiber.StatusBadRequest, "limit must not be negative")
+	}
+	exp.Limit = limit
+	return exp, nil
+}
+
+// query selects the records of the export in key order; see eachBatch.
+func (exp exportFilter) query(db *gorm.DB) *gorm.DB {
+	var query *gorm.DB
+	switch exp.Entity {
+	case "posts":
+		query = exp.where(db.Model(&StackOverflowPost{}).Preload("Tags").Order("site, question_id"), "creation_date")
+	case "answers":
+		query = exp.where(db.Model(&StackOverflowAnswer{}).Order("answer_id"), "creation_date")
+	default:
+		query = exp.where(db.Model(&GitHubIssue{}).Order("id"), "issue_created_at")
+	}
+	if exp.Framework != "" {
+		query = query.Where("LOWER(framework) = ?", strings.ToLower(exp.Framework))
+	}
+	return query
+}
+
+// eachBatch calls fn with consecutive batches of the records of type M
+// selected by exp, up to exp.Limit unless it is zero, stopping at the
+// first error. Each batch resumes after the last record of the previous
+// one through after, so large exports do not slow down with the offset.
+func eachBatch[M any](ctx context.Context, db *gorm.DB, exp exportFilter, after func(*gorm.DB, M) *gorm.DB, fn func([]M) error) error {
+	var last *M
+	for sent := 0; exp.Limit == 0 || sent < exp.Limit; {
+		size := streamBatchSize
+		if exp.Limit > 0 {
+			size = min(size, exp.Limit-sent)
+		}
+		query := exp.query(db.WithContext(ctx)).Limit(size)
+		if last != nil {
+			query = after(query, *last)
+		}
+		var batch []M
+		if err := query.Find(&batch).Error; err != nil {
+			return err
+		}
+		if len(batch) > 0 {
+			if err := fn(batch); err != nil {
+				return err
+			}
+			sent += len(batch)
+			last = &batch[len(batch)-1]
+		}
+		if len(batch) < size {
+			return nil
+		}
+	}
+	return nil
+}
+
+// The keys the exported records are ordered by, for eachBatch.
+func postsAfter(query *gorm.DB, p StackOverflowPost) *gorm.DB {
+	return query.Where("site > ? OR (site = ? AND question_id > ?)", p.Site, p.Site, p.QuestionID)
+}
+
+func answersAfter(query *gorm.DB, a StackOverflowAnswer) *gorm.DB {
+	return query.Where("answer_id > ?", a.AnswerID)
+}
+
+func issuesAfter(query *gorm.DB, i GitHubIssue) *gorm.DB {
+	return query.Where("id > ?", i.ID)
+}
+
+// csvText guards a text cell against formula injection: spreadsheets
+// evaluate cells starting with these characters, so they are prefixed
+// with a quote.
+func csvText(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+func csvTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func csvList(values []string) string {
+	texts := make([]string, len(values))
+	for i, v := range values {
+		texts[i] = csvText(v)
+	}
+	return strings.Join(texts, ";")
+}
+
+var csvHeaders = map[string][]string{
+	"posts":   {"question_id", "site", "framework", "title", "body", "score", "tags", "creation_date", "collected_at"},
+	"answers": {"answer_id", "question_id", "site", "framework", "body", "score", "is_accepted", "owner", "creation_date"},
+	"issues": {"id", "number", "repo", "framework", "title", "body", "state", "author", "labels", "assignees",
+		"comments", "reactions", "created_at", "updated_at", "closed_at"},
+}
+
+// writeCSV writes the header and rows of exp to w, flushing after each
+// batch.
+func writeCSV(ctx context.Context, db *gorm.DB, exp exportFilter, w *csv.Writer) error {
+	if err := w.Write(csvHeaders[exp.Entity]); err != nil {
+		return err
+	}
+	flush := func() error {
+		w.Flush()
+		return w.Error()
+	}
+
+	switch exp.Entity {
+	case "posts":
+		return eachBatch(ctx, db, exp, postsAfter, func(posts []StackOverflowPost) error {
+			for _, p := range posts {
+				w.Write([]string{strconv.Itoa(p.QuestionID), p.Site, csvText(p.Framework), csvText(p.Title), csvText(p.Body),
+					strconv.Itoa(p.Score), csvList(p.tagNames()), csvTime(p.CreationDate), csvTime(p.CreatedAt)})
+			}
+			return flush()
+		})
+	case "answers":
+		return eachBatch(ctx, db, exp, answersAfter, func(answers []StackOverflowAnswer) error {
+			for _, a := range answers {
+				w.Write([]string{strconv.Itoa(a.AnswerID), strconv.Itoa(a.QuestionID), a.Site, csvText(a.Framework), csvText(a.Body),
+					strconv.Itoa(a.Score), strconv.FormatBool(a.IsAccepted), csvText(a.Owner), csvTime(a.CreationDate)})
+			}
+			return flush()
+		})
+	default:
+		return eachBatch(ctx, db, exp, issuesAfter, func(issues []GitHubIssue) error {
+			for _, i := range issues {
+				closedAt := ""
+				if i.ClosedAt != nil {
+					closedAt = csvTime(*i.ClosedAt)
+				}
+				w.Write([]string{strconv.Itoa(i.ID), strconv.Itoa(i.Number), i.Repo, csvText(i.Framework), csvText(i.Title), csvText(i.Body),
+					i.State, csvText(i.Author), csvList(i.Labels), csvList(i.Assignees), strconv.Itoa(i.Comments), strconv.Itoa(i.Reactions),
+					csvTime(i.IssueCreatedAt), csvTime(i.IssueUpdatedAt), closedAt})
+			}
+			return flush()
+		})
+	}
+}
+
+// exportCSVHandler serves
+// GET /api/v1/export/csv?entity=posts|answers|issues, streaming at most
+// ?limit= (default 10000, up to 100000) rows of the dataset, optionally
+// only for ?framework=, as a CSV attachment. Like the
+// Parquet export it reads the relational database. An error once rows are
+// sent can only end the download early; it is logged.
+func exportCSVHandler(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		exp, err := exportParams(c, 10000, 100000)
+		if err != nil {
+			return err
+		}
+
+		c.Attachment(exp.Entity + ".csv")
+		c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+		c.Context().SetBodyStreamWriter(func(bw *bufio.Writer) {
+			if err := writeCSV(context.Background(), db, exp, csv.NewWriter(bw)); err != nil {
+				log.Printf("Error exporting %s as CSV: %v", exp.Entity, err)
+			}
+		})
+		return nil
+	}
+}
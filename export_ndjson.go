@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// writeNDJSON writes the records of exp to w as one JSON object per line,
+// in the same form as the listing endpoints, flushing after each batch so
+// that only one batch is held in memory. Flushing blocks while the client
+// is not reading, which holds back the next query.
+func writeNDJSON(ctx context.Context, db *gorm.DB, exp exportFilter, w *bufio.Writer) error {
+	enc := json.NewEncoder(w)
+	switch exp.Entity {
+	case "posts":
+		return eachBatch(ctx, db, exp, postsAfter, func(posts []StackOverflowPost) error {
+			return encodeBatch(enc, w, posts)
+		})
+	case "answers":
+		return eachBatch(ctx, db, exp, answersAfter, func(answers []StackOverflowAnswer) error {
+			return encodeBatch(enc, w, answers)
+		})
+	default:
+		return eachBatch(ctx, db, exp, issuesAfter, func(issues []GitHubIssue) error {
+			return encodeBatch(enc, w, issues)
+		})
+	}
+}
+
+func encodeBatch[M any](enc *json.Encoder, w *bufio.Writer, batch []M) error {
+	for _, record := range batch {
+		if err := enc.Encode(record); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
+
+// exportNDJSONHandler serves
+// GET /api/v1/export/ndjson?entity=posts|answers|issues, streaming the
+// whole dataset, or ?limit= records, optionally only for ?framework=, as
+// newline-delimited JSON with chunked encoding. Errors are handled as by
+// /api/v1/export/csv.
+func exportNDJSONHandler(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		exp, err := exportParams(c, 0, 0)
+		if err != nil {
+			return err
+		}
+
+		c.Set(fiber.HeaderContentType, "application/x-ndjson")
+		c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+			if err := writeNDJSON(context.Background(), db, exp, w); err != nil {
+				log.Printf("Error exporting %s as NDJSON: %v", exp.Entity, err)
+			}
+		})
+		return nil
+	}
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"gorm.io/gorm"
+)
+
+var feedFetches = promauto.NewCounter(prometheus.CounterOpts{
+	Name: "myapp_feed_fetches_total",
+	Help: "Total number of RSS/Atom feed fetches",
+})
+
+// FeedEntry is an item from an RSS or Atom feed attached to a framework.
+// Entries are deduplicated by GUID, falling back to the entry link for
+// feeds that omit it.
+type FeedEntry struct {
+	GUID        string    `json:"guid" gorm:"primaryKey"`
+	Framework   string    `json:"framework" gorm:"index"`
+	Feed        string    `json:"feed"`
+	Title       string    `json:"title"`
+	Link        string    `json:"link"`
+	Author      string    `json:"author"`
+	Content     string    `json:"content"`
+	PublishedAt time.Time `json:"published_at"`
+}
+
+// feedDocument decodes both RSS 2.0 (<rss><channel><item>) and Atom
+// (<feed><entry>) documents; only one of Items and Entries is populated.
+type feedDocument struct {
+	Items []struct {
+		GUID        string `xml:"guid"`
+		Title       string `xml:"title"`
+		Link        string `xml:"link"`
+		Author      string `xml:"author"`
+		Creator     string `xml:"http://purl.org/dc/elements/1.1/ creator"`
+		Description string `xml:"description"`
+		Content     string `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+		PubDate     string `xml:"pubDate"`
+	} `xml:"channel>item"`
+	Entries []struct {
+		ID    string `xml:"id"`
+		Title string `xml:"title"`
+		Links []struct {
+			Href string `xml:"href,attr"`
+			Rel  string `xml:"rel,attr"`
+		} `xml:"link"`
+		Author struct {
+			Name string `xml:"name"`
+		} `xml:"author"`
+		Summary   string `xml:"summary"`
+		Content   string `xml:"content"`
+		Published string `xml:"published"`
+		Updated   string `xml:"updated"`
+	} `xml:"entry"`
+}
+
+var feedTimeLayouts = []string{time.RFC1123Z, time.RFC1123, time.RFC3339, "Mon, 2 Jan 2006 15:04:05 -0700", "Mon, 2 Jan 2006 15:04:05 MST"}
+
+func parseFeedTime(value string) time.Time {
+	value = strings.TrimSpace(value)
+	for _, layout := range feedTimeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.UTC()
+		}
+	}
+	return time.Time{}
+}
+
+// fetchFeedEntries polls every feed attached to the framework and returns
+// up to cfg.FetchLimit entries from each. A failing feed does not stop the
+// others; the first error is returned alongside the entries collected.
+func fetchFeedEntries(ctx context.Context, cfg *Config, framework Framework) ([]FeedEntry, error) {
+	var entries []FeedEntry
+	var firstErr error
+	for _, feed := range framework.Feeds {
+		items, err := fetchFeed(ctx, cfg, framework, feed)
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+		entries = append(entries, items...)
+	}
+	return entries, firstErr
+}
+
+func fetchFeed(ctx context.Context, cfg *Config, framework Framework, feed string) ([]FeedEntry, error) {
+	feedFetches.Inc()
+
+	var doc feedDocument
+	if err := getXML(ctx, feed, &doc); err != nil {
+		return nil, err
+	}
+
+	var entries []FeedEntry
+	for _, item := range doc.Items {
+		entry := FeedEntry{
+			GUID:        strings.TrimSpace(item.GUID),
+			Framework:   framework.Name,
+			Feed:        feed,
+			Title:       strings.TrimSpace(item.Title),
+			Link:        strings.TrimSpace(item.Link),
+			Author:      item.Author,
+			Content:     item.Content,
+			PublishedAt: parseFeedTime(item.PubDate),
+		}
+		if entry.Author == "" {
+			entry.Author = item.Creator
+		}
+		if entry.Content == "" {
+			entry.Content = item.Description
+		}
+		entries = append(entries, entry)
+	}
+	for _, item := range doc.Entries {
+		entry := FeedEntry{
+			GUID:        strings.TrimSpace(item.ID),
+			Framework:   framework.Name,
+			Feed:        feed,
+			Title:       strings.TrimSpace(item.Title),
+			Author:      item.Author.Name,
+			Content:     item.Content,
+			PublishedAt: parseFeedTime(item.Published),
+		}
+		for _, link := range item.Links {
+			if link.Rel == "" || link.Rel == "alternate" {
+				entry.Link = link.Href
+				break
+			}
+		}
+		if entry.Content == "" {
+			entry.Content = item.Summary
+		}
+		if entry.PublishedAt.IsZero() {
+			entry.PublishedAt = parseFeedTime(item.Updated)
+		}
+		entries = append(entries, entry)
+	}
+
+	kept := entries[:0]
+	for _, entry := range entries {
+		if entry.GUID == "" {
+			entry.GUID = entry.Link
+		}
+		if entry.GUID == "" {
+			continue
+		}
+		kept = append(kept, entry)
+		if len(kept) == cfg.FetchLimit {
+			break
+		}
+	}
+	return kept, nil
+}
+
+func init() {
+	registerSource(func(cfg *Config) Source { return feedSource{cfg} })
+}
+
+// feedSource polls the RSS and Atom feeds attached to a framework.
+type feedSource struct{ cfg *Config }
+
+func (feedSource) Name() string { return "feeds" }
+
+func (s feedSource) Fetch(ctx context.Context, framework Framework) ([]Item, error) {
+	entries, err := fetchFeedEntries(ctx, s.cfg, framework)
+	return asItems(entries), err
+}
+
+func (entry FeedEntry) Save(db *gorm.DB) error {
+	return upsert(db, &entry)
+}
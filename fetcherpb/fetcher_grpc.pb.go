@@ -0,0 +1,255 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: fetcher/v1/fetcher.proto
+
+// Package fetcher.v1 serves the collected corpus to internal services; it
+// mirrors the /api/v1 HTTP endpoints. Regenerate the Go code in fetcherpb
+// with `go generate` after editing.
+
+package fetcherpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Fetcher_ListPosts_FullMethodName    = "/fetcher.v1.Fetcher/ListPosts"
+	Fetcher_ListIssues_FullMethodName   = "/fetcher.v1.Fetcher/ListIssues"
+	Fetcher_TriggerFetch_FullMethodName = "/fetcher.v1.Fetcher/TriggerFetch"
+	Fetcher_GetStats_FullMethodName     = "/fetcher.v1.Fetcher/GetStats"
+)
+
+// FetcherClient is the client API for Fetcher service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type FetcherClient interface {
+	// ListPosts returns one page of stored StackOverflow posts, most
+	// recently collected first.
+	ListPosts(ctx context.Context, in *ListPostsRequest, opts ...grpc.CallOption) (*ListPostsResponse, error)
+	// ListIssues returns one page of stored GitHub issues, newest first.
+	ListIssues(ctx context.Context, in *ListIssuesRequest, opts ...grpc.CallOption) (*ListIssuesResponse, error)
+	// TriggerFetch starts a collection pass and streams the job's status
+	// as it progresses, ending with the finished job.
+	TriggerFetch(ctx context.Context, in *TriggerFetchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Job], error)
+	// GetStats returns record counts and fetch activity per framework.
+	GetStats(ctx context.Context, in *GetStatsRequest, opts ...grpc.CallOption) (*GetStatsResponse, error)
+}
+
+type fetcherClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewFetcherClient(cc grpc.ClientConnInterface) FetcherClient {
+	return &fetcherClient{cc}
+}
+
+func (c *fetcherClient) ListPosts(ctx context.Context, in *ListPostsRequest, opts ...grpc.CallOption) (*ListPostsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListPostsResponse)
+	err := c.cc.Invoke(ctx, Fetcher_ListPosts_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fetcherClient) ListIssues(ctx context.Context, in *ListIssuesRequest, opts ...grpc.CallOption) (*ListIssuesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListIssuesResponse)
+	err := c.cc.Invoke(ctx, Fetcher_ListIssues_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fetcherClient) TriggerFetch(ctx context.Context, in *TriggerFetchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Job], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Fetcher_ServiceDesc.Streams[0], Fetcher_TriggerFetch_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[TriggerFetchRequest, Job]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Fetcher_TriggerFetchClient = grpc.ServerStreamingClient[Job]
+
+func (c *fetcherClient) GetStats(ctx context.Context, in *GetStatsRequest, opts ...grpc.CallOption) (*GetStatsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetStatsResponse)
+	err := c.cc.Invoke(ctx, Fetcher_GetStats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// FetcherServer is the server API for Fetcher service.
+// All implementations must embed UnimplementedFetcherServer
+// for forward compatibility.
+type FetcherServer interface {
+	// ListPosts returns one page of stored StackOverflow posts, most
+	// recently collected first.
+	ListPosts(context.Context, *ListPostsRequest) (*ListPostsResponse, error)
+	// ListIssues returns one page of stored GitHub issues, newest first.
+	ListIssues(context.Context, *ListIssuesRequest) (*ListIssuesResponse, error)
+	// TriggerFetch starts a collection pass and streams the job's status
+	// as it progresses, ending with the finished job.
+	TriggerFetch(*TriggerFetchRequest, grpc.ServerStreamingServer[Job]) error
+	// GetStats returns record counts and fetch activity per framework.
+	GetStats(context.Context, *GetStatsRequest) (*GetStatsResponse, error)
+	mustEmbedUnimplementedFetcherServer()
+}
+
+// UnimplementedFetcherServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedFetcherServer struct{}
+
+func (UnimplementedFetcherServer) ListPosts(context.Context, *ListPostsRequest) (*ListPostsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListPosts not implemented")
+}
+func (UnimplementedFetcherServer) ListIssues(context.Context, *ListIssuesRequest) (*ListIssuesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListIssues not implemented")
+}
+func (UnimplementedFetcherServer) TriggerFetch(*TriggerFetchRequest, grpc.ServerStreamingServer[Job]) error {
+	return status.Errorf(codes.Unimplemented, "method TriggerFetch not implemented")
+}
+func (UnimplementedFetcherServer) GetStats(context.Context, *GetStatsRequest) (*GetStatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStats not implemented")
+}
+func (UnimplementedFetcherServer) mustEmbedUnimplementedFetcherServer() {}
+func (UnimplementedFetcherServer) testEmbeddedByValue()                 {}
+
+// UnsafeFetcherServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to FetcherServer will
+// result in compilation errors.
+type UnsafeFetcherServer interface {
+	mustEmbedUnimplementedFetcherServer()
+}
+
+func RegisterFetcherServer(s grpc.ServiceRegistrar, srv FetcherServer) {
+	// If the following call pancis, it indicates UnimplementedFetcherServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Fetcher_ServiceDesc, srv)
+}
+
+func _Fetcher_ListPosts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListPostsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FetcherServer).ListPosts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Fetcher_ListPosts_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FetcherServer).ListPosts(ctx, req.(*ListPostsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Fetcher_ListIssues_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListIssuesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FetcherServer).ListIssues(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Fetcher_ListIssues_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FetcherServer).ListIssues(ctx, req.(*ListIssuesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Fetcher_TriggerFetch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(TriggerFetchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(FetcherServer).TriggerFetch(m, &grpc.GenericServerStream[TriggerFetchRequest, Job]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Fetcher_TriggerFetchServer = grpc.ServerStreamingServer[Job]
+
+func _Fetcher_GetStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FetcherServer).GetStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Fetcher_GetStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FetcherServer).GetStats(ctx, req.(*GetStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Fetcher_ServiceDesc is the grpc.ServiceDesc for Fetcher service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Fetcher_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "fetcher.v1.Fetcher",
+	HandlerType: (*FetcherServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListPosts",
+			Handler:    _Fetcher_ListPosts_Handler,
+		},
+		{
+			MethodName: "ListIssues",
+			Handler:    _Fetcher_ListIssues_Handler,
+		},
+		{
+			MethodName: "GetStats",
+			Handler:    _Fetcher_GetStats_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "TriggerFetch",
+			Handler:       _Fetcher_TriggerFetch_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "fetcher/v1/fetcher.proto",
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Feature flag names. Every flag must have an entry in defaultFlags; each
+// registered Source is switched by source.<Name()>.
+const (
+	flagSourceStackOverflow = "source.stackoverflow"
+	flagSourceGitHub        = "source.github"
+	flagSourceReddit        = "source.reddit"
+	flagSourceHackerNews    = "source.hackernews"
+	flagSourceGitLab        = "source.gitlab"
+	flagSourceJira          = "source.jira"
+	flagSourceDiscourse     = "source.discourse"
+	flagSourceDiscussions   = "source.github_discussions"
+	flagSourcePullRequests  = "source.github_pulls"
+	flagSourceReleases      = "source.github_releases"
+	flagSourceSnapshots     = "source.github_snapshots"
+	flagSourceCommitStats   = "source.github_commit_stats"
+	flagSourceAnswers       = "source.stackoverflow_answers"
+	flagSourceComments      = "source.comments"
+	flagSourceDevTo         = "source.devto"
+	flagSourceFeeds         = "source.feeds"
+	flagSourceMastodon      = "source.mastodon"
+	flagSourceNpm           = "source.npm"
+	flagSourcePyPI          = "source.pypi"
+	flagSourceDockerHub     = "source.dockerhub"
+	flagSourceGoProxy       = "source.goproxy"
+	flagSourceNVD           = "source.nvd"
+	flagSourceAdvisories    = "source.github_advisories"
+	flagSourceLibrariesIO   = "source.librariesio"
+	flagSourceBitbucket     = "source.bitbucket"
+	flagSourceYouTube       = "source.youtube"
+	flagSourceArxiv         = "source.arxiv"
+	flagSourceLobsters      = "source.lobsters"
+
+	// flagLegacyRoutes keeps serving the data endpoints at the paths they
+	// had before the API was versioned; see deprecatedRoute.
+	flagLegacyRoutes = "api.legacy_routes"
+)
+
+var defaultFlags = map[string]bool{
+	flagSourceStackOverflow: true,
+	flagSourceGitHub:        true,
+	flagSourceReddit:        true,
+	flagSourceHackerNews:    true,
+	flagSourceGitLab:        true,
+	flagSourceJira:          true,
+	flagSourceDiscourse:     true,
+	flagSourceDiscussions:   true,
+	flagSourcePullRequests:  true,
+	flagSourceReleases:      true,
+	flagSourceSnapshots:     true,
+	flagSourceCommitStats:   true,
+	flagSourceAnswers:       true,
+	flagSourceComments:      true,
+	flagSourceDevTo:         true,
+	flagSourceFeeds:         true,
+	flagSourceMastodon:      true,
+	flagSourceNpm:           true,
+	flagSourcePyPI:          true,
+	flagSourceDockerHub:     true,
+	flagSourceGoProxy:       true,
+	flagSourceNVD:           true,
+	flagSourceAdvisories:    true,
+	flagSourceLibrariesIO:   true,
+	flagSourceBitbucket:     true,
+	flagSourceYouTube:       true,
+	flagSourceArxiv:         true,
+	flagSourceLobsters:      true,
+	flagLegacyRoutes:        true,
+}
+
+// FeatureFlags switches collectors and pipelines on or off. Flags are
+// resolved from, in increasing precedence: defaultFlags, the `flags`
+// section of the config file, the file's `environments.<APP_ENV>.flags`
+// section, and the FEATURE_FLAGS environment variable
+// (e.g. "source.github=false,source.stackoverflow=true").
+type FeatureFlags map[string]bool
+
+func (f FeatureFlags) Enabled(name string) bool {
+	if v, ok := f[name]; ok {
+		return v
+	}
+	return defaultFlags[name]
+}
+
+// All returns the resolved value of every known flag.
+func (f FeatureFlags) All() map[string]bool {
+	all := make(map[string]bool, len(defaultFlags))
+	for name := range defaultFlags {
+		all[name] = f.Enabled(name)
+	}
+	return all
+}
+
+// unknown returns the names of flags that are set but not defined, sorted.
+func (f FeatureFlags) unknown() []string {
+	var names []string
+	for name := range f {
+		if _, ok := defaultFlags[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// parseFlagList parses the FEATURE_FLAGS format into overrides.
+func parseFlagList(value string) (FeatureFlags, error) {
+	flags := FeatureFlags{}
+	for _, item := range splitList(value) {
+		name, raw, ok := strings.Cut(item, "=")
+		if !ok {
+			return nil, fmt.Errorf("FEATURE_FLAGS entry %q must be name=true|false", item)
+		}
+		enabled, err := strconv.ParseBool(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, fmt.Errorf("FEATURE_FLAGS entry %q must be name=true|false", item)
+		}
+		flags[strings.TrimSpace(name)] = enabled
+	}
+	return flags, nil
+}
+
+// mergeFlags applies each layer of overrides in order.
+func mergeFlags(layers ...FeatureFlags) FeatureFlags {
+	merged := FeatureFlags{}
+	for _, layer := range layers {
+		for name, enabled := range layer {
+			merged[name] = enabled
+		}
+	}
+	return merged
+}
+
+func statusHandler(store *configStore) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		cfg := store.Get()
+		return c.JSON(fiber.Map{
+			"env":      cfg.Env,
+			"flags":    cfg.Flags.All(),
+			"schedule": fetchSchedule.Status(),
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// seedFrameworks fills an empty frameworks table from the configured list so
+// a fresh database starts out tracking the same projects as before.
+func seedFrameworks(db *gorm.DB, frameworks []Framework) error {
+	var count int64
+	if err := db.Model(&Framework{}).Count(&count).Error; err != nil {
+		return fmt.Errorf("counting frameworks: %w", err)
+	}
+	if count > 0 {
+		return nil
+	}
+
+	seed := make([]Framework, len(frameworks))
+	copy(seed, frameworks)
+	if err := db.Create(&seed).Error; err != nil {
+		return fmt.Errorf("seeding frameworks: %w", err)
+	}
+	log.Printf("Seeded framework registry with %d frameworks", len(seed))
+	return nil
+}
+
+// addConfiguredFrameworks adds the configured frameworks missing from the
+// registry, matched by name case-insensitively, and returns how many it
+// added. Registered frameworks are left as they are, since they may have
+// been edited through the API.
+func addConfiguredFrameworks(db *gorm.DB, frameworks []Framework) (int, error) {
+	registered, err := loadFrameworks(db)
+	if err != nil {
+		return 0, err
+	}
+	var missing []Framework
+	for _, framework := range frameworks {
+		if !slices.ContainsFunc(registered, func(f Framework) bool { return strings.EqualFold(f.Name, framework.Name) }) {
+			framework.ID = 0
+			missing = append(missing, framework)
+		}
+	}
+	if len(missing) == 0 {
+		return 0, nil
+	}
+	if err := db.Create(&missing).Error; err != nil {
+		return 0, fmt.Errorf("adding configured frameworks: %w", err)
+	}
+	return len(missing), nil
+}
+
+// loadFrameworks returns the registry contents in insertion order.
+func loadFrameworks(db *gorm.DB) ([]Framework, error) {
+	var frameworks []Framework
+	if err := db.Order("id").Find(&frameworks).Error; err != nil {
+		return nil, fmt.Errorf("loading frameworks: %w", err)
+	}
+	return frameworks, nil
+}
+
+// findFramework looks a framework up by name, case-insensitively.
+func findFramework(db *gorm.DB, name string) (*Framework, error) {
+	var framework Framework
+	err := db.Where("LOWER(name) = ?", strings.ToLower(name)).First(&framework).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fiber.NewError(fiber.StatusNotFound, fmt.Sprintf("framework %q not found", name))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &framework, nil
+}
+
+func parseFramework(c *fiber.Ctx) (Framework, error) {
+	var framework Framework
+	if err := c.BodyParser(&framework); err != nil {
+		return framework, fiber.NewError(fiber.StatusBadRequest, "invalid framework JSON: "+err.Error())
+	}
+	if problems := framework.validate(); len(problems) > 0 {
+		return framework, fiber.NewError(fiber.StatusBadRequest, strings.Join(problems, "; "))
+	}
+	return framework, nil
+}
+
+func listFrameworksHandler(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		frameworks, err := loadFrameworks(db)
+		if err != nil {
+			return err
+		}
+		return c.JSON(frameworks)
+	}
+}
+
+func getFrameworkHandler(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		framework, err := findFramework(db, c.Params("name"))
+		if err != nil {
+			return err
+		}
+		return c.JSON(framework)
+	}
+}
+
+func createFrameworkHandler(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		framework, err := parseFramework(c)
+		if err != nil {
+			return err
+		}
+		if _, err := findFramework(db, framework.Name); err == nil {
+			return fiber.NewError(fiber.StatusConflict, fmt.Sprintf("framework %q already exists", framework.Name))
+		}
+
+		framework.ID = 0
+		if err := db.Create(&framework).Error; err != nil {
+			return err
+		}
+		return c.Status(fiber.StatusCreated).JSON(framework)
+	}
+}
+
+func updateFrameworkHandler(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		existing, err := findFramework(db, c.Params("name"))
+		if err != nil {
+			return err
+		}
+		framework, err := parseFramework(c)
+		if err != nil {
+			return err
+		}
+		if !strings.EqualFold(framework.Name, existing.Name) {
+			if _, err := findFramework(db, framework.Name); err == nil {
+				return fiber.NewError(fiber.StatusConflict, fmt.Sprintf("framework %q already exists", framework.Name))
+			}
+		}
+
+		framework.ID = existing.ID
+		if err := db.Save(&framework).Error; err != nil {
+			return err
+		}
+		return c.JSON(framework)
+	}
+}
+
+func deleteFrameworkHandler(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		framework, err := findFramework(db, c.Params("name"))
+		if err != nil {
+			return err
+		}
+		if err := db.Delete(framework).Error; err != nil {
+			return err
+		}
+		return c.SendStatus(fiber.StatusNoContent)
+	}
+}
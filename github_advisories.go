@@ -0,0 +1,189 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// GitHubAdvisory is a GitHub Security Advisory affecting one of the
+// packages a framework publishes. An advisory can cover several packages,
+// so it is keyed by the package as well.
+type GitHubAdvisory struct {
+	GHSAID          string     `json:"ghsa_id" gorm:"primaryKey"`
+	Framework       string     `json:"framework" gorm:"primaryKey"`
+	Ecosystem       string     `json:"ecosystem" gorm:"primaryKey"`
+	Package         string     `json:"package" gorm:"primaryKey"`
+	Summary         string     `json:"summary"`
+	Description     string     `json:"description"`
+	Severity        string     `json:"severity"`
+	CVSSScore       float64    `json:"cvss_score"`
+	CVSSVector      string     `json:"cvss_vector"`
+	CVEIDs          []string   `json:"cve_ids" gorm:"serializer:json"`
+	VulnerableRange string     `json:"vulnerable_range"`
+	PatchedVersion  string     `json:"patched_version"`
+	Permalink       string     `json:"permalink"`
+	PublishedAt     time.Time  `json:"published_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+	WithdrawnAt     *time.Time `json:"withdrawn_at,omitempty"`
+}
+
+const githubAdvisoriesQuery = `
+query($ecosystem: SecurityAdvisoryEcosystem!, $package: String!, $first: Int!) {
+  securityVulnerabilities(ecosystem: $ecosystem, package: $package, first: $first, orderBy: {field: UPDATED_AT, direction: DESC}) {
+    nodes {
+      vulnerableVersionRange
+      firstPatchedVersion { identifier }
+      package { name ecosystem }
+      advisory {
+        ghsaId
+        summary
+        description
+        severity
+        permalink
+        publishedAt
+        updatedAt
+        withdrawnAt
+        identifiers { type value }
+        cvss { score vectorString }
+      }
+    }
+  }
+}`
+
+type advisoryPackage struct {
+	ecosystem string
+	name      string
+}
+
+// advisoryPackages lists the packages a framework publishes in ecosystems
+// covered by the GitHub Advisory Database.
+func (f Framework) advisoryPackages() []advisoryPackage {
+	var packages []advisoryPackage
+	if f.GoModule != "" {
+		packages = append(packages, advisoryPackage{"GO", f.GoModule})
+	}
+	if f.NpmPackage != "" {
+		packages = append(packages, advisoryPackage{"NPM", f.NpmPackage})
+	}
+	if f.PyPIPackage != "" {
+		packages = append(packages, advisoryPackage{"PIP", f.PyPIPackage})
+	}
+	return packages
+}
+
+func init() {
+	registerSource(func(cfg *Config) Source { return gitHubAdvisorySource{cfg} })
+}
+
+// gitHubAdvisorySource collects security advisories for a framework's packages.
+type gitHubAdvisorySource struct{ cfg *Config }
+
+func (gitHubAdvisorySource) Name() string { return "github_advisories" }
+
+func (s gitHubAdvisorySource) Fetch(ctx context.Context, framework Framework) ([]Item, error) {
+	advisories, err := fetchGitHubAdvisories(ctx, s.cfg, framework)
+	return asItems(advisories), err
+}
+
+// fetchGitHubAdvisories returns the most recently updated advisories for
+// each of the framework's packages.
+func fetchGitHubAdvisories(ctx context.Context, cfg *Config, framework Framework) ([]GitHubAdvisory, error) {
+	var advisories []GitHubAdvisory
+	for _, pkg := range framework.advisoryPackages() {
+		var data struct {
+			SecurityVulnerabilities struct {
+				Nodes []struct {
+					VulnerableVersionRange string `json:"vulnerableVersionRange"`
+					FirstPatchedVersion    *struct {
+						Identifier string `json:"identifier"`
+					} `json:"firstPatchedVersion"`
+					Package struct {
+						Name      string `json:"name"`
+						Ecosystem string `json:"ecosystem"`
+					} `json:"package"`
+					Advisory struct {
+						GHSAID      string     `json:"ghsaId"`
+						Summary     string     `json:"summary"`
+						Description string     `json:"description"`
+						Severity    string     `json:"severity"`
+						Permalink   string     `json:"permalink"`
+						PublishedAt time.Time  `json:"publishedAt"`
+						UpdatedAt   time.Time  `json:"updatedAt"`
+						WithdrawnAt *time.Time `json:"withdrawnAt"`
+						Identifiers []struct {
+							Type  string `json:"type"`
+							Value string `json:"value"`
+						} `json:"identifiers"`
+						CVSS struct {
+							Score        float64 `json:"score"`
+							VectorString string  `json:"vectorString"`
+						} `json:"cvss"`
+					} `json:"advisory"`
+				} `json:"nodes"`
+			} `json:"securityVulnerabilities"`
+		}
+
+		vars := map[string]interface{}{"ecosystem": pkg.ecosystem, "package": pkg.name, "first": cfg.FetchLimit}
+		if err := githubGraphQL(ctx, githubAdvisoriesQuery, vars, &data); err != nil {
+			return advisories, err
+		}
+
+		for _, n := range data.SecurityVulnerabilities.Nodes {
+			a := GitHubAdvisory{
+				GHSAID:          n.Advisory.GHSAID,
+				Framework:       framework.Name,
+				Ecosystem:       n.Package.Ecosystem,
+				Package:         n.Package.Name,
+				Summary:         n.Advisory.Summary,
+				Description:     n.Advisory.Description,
+				Severity:        n.Advisory.Severity,
+				CVSSScore:       n.Advisory.CVSS.Score,
+				CVSSVector:      n.Advisory.CVSS.VectorString,
+				VulnerableRange: n.VulnerableVersionRange,
+				Permalink:       n.Advisory.Permalink,
+				PublishedAt:     n.Advisory.PublishedAt,
+				UpdatedAt:       n.Advisory.UpdatedAt,
+				WithdrawnAt:     n.Advisory.WithdrawnAt,
+			}
+			if n.FirstPatchedVersion != nil {
+				a.PatchedVersion = n.FirstPatchedVersion.Identifier
+			}
+			for _, id := range n.Advisory.Identifiers {
+				if id.Type == "CVE" {
+					a.CVEIDs = append(a.CVEIDs, id.Value)
+				}
+			}
+			advisories = append(advisories, a)
+		}
+	}
+	return advisories, nil
+}
+
+func (advisory GitHubAdvisory) Save(db *gorm.DB) error {
+	return upsert(db, &advisory)
+}
+
+// listAdvisoriesHandler serves GET /api/v1/frameworks/:name/advisories, newest
+// first. Withdrawn advisories are left out unless ?withdrawn=true.
+func listAdvisoriesHandler(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		framework, err := findFramework(db, c.Params("name"))
+		if err != nil {
+			return err
+		}
+
+		query := db.Where("framework = ?", framework.Name).Order("published_at DESC")
+		if !c.QueryBool("withdrawn") {
+			query = query.Where("withdrawn_at IS NULL")
+		}
+
+		var advisories []GitHubAdvisory
+		if err := query.Find(&advisories).Error; err != nil {
+			return err
+		}
+		return c.JSON(advisories)
+	}
+}
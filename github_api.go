@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const (
+	githubAPIURL     = "https://api.github.com"
+	githubGraphQLURL = githubAPIURL + "/graphql"
+)
+
+// githubGet fetches a REST v3 resource with a token from the pool, feeding
+// the response's rate-limit headers back into the pool. Transient failures
+// are retried according to apiRetry.
+func githubGet(ctx context.Context, url string, out interface{}) error {
+	return apiRetry.Do(ctx, "github", func() error { return githubGetOnce(ctx, url, out) })
+}
+
+func githubGetOnce(ctx context.Context, url string, out interface{}) error {
+	token := githubTokens.Next()
+	if token == nil {
+		return errors.New("no GitHub token configured")
+	}
+	githubAPICalls.Inc()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "token "+token.value)
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+
+	header, err := doJSONHeader(req, out)
+	if header != nil {
+		githubTokens.Update(token, header)
+	}
+	return err
+}
+
+// githubGraphQL runs a GraphQL v4 query with a token from the pool and
+// decodes the response's data field into out. Transient failures are
+// retried according to apiRetry.
+func githubGraphQL(ctx context.Context, query string, variables map[string]interface{}, out interface{}) error {
+	return apiRetry.Do(ctx, "github", func() error { return githubGraphQLOnce(ctx, query, variables, out) })
+}
+
+func githubGraphQLOnce(ctx context.Context, query string, variables map[string]interface{}, out interface{}) error {
+	token := githubTokens.Next()
+	if token == nil {
+		return errors.New("no GitHub token configured")
+	}
+	githubAPICalls.Inc()
+
+	header := http.Header{}
+	header.Set("Authorization", "bearer "+token.value)
+
+	var resp struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	body := map[string]interface{}{"query": query, "variables": variables}
+	if err := postJSON(ctx, githubGraphQLURL, header, body, &resp); err != nil {
+		return err
+	}
+	if len(resp.Errors) > 0 {
+		messages := make([]string, len(resp.Errors))
+		for i, e := range resp.Errors {
+			messages[i] = e.Message
+		}
+		return fmt.Errorf("github graphql: %s", strings.Join(messages, "; "))
+	}
+	return json.Unmarshal(resp.Data, out)
+}
+
+type githubActor struct {
+	Login string `json:"login"`
+}
+
+type githubLabel struct {
+	Name string `json:"name"`
+}
+
+func actorLogins(users []githubActor) []string {
+	logins := make([]string, len(users))
+	for i, u := range users {
+		logins[i] = u.Login
+	}
+	return logins
+}
+
+func labelNames(labels []githubLabel) []string {
+	names := make([]string, len(labels))
+	for i, l := range labels {
+		names[i] = l.Name
+	}
+	return names
+}
+
+// splitRepo splits an owner/name repository path.
+func splitRepo(repo string) (owner, name string) {
+	owner, name, _ = strings.Cut(repo, "/")
+	return owner, name
+}
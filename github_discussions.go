@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type GitHubDiscussion struct {
+	ID                string     `json:"id" gorm:"primaryKey"`
+	Number            int        `json:"number"`
+	Framework         string     `json:"framework" gorm:"index"`
+	Repo              string     `json:"repo"`
+	Title             string     `json:"title"`
+	Body              string     `json:"body"`
+	URL               string     `json:"url"`
+	Category          string     `json:"category"`
+	Author            string     `json:"author"`
+	UpvoteCount       int        `json:"upvote_count"`
+	CommentCount      int        `json:"comment_count"`
+	AnswerID          string     `json:"answer_id,omitempty"`
+	AnswerBody        string     `json:"answer_body,omitempty"`
+	AnswerAuthor      string     `json:"answer_author,omitempty"`
+	AnswerUpvoteCount int        `json:"answer_upvote_count"`
+	AnswerChosenAt    *time.Time `json:"answer_chosen_at,omitempty"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+}
+
+const githubDiscussionsQuery = `
+query($owner: String!, $name: String!, $first: Int!) {
+  repository(owner: $owner, name: $name) {
+    discussions(first: $first, orderBy: {field: UPDATED_AT, direction: DESC}) {
+      nodes {
+        id
+        number
+        title
+        body
+        url
+        upvoteCount
+        createdAt
+        updatedAt
+        answerChosenAt
+        author { login }
+        category { name }
+        comments { totalCount }
+        answer { id body upvoteCount author { login } }
+      }
+    }
+  }
+}`
+
+// fetchGitHubDiscussions returns the most recently updated discussions of
+// the framework's repository, with their accepted answers.
+func fetchGitHubDiscussions(ctx context.Context, cfg *Config, framework Framework) ([]GitHubDiscussion, error) {
+	if framework.GitHubRepo == "" {
+		return nil, nil
+	}
+	var data struct {
+		Repository *struct {
+			Discussions struct {
+				Nodes []struct {
+					ID             string       `json:"id"`
+					Number         int          `json:"number"`
+					Title          string       `json:"title"`
+					Body           string       `json:"body"`
+					URL            string       `json:"url"`
+					UpvoteCount    int          `json:"upvoteCount"`
+					CreatedAt      time.Time    `json:"createdAt"`
+					UpdatedAt      time.Time    `json:"updatedAt"`
+					AnswerChosenAt *time.Time   `json:"answerChosenAt"`
+					Author         *githubActor `json:"author"`
+					Category       struct {
+						Name string `json:"name"`
+					} `json:"category"`
+					Comments struct {
+						TotalCount int `json:"totalCount"`
+					} `json:"comments"`
+					Answer *struct {
+						ID          string       `json:"id"`
+						Body        string       `json:"body"`
+						UpvoteCount int          `json:"upvoteCount"`
+						Author      *githubActor `json:"author"`
+					} `json:"answer"`
+				} `json:"nodes"`
+			} `json:"discussions"`
+		} `json:"repository"`
+	}
+
+	owner, name := splitRepo(framework.GitHubRepo)
+	vars := map[string]interface{}{"owner": owner, "name": name, "first": cfg.FetchLimit}
+	if err := githubGraphQL(ctx, githubDiscussionsQuery, vars, &data); err != nil {
+		return nil, err
+	}
+	if data.Repository == nil {
+		return nil, nil
+	}
+
+	var discussions []GitHubDiscussion
+	for _, n := range data.Repository.Discussions.Nodes {
+		d := GitHubDiscussion{
+			ID:             n.ID,
+			Number:         n.Number,
+			Framework:      framework.Name,
+			Repo:           framework.GitHubRepo,
+			Title:          n.Title,
+			Body:           n.Body,
+			URL:            n.URL,
+			Category:       n.Category.Name,
+			UpvoteCount:    n.UpvoteCount,
+			CommentCount:   n.Comments.TotalCount,
+			AnswerChosenAt: n.AnswerChosenAt,
+			CreatedAt:      n.CreatedAt,
+			UpdatedAt:      n.UpdatedAt,
+		}
+		if n.Author != nil {
+			d.Author = n.Author.Login
+		}
+		if n.Answer != nil {
+			d.AnswerID = n.Answer.ID
+			d.AnswerBody = n.Answer.Body
+			d.AnswerUpvoteCount = n.Answer.UpvoteCount
+			if n.Answer.Author != nil {
+				d.AnswerAuthor = n.Answer.Author.Login
+			}
+		}
+		discussions = append(discussions, d)
+	}
+	return discussions, nil
+}
+
+func init() {
+	registerSource(func(cfg *Config) Source { return gitHubDiscussionSource{cfg} })
+}
+
+// gitHubDiscussionSource collects a repository's GitHub Discussions.
+type gitHubDiscussionSource struct{ cfg *Config }
+
+func (gitHubDiscussionSource) Name() string { return "github_discussions" }
+
+func (s gitHubDiscussionSource) Fetch(ctx context.Context, framework Framework) ([]Item, error) {
+	discussions, err := fetchGitHubDiscussions(ctx, s.cfg, framework)
+	return asItems(discussions), err
+}
+
+func (discussion GitHubDiscussion) Save(db *gorm.DB) error {
+	return upsert(db, &discussion)
+}
+
+// listDiscussionsHandler serves GET /api/v1/discussions, optionally
+// filtered by ?framework=, ?answered=true and when they were started,
+// ?from= and ?to=, newest first, capped by ?limit=.
+func listDiscussionsHandler(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		limit, err := strconv.Atoi(c.Query("limit", "50"))
+		if err != nil || limit < 1 || limit > 500 {
+			return fiber.NewError(fiber.StatusBadRequest, "limit must be between 1 and 500")
+		}
+
+		period, err := timeRangeParams(c)
+		if err != nil {
+			return err
+		}
+
+		query := period.where(db.Order("updated_at DESC").Limit(limit), "created_at")
+		if framework := c.Query("framework"); framework != "" {
+			query = query.Where("LOWER(framework) = ?", strings.ToLower(framework))
+		}
+		if c.QueryBool("answered") {
+			query = query.Where("answer_id <> ''")
+		}
+
+		var discussions []GitHubDiscussion
+		if err := query.Find(&discussions).Error; err != nil {
+			return err
+		}
+		return c.JSON(discussions)
+	}
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Values of GITHUB_API, which selects how gitHubIssueSource fetches issues.
+const (
+	githubAPIREST    = "rest"
+	githubAPIGraphQL = "graphql"
+)
+
+const githubIssuesQuery = `
+query($owner: String!, $name: String!, $first: Int!) {
+  repository(owner: $owner, name: $name) {
+    issues(first: $first, states: OPEN, orderBy: {field: CREATED_AT, direction: DESC}) {
+      nodes {
+        databaseId
+        number
+        title
+        body
+        state
+        createdAt
+        updatedAt
+        closedAt
+        author { login }
+        assignees(first: 20) { nodes { login } }
+        comments { totalCount }
+        reactions { totalCount }
+        labels(first: 50) { nodes { name } }
+      }
+    }
+  }
+}`
+
+// fetchGitHubIssuesGraphQL is the GraphQL counterpart of fetchGitHubIssues.
+// It returns the same open issues, with labels, assignees, reactions and
+// comment counts, in a single request per repository.
+func fetchGitHubIssuesGraphQL(ctx context.Context, cfg *Config, framework Framework) ([]GitHubIssue, error) {
+	if framework.GitHubRepo == "" {
+		return nil, nil
+	}
+	var data struct {
+		Repository *struct {
+			Issues struct {
+				Nodes []json.RawMessage `json:"nodes"`
+			} `json:"issues"`
+		} `json:"repository"`
+	}
+
+	owner, name := splitRepo(framework.GitHubRepo)
+	vars := map[string]interface{}{"owner": owner, "name": name, "first": cfg.FetchLimit}
+	if err := githubGraphQL(ctx, githubIssuesQuery, vars, &data); err != nil {
+		return nil, err
+	}
+	if data.Repository == nil {
+		return nil, nil
+	}
+
+	issues := make([]GitHubIssue, 0, len(data.Repository.Issues.Nodes))
+	for _, raw := range data.Repository.Issues.Nodes {
+		var n struct {
+			DatabaseID int          `json:"databaseId"`
+			Number     int          `json:"number"`
+			Title      string       `json:"title"`
+			Body       string       `json:"body"`
+			State      string       `json:"state"`
+			CreatedAt  time.Time    `json:"createdAt"`
+			UpdatedAt  time.Time    `json:"updatedAt"`
+			ClosedAt   *time.Time   `json:"closedAt"`
+			Author     *githubActor `json:"author"`
+			Assignees  struct {
+				Nodes []githubActor `json:"nodes"`
+			} `json:"assignees"`
+			Comments struct {
+				TotalCount int `json:"totalCount"`
+			} `json:"comments"`
+			Reactions struct {
+				TotalCount int `json:"totalCount"`
+			} `json:"reactions"`
+			Labels struct {
+				Nodes []githubLabel `json:"nodes"`
+			} `json:"labels"`
+		}
+		if err := json.Unmarshal(raw, &n); err != nil {
+			return issues, fmt.Errorf("decoding issue: %w", err)
+		}
+		// author is null for issues opened by since-deleted accounts.
+		var author string
+		if n.Author != nil {
+			author = n.Author.Login
+		}
+		issues = append(issues, GitHubIssue{
+			ID:             n.DatabaseID,
+			Number:         n.Number,
+			Title:          n.Title,
+			Body:           n.Body,
+			Labels:         labelNames(n.Labels.Nodes),
+			Comments:       n.Comments.TotalCount,
+			Reactions:      n.Reactions.TotalCount,
+			State:          strings.ToLower(n.State),
+			Author:         author,
+			Assignees:      actorLogins(n.Assignees.Nodes),
+			Framework:      framework.Name,
+			Repo:           framework.GitHubRepo,
+			IssueCreatedAt: n.CreatedAt,
+			IssueUpdatedAt: n.UpdatedAt,
+			ClosedAt:       n.ClosedAt,
+			CommentsURL:    fmt.Sprintf("%s/repos/%s/issues/%d/comments", githubAPIURL, framework.GitHubRepo, n.Number),
+			Raw:            raw,
+		})
+	}
+	return issues, nil
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type GitHubPullRequest struct {
+	ID           int        `json:"id" gorm:"primaryKey;autoIncrement:false"`
+	Number       int        `json:"number"`
+	Framework    string     `json:"framework" gorm:"index"`
+	Repo         string     `json:"repo"`
+	Title        string     `json:"title"`
+	Body         string     `json:"body"`
+	State        string     `json:"state"`
+	Draft        bool       `json:"draft"`
+	Author       string     `json:"author"`
+	Labels       []string   `json:"labels" gorm:"serializer:json"`
+	Additions    int        `json:"additions"`
+	Deletions    int        `json:"deletions"`
+	ChangedFiles int        `json:"changed_files"`
+	Commits      int        `json:"commits"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+	ClosedAt     *time.Time `json:"closed_at"`
+	MergedAt     *time.Time `json:"merged_at"`
+}
+
+// fetchGitHubPullRequests returns the most recently updated pull requests of
+// the framework's repository. The list endpoint omits diff statistics, so
+// each pull request is fetched individually for additions and deletions.
+func fetchGitHubPullRequests(ctx context.Context, cfg *Config, framework Framework) ([]GitHubPullRequest, error) {
+	if framework.GitHubRepo == "" {
+		return nil, nil
+	}
+	var list []struct {
+		Number int `json:"number"`
+	}
+	u := fmt.Sprintf("%s/repos/%s/pulls?state=all&sort=updated&direction=desc&per_page=%d", githubAPIURL, framework.GitHubRepo, cfg.FetchLimit)
+	if err := githubGet(ctx, u, &list); err != nil {
+		return nil, err
+	}
+
+	pulls := make([]GitHubPullRequest, 0, len(list))
+	for _, item := range list {
+		var pr struct {
+			ID           int           `json:"id"`
+			Number       int           `json:"number"`
+			Title        string        `json:"title"`
+			Body         string        `json:"body"`
+			State        string        `json:"state"`
+			Draft        bool          `json:"draft"`
+			User         githubActor   `json:"user"`
+			Labels       []githubLabel `json:"labels"`
+			Additions    int           `json:"additions"`
+			Deletions    int           `json:"deletions"`
+			ChangedFiles int           `json:"changed_files"`
+			Commits      int           `json:"commits"`
+			CreatedAt    time.Time     `json:"created_at"`
+			UpdatedAt    time.Time     `json:"updated_at"`
+			ClosedAt     *time.Time    `json:"closed_at"`
+			MergedAt     *time.Time    `json:"merged_at"`
+		}
+		u := fmt.Sprintf("%s/repos/%s/pulls/%d", githubAPIURL, framework.GitHubRepo, item.Number)
+		if err := githubGet(ctx, u, &pr); err != nil {
+			return pulls, err
+		}
+
+		pulls = append(pulls, GitHubPullRequest{
+			ID:           pr.ID,
+			Number:       pr.Number,
+			Framework:    framework.Name,
+			Repo:         framework.GitHubRepo,
+			Title:        pr.Title,
+			Body:         pr.Body,
+			State:        pr.State,
+			Draft:        pr.Draft,
+			Author:       pr.User.Login,
+			Labels:       labelNames(pr.Labels),
+			Additions:    pr.Additions,
+			Deletions:    pr.Deletions,
+			ChangedFiles: pr.ChangedFiles,
+			Commits:      pr.Commits,
+			CreatedAt:    pr.CreatedAt,
+			UpdatedAt:    pr.UpdatedAt,
+			ClosedAt:     pr.ClosedAt,
+			MergedAt:     pr.MergedAt,
+		})
+	}
+	return pulls, nil
+}
+
+func init() {
+	registerSource(func(cfg *Config) Source { return gitHubPullRequestSource{cfg} })
+}
+
+// gitHubPullRequestSource collects a repository's pull requests.
+type gitHubPullRequestSource struct{ cfg *Config }
+
+func (gitHubPullRequestSource) Name() string { return "github_pulls" }
+
+func (s gitHubPullRequestSource) Fetch(ctx context.Context, framework Framework) ([]Item, error) {
+	pulls, err := fetchGitHubPullRequests(ctx, s.cfg, framework)
+	return asItems(pulls), err
+}
+
+func (pr GitHubPullRequest) Save(db *gorm.DB) error {
+	return upsert(db, &pr)
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type GitHubRelease struct {
+	ID          int        `json:"id" gorm:"primaryKey;autoIncrement:false"`
+	Framework   string     `json:"framework" gorm:"index"`
+	Repo        string     `json:"repo"`
+	TagName     string     `json:"tag_name"`
+	Name        string     `json:"name"`
+	Body        string     `json:"body"`
+	Draft       bool       `json:"draft"`
+	Prerelease  bool       `json:"prerelease"`
+	URL         string     `json:"url"`
+	PublishedAt *time.Time `json:"published_at" gorm:"index"`
+}
+
+// fetchGitHubReleases returns the newest releases of the framework's
+// repository.
+func fetchGitHubReleases(ctx context.Context, cfg *Config, framework Framework) ([]GitHubRelease, error) {
+	if framework.GitHubRepo == "" {
+		return nil, nil
+	}
+	var list []struct {
+		ID          int        `json:"id"`
+		TagName     string     `json:"tag_name"`
+		Name        string     `json:"name"`
+		Body        string     `json:"body"`
+		Draft       bool       `json:"draft"`
+		Prerelease  bool       `json:"prerelease"`
+		HTMLURL     string     `json:"html_url"`
+		PublishedAt *time.Time `json:"published_at"`
+	}
+	u := fmt.Sprintf("%s/repos/%s/releases?per_page=%d", githubAPIURL, framework.GitHubRepo, cfg.FetchLimit)
+	if err := githubGet(ctx, u, &list); err != nil {
+		return nil, err
+	}
+
+	releases := make([]GitHubRelease, 0, len(list))
+	for _, r := range list {
+		releases = append(releases, GitHubRelease{
+			ID:          r.ID,
+			Framework:   framework.Name,
+			Repo:        framework.GitHubRepo,
+			TagName:     r.TagName,
+			Name:        r.Name,
+			Body:        r.Body,
+			Draft:       r.Draft,
+			Prerelease:  r.Prerelease,
+			URL:         r.HTMLURL,
+			PublishedAt: r.PublishedAt,
+		})
+	}
+	return releases, nil
+}
+
+func init() {
+	registerSource(func(cfg *Config) Source { return gitHubReleaseSource{cfg} })
+}
+
+// gitHubReleaseSource collects a repository's releases.
+type gitHubReleaseSource struct{ cfg *Config }
+
+func (gitHubReleaseSource) Name() string { return "github_releases" }
+
+func (s gitHubReleaseSource) Fetch(ctx context.Context, framework Framework) ([]Item, error) {
+	releases, err := fetchGitHubReleases(ctx, s.cfg, framework)
+	return asItems(releases), err
+}
+
+func (release GitHubRelease) Save(db *gorm.DB) error {
+	return upsert(db, &release)
+}
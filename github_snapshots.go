@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// GitHubRepoSnapshot records a repository's popularity counters for one UTC
+// day. Re-running the job on the same day overwrites that day's row.
+type GitHubRepoSnapshot struct {
+	Repo       string    `json:"repo" gorm:"primaryKey"`
+	Date       time.Time `json:"date" gorm:"primaryKey;type:date"`
+	Framework  string    `json:"framework" gorm:"index"`
+	Stars      int       `json:"stars"`
+	Forks      int       `json:"forks"`
+	OpenIssues int       `json:"open_issues"`
+	Watchers   int       `json:"watchers"`
+	RecordedAt time.Time `json:"recorded_at"`
+}
+
+func fetchGitHubRepoSnapshot(ctx context.Context, framework Framework) (GitHubRepoSnapshot, error) {
+	var repo struct {
+		StargazersCount  int `json:"stargazers_count"`
+		ForksCount       int `json:"forks_count"`
+		OpenIssuesCount  int `json:"open_issues_count"`
+		SubscribersCount int `json:"subscribers_count"`
+	}
+	if err := githubGet(ctx, fmt.Sprintf("%s/repos/%s", githubAPIURL, framework.GitHubRepo), &repo); err != nil {
+		return GitHubRepoSnapshot{}, err
+	}
+
+	now := time.Now().UTC()
+	return GitHubRepoSnapshot{
+		Repo:       framework.GitHubRepo,
+		Date:       now.Truncate(24 * time.Hour),
+		Framework:  framework.Name,
+		Stars:      repo.StargazersCount,
+		Forks:      repo.ForksCount,
+		OpenIssues: repo.OpenIssuesCount,
+		Watchers:   repo.SubscribersCount,
+		RecordedAt: now,
+	}, nil
+}
+
+func (snapshot GitHubRepoSnapshot) Save(db *gorm.DB) error {
+	return upsert(db, &snapshot)
+}
+
+// recordGitHubSnapshots takes today's snapshot of every tracked repository.
+func recordGitHubSnapshots(ctx context.Context, db *gorm.DB, cfg *Config) {
+	frameworks, err := loadFrameworks(db)
+	if err != nil {
+		log.Printf("Error loading framework registry: %v", err)
+		return
+	}
+	githubTokens.SetTokens(cfg.GitHubTokens)
+	apiRetry.Configure(cfg.FetchMaxAttempts, cfg.FetchRetryDelay)
+
+	for _, framework := range frameworks {
+		if framework.GitHubRepo == "" {
+			continue
+		}
+		snapshot, err := fetchGitHubRepoSnapshot(ctx, framework)
+		if err != nil {
+			log.Printf("Error fetching GitHub repo snapshot for %s: %v", framework.Name, err)
+			continue
+		}
+		if err := snapshot.Save(db); err != nil {
+			log.Printf("Error storing GitHub repo snapshot for %s: %v", framework.Name, err)
+		}
+	}
+}
+
+// runDailySnapshots records the GitHub and Docker Hub snapshots at startup and then once a day until
+// ctx is cancelled.
+func runDailySnapshots(ctx context.Context, db *gorm.DB, store *configStore) {
+	ticker := time.NewTicker(24 * time.Hour)
+	defer ticker.Stop()
+
+	for {
+		cfg := store.Get()
+		if err := responseArchive.Configure(ctx, cfg); err != nil {
+			log.Printf("Error configuring response archive: %v", err)
+		}
+		if cfg.Flags.Enabled(flagSourceSnapshots) {
+			recordGitHubSnapshots(ctx, db, cfg)
+		}
+		if cfg.Flags.Enabled(flagSourceDockerHub) {
+			recordDockerHubSnapshots(ctx, db)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// githubStatsAttempts bounds how often a statistics endpoint is polled
+// while GitHub is still computing it.
+const githubStatsAttempts = 4
+
+// GitHubCommitActivity is one week of a repository's commit counts.
+type GitHubCommitActivity struct {
+	Repo      string    `json:"repo" gorm:"primaryKey"`
+	Week      time.Time `json:"week" gorm:"primaryKey"`
+	Framework string    `json:"framework" gorm:"index"`
+	Total     int       `json:"total"`
+	Days      []int     `json:"days" gorm:"serializer:json"`
+}
+
+// GitHubContributor summarises one of a repository's top contributors.
+type GitHubContributor struct {
+	Repo        string    `json:"repo" gorm:"primaryKey"`
+	Login       string    `json:"login" gorm:"primaryKey"`
+	Framework   string    `json:"framework" gorm:"index"`
+	Commits     int       `json:"commits"`
+	Additions   int       `json:"additions"`
+	Deletions   int       `json:"deletions"`
+	FirstWeek   time.Time `json:"first_week"`
+	LastWeek    time.Time `json:"last_week"`
+	ActiveWeeks int       `json:"active_weeks"`
+	CollectedAt time.Time `json:"collected_at"`
+}
+
+// githubGetStats fetches a /stats endpoint. GitHub answers 202 Accepted
+// with an empty object while it computes statistics in the background, so
+// anything other than a JSON array is treated as "not ready" and retried
+// with exponential backoff.
+func githubGetStats(ctx context.Context, url string, out interface{}) error {
+	wait := 2 * time.Second
+	for attempt := 1; ; attempt++ {
+		var raw json.RawMessage
+		if err := githubGet(ctx, url, &raw); err != nil {
+			return err
+		}
+		if bytes.HasPrefix(bytes.TrimSpace(raw), []byte("[")) {
+			return json.Unmarshal(raw, out)
+		}
+		if attempt == githubStatsAttempts {
+			return fmt.Errorf("%s: statistics still being computed after %d attempts", url, attempt)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+		wait *= 2
+	}
+}
+
+// fetchGitHubCommitActivity returns the last year of weekly commit counts.
+func fetchGitHubCommitActivity(ctx context.Context, framework Framework) ([]GitHubCommitActivity, error) {
+	if framework.GitHubRepo == "" {
+		return nil, nil
+	}
+	var weeks []struct {
+		Days  []int `json:"days"`
+		Total int   `json:"total"`
+		Week  int64 `json:"week"`
+	}
+	u := fmt.Sprintf("%s/repos/%s/stats/commit_activity", githubAPIURL, framework.GitHubRepo)
+	if err := githubGetStats(ctx, u, &weeks); err != nil {
+		return nil, err
+	}
+
+	activity := make([]GitHubCommitActivity, 0, len(weeks))
+	for _, w := range weeks {
+		activity = append(activity, GitHubCommitActivity{
+			Repo:      framework.GitHubRepo,
+			Week:      time.Unix(w.Week, 0).UTC(),
+			Framework: framework.Name,
+			Total:     w.Total,
+			Days:      w.Days,
+		})
+	}
+	return activity, nil
+}
+
+// fetchGitHubContributors returns totals for the repository's top
+// contributors, aggregated from GitHub's per-week breakdown.
+func fetchGitHubContributors(ctx context.Context, framework Framework) ([]GitHubContributor, error) {
+	if framework.GitHubRepo == "" {
+		return nil, nil
+	}
+	var stats []struct {
+		Author *githubActor `json:"author"`
+		Total  int          `json:"total"`
+		Weeks  []struct {
+			W int64 `json:"w"`
+			A int   `json:"a"`
+			D int   `json:"d"`
+			C int   `json:"c"`
+		} `json:"weeks"`
+	}
+	u := fmt.Sprintf("%s/repos/%s/stats/contributors", githubAPIURL, framework.GitHubRepo)
+	if err := githubGetStats(ctx, u, &stats); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	contributors := make([]GitHubContributor, 0, len(stats))
+	for _, s := range stats {
+		if s.Author == nil {
+			continue
+		}
+		c := GitHubContributor{
+			Repo:        framework.GitHubRepo,
+			Login:       s.Author.Login,
+			Framework:   framework.Name,
+			Commits:     s.Total,
+			CollectedAt: now,
+		}
+		for _, w := range s.Weeks {
+			c.Additions += w.A
+			c.Deletions += w.D
+			if w.C == 0 {
+				continue
+			}
+			week := time.Unix(w.W, 0).UTC()
+			if c.FirstWeek.IsZero() {
+				c.FirstWeek = week
+			}
+			c.LastWeek = week
+			c.ActiveWeeks++
+		}
+		contributors = append(contributors, c)
+	}
+	return contributors, nil
+}
+
+func init() {
+	registerSource(func(cfg *Config) Source { return gitHubCommitStatsSource{cfg} })
+}
+
+// gitHubCommitStatsSource collects weekly commit activity and contributor totals.
+type gitHubCommitStatsSource struct{ cfg *Config }
+
+func (gitHubCommitStatsSource) Name() string { return "github_commit_stats" }
+
+func (gitHubCommitStatsSource) Fetch(ctx context.Context, framework Framework) ([]Item, error) {
+	activity, err := fetchGitHubCommitActivity(ctx, framework)
+	items := asItems(activity)
+	if err != nil {
+		return items, fmt.Errorf("fetching commit activity: %w", err)
+	}
+
+	contributors, err := fetchGitHubContributors(ctx, framework)
+	items = append(items, asItems(contributors)...)
+	if err != nil {
+		return items, fmt.Errorf("fetching contributors: %w", err)
+	}
+	return items, nil
+}
+
+func (activity GitHubCommitActivity) Save(db *gorm.DB) error {
+	return upsert(db, &activity)
+}
+
+func (contributor GitHubContributor) Save(db *gorm.DB) error {
+	return upsert(db, &contributor)
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	githubTokenRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "myapp_github_token_requests_total",
+		Help: "Total number of GitHub API calls made with each pooled token",
+	}, []string{"token"})
+	githubTokenRemaining = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "myapp_github_token_rate_limit_remaining",
+		Help: "Remaining GitHub API requests in the current window for each pooled token",
+	}, []string{"token"})
+)
+
+// githubToken tracks the rate-limit state GitHub last reported for a
+// token. Label identifies the token in metrics without exposing it.
+type githubToken struct {
+	value     string
+	label     string
+	remaining int // -1 until GitHub has reported a value
+	reset     time.Time
+}
+
+// githubTokenPool hands out the configured GitHub tokens, preferring the one
+// with the most remaining requests so load spreads across all of them.
+type githubTokenPool struct {
+	mu     sync.Mutex
+	tokens []*githubToken
+}
+
+var githubTokens = &githubTokenPool{}
+
+// SetTokens replaces the pool contents, keeping the known rate-limit state
+// of tokens that are still configured.
+func (p *githubTokenPool) SetTokens(values []string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	existing := make(map[string]*githubToken, len(p.tokens))
+	for _, t := range p.tokens {
+		existing[t.value] = t
+	}
+
+	p.tokens = make([]*githubToken, 0, len(values))
+	for i, v := range values {
+		t, ok := existing[v]
+		if !ok {
+			t = &githubToken{value: v, remaining: -1}
+		}
+		t.label = "token_" + strconv.Itoa(i)
+		p.tokens = append(p.tokens, t)
+	}
+}
+
+// Next returns the token to use for the next request: the one with the most
+// remaining quota, treating tokens whose window has reset as unused. When
+// every token is exhausted it returns the one that resets soonest.
+func (p *githubTokenPool) Next() *githubToken {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	var best *githubToken
+	bestRemaining := 0
+	for _, t := range p.tokens {
+		remaining := t.remaining
+		if remaining < 0 || now.After(t.reset) {
+			remaining = math.MaxInt
+		}
+		if best == nil || remaining > bestRemaining ||
+			(remaining == 0 && bestRemaining == 0 && t.reset.Before(best.reset)) {
+			best, bestRemaining = t, remaining
+		}
+	}
+	if best != nil {
+		githubTokenRequests.WithLabelValues(best.label).Inc()
+	}
+	return best
+}
+
+// Update records the rate-limit headers from a response made with t.
+func (p *githubTokenPool) Update(t *githubToken, header http.Header) {
+	remaining, err := strconv.Atoi(header.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return
+	}
+	reset, _ := strconv.ParseInt(header.Get("X-RateLimit-Reset"), 10, 64)
+
+	p.mu.Lock()
+	t.remaining = remaining
+	t.reset = time.Unix(reset, 0)
+	p.mu.Unlock()
+
+	githubTokenRemaining.WithLabelValues(t.label).Set(float64(remaining))
+}
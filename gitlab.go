@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"gorm.io/gorm"
+)
+
+var gitlabAPICalls = promauto.NewCounter(prometheus.CounterOpts{
+	Name: "myapp_gitlab_api_calls_total",
+	Help: "Total number of API calls to GitLab",
+})
+
+// GitLabConfig points at a GitLab instance. Token is optional for public
+// projects but raises the rate limit and allows private ones.
+type GitLabConfig struct {
+	URL   string
+	Token string
+}
+
+type GitLabIssue struct {
+	ID        int       `json:"id" gorm:"primaryKey;autoIncrement:false"`
+	IID       int       `json:"iid"`
+	Framework string    `json:"framework" gorm:"index"`
+	Project   string    `json:"project"`
+	Title     string    `json:"title"`
+	Body      string    `json:"body"`
+	State     string    `json:"state"`
+	Author    string    `json:"author"`
+	Labels    []string  `json:"labels" gorm:"serializer:json"`
+	WebURL    string    `json:"web_url"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// fetchGitLabIssues returns the most recently updated issues of the
+// framework's GitLab project.
+func fetchGitLabIssues(ctx context.Context, cfg *Config, framework Framework) ([]GitLabIssue, error) {
+	if framework.GitLabProject == "" {
+		return nil, nil
+	}
+	gitlabAPICalls.Inc()
+
+	var issues []struct {
+		ID          int       `json:"id"`
+		IID         int       `json:"iid"`
+		Title       string    `json:"title"`
+		Description string    `json:"description"`
+		State       string    `json:"state"`
+		Labels      []string  `json:"labels"`
+		WebURL      string    `json:"web_url"`
+		CreatedAt   time.Time `json:"created_at"`
+		UpdatedAt   time.Time `json:"updated_at"`
+		Author      struct {
+			Username string `json:"username"`
+		} `json:"author"`
+	}
+
+	u := fmt.Sprintf("%s/api/v4/projects/%s/issues?order_by=updated_at&per_page=%d",
+		strings.TrimSuffix(cfg.GitLab.URL, "/"), url.PathEscape(framework.GitLabProject), cfg.FetchLimit)
+	header := http.Header{}
+	if cfg.GitLab.Token != "" {
+		header.Set("PRIVATE-TOKEN", cfg.GitLab.Token)
+	}
+	if err := getJSON(ctx, u, header, &issues); err != nil {
+		return nil, err
+	}
+
+	result := make([]GitLabIssue, 0, len(issues))
+	for _, issue := range issues {
+		result = append(result, GitLabIssue{
+			ID:        issue.ID,
+			IID:       issue.IID,
+			Framework: framework.Name,
+			Project:   framework.GitLabProject,
+			Title:     issue.Title,
+			Body:      issue.Description,
+			State:     issue.State,
+			Author:    issue.Author.Username,
+			Labels:    issue.Labels,
+			WebURL:    issue.WebURL,
+			CreatedAt: issue.CreatedAt,
+			UpdatedAt: issue.UpdatedAt,
+		})
+	}
+	return result, nil
+}
+
+func init() {
+	registerSource(func(cfg *Config) Source { return gitLabSource{cfg} })
+}
+
+// gitLabSource collects issues from a framework's GitLab project.
+type gitLabSource struct{ cfg *Config }
+
+func (gitLabSource) Name() string { return "gitlab" }
+
+func (s gitLabSource) Fetch(ctx context.Context, framework Framework) ([]Item, error) {
+	issues, err := fetchGitLabIssues(ctx, s.cfg, framework)
+	return asItems(issues), err
+}
+
+func (issue GitLabIssue) Save(db *gorm.DB) error {
+	return upsert(db, &issue)
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+	"gorm.io/gorm"
+)
+
+var goProxyAPICalls = promauto.NewCounter(prometheus.CounterOpts{
+	Name: "myapp_goproxy_api_calls_total",
+	Help: "Total number of API calls to the Go module proxy",
+})
+
+const goProxyURL = "https://proxy.golang.org"
+
+// GoModuleVersion is a version of a framework's Go module and the time the
+// proxy first saw it. The proxy does not publish download counts, so
+// publish events are the only adoption signal it offers.
+type GoModuleVersion struct {
+	Module      string    `json:"module" gorm:"primaryKey"`
+	Version     string    `json:"version" gorm:"primaryKey"`
+	Framework   string    `json:"framework" gorm:"index"`
+	PublishedAt time.Time `json:"published_at"`
+}
+
+func init() {
+	registerSource(func(cfg *Config) Source { return goProxySource{cfg} })
+}
+
+// goProxySource collects version publish events of a framework's Go module.
+type goProxySource struct{ cfg *Config }
+
+func (goProxySource) Name() string { return "goproxy" }
+
+func (s goProxySource) Fetch(ctx context.Context, framework Framework) ([]Item, error) {
+	versions, err := fetchGoModuleVersions(ctx, s.cfg, framework)
+	return asItems(versions), err
+}
+
+// fetchGoModuleVersions returns the newest cfg.FetchLimit versions of the
+// framework's Go module with their publish times.
+func fetchGoModuleVersions(ctx context.Context, cfg *Config, framework Framework) ([]GoModuleVersion, error) {
+	if framework.GoModule == "" {
+		return nil, nil
+	}
+	escaped, err := module.EscapePath(framework.GoModule)
+	if err != nil {
+		return nil, err
+	}
+
+	goProxyAPICalls.Inc()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/%s/@v/list", goProxyURL, escaped), nil)
+	if err != nil {
+		return nil, err
+	}
+	_, body, err := doRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var list []string
+	for _, v := range strings.Fields(string(body)) {
+		if semver.IsValid(v) {
+			list = append(list, v)
+		}
+	}
+	semver.Sort(list)
+	if len(list) > cfg.FetchLimit {
+		list = list[len(list)-cfg.FetchLimit:]
+	}
+
+	versions := make([]GoModuleVersion, 0, len(list))
+	for i := len(list) - 1; i >= 0; i-- {
+		goProxyAPICalls.Inc()
+		var info struct {
+			Version string    `json:"Version"`
+			Time    time.Time `json:"Time"`
+		}
+		if err := getJSON(ctx, fmt.Sprintf("%s/%s/@v/%s.info", goProxyURL, escaped, list[i]), nil, &info); err != nil {
+			return versions, err
+		}
+		versions = append(versions, GoModuleVersion{
+			Module:      framework.GoModule,
+			Version:     info.Version,
+			Framework:   framework.Name,
+			PublishedAt: info.Time,
+		})
+	}
+	return versions, nil
+}
+
+func (version GoModuleVersion) Save(db *gorm.DB) error {
+	return upsert(db, &version)
+}
@@ -0,0 +1,59 @@
+// Package graph is the GraphQL executor for schema.graphqls, generated by
+// gqlgen, and the models it resolves. The resolvers are in package main,
+// next to the records they read.
+package graph
+
+import "time"
+
+// The models carry the keys their nested resolvers look up by, such as
+// the framework name of a post, next to the fields of the schema.
+
+type Framework struct {
+	ID                 int
+	Name               string
+	StackoverflowTag   string
+	GithubRepo         string
+	StackexchangeSites []string
+}
+
+type Post struct {
+	QuestionID   int
+	Site         string
+	Title        string
+	Body         string
+	Tags         []string
+	CreationDate time.Time
+	CollectedAt  time.Time
+
+	FrameworkName string
+	// Answers are the answers loaded together with the post, if any.
+	Answers []Answer
+}
+
+type Answer struct {
+	AnswerID     int
+	Body         string
+	Score        int
+	IsAccepted   bool
+	Owner        string
+	CreationDate time.Time
+}
+
+type Issue struct {
+	ID        int
+	Number    int
+	Title     string
+	Body      string
+	Labels    []string
+	Comments  int
+	Reactions int
+	State     IssueState
+	Author    string
+	Assignees []string
+	Repo      string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	ClosedAt  *time.Time
+
+	FrameworkName string
+}
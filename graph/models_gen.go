@@ -0,0 +1,63 @@
+// Code generated by github.com/99designs/gqlgen, DO NOT EDIT.
+
+package graph
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+)
+
+type IssuePage struct {
+	Total  int     `json:"total"`
+	Issues []Issue `json:"issues"`
+}
+
+type PostPage struct {
+	Total int    `json:"total"`
+	Posts []Post `json:"posts"`
+}
+
+type Query struct {
+}
+
+type IssueState string
+
+const (
+	IssueStateOpen   IssueState = "open"
+	IssueStateClosed IssueState = "closed"
+)
+
+var AllIssueState = []IssueState{
+	IssueStateOpen,
+	IssueStateClosed,
+}
+
+func (e IssueState) IsValid() bool {
+	switch e {
+	case IssueStateOpen, IssueStateClosed:
+		return true
+	}
+	return false
+}
+
+func (e IssueState) String() string {
+	return string(e)
+}
+
+func (e *IssueState) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = IssueState(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid IssueState", str)
+	}
+	return nil
+}
+
+func (e IssueState) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
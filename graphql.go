@@ -0,0 +1,26 @@
+package main
+
+//go:generate go run github.com/99designs/gqlgen generate
+
+import (
+	"my-assignment/graph"
+
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/playground"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"gorm.io/gorm"
+)
+
+// graphqlHandler serves /graphql, the GraphQL API over the framework
+// registry and the stored posts, answers and issues. Queries are accepted
+// by GET and POST.
+func graphqlHandler(db *gorm.DB, records Store) fiber.Handler {
+	schema := graph.NewExecutableSchema(graph.Config{Resolvers: &graphqlResolver{db: db, records: records}})
+	return adaptor.HTTPHandler(handler.NewDefaultServer(schema))
+}
+
+// graphqlPlaygroundHandler serves a GraphQL playground for /graphql.
+func graphqlPlaygroundHandler() fiber.Handler {
+	return adaptor.HTTPHandlerFunc(playground.Handler("GraphQL", "/graphql"))
+}
@@ -0,0 +1,261 @@
+package main
+
+// Generated as a starting point by gqlgen, which does not update this file
+// on schema changes; add resolvers for new fields by hand.
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"my-assignment/graph"
+
+	"gorm.io/gorm"
+)
+
+// graphqlResolver resolves the GraphQL schema in schema.graphqls. Records
+// are read through the Store like the REST endpoints, and the framework
+// registry and answers from db.
+type graphqlResolver struct {
+	db      *gorm.DB
+	records Store
+}
+
+// Posts is the resolver for the posts field.
+func (r *frameworkResolver) Posts(ctx context.Context, obj *graph.Framework, tag *string, site *string, limit *int, offset *int) (*graph.PostPage, error) {
+	return r.listPosts(ctx, &obj.Name, tag, site, limit, offset)
+}
+
+// Issues is the resolver for the issues field. A framework without a
+// GitHub repository has none.
+func (r *frameworkResolver) Issues(ctx context.Context, obj *graph.Framework, state *graph.IssueState, label *string, limit *int, offset *int) (*graph.IssuePage, error) {
+	if obj.GithubRepo == "" {
+		return &graph.IssuePage{Issues: []graph.Issue{}}, nil
+	}
+	return r.listIssues(ctx, &obj.GithubRepo, state, label, limit, offset)
+}
+
+// Framework is the resolver for the framework field.
+func (r *issueResolver) Framework(ctx context.Context, obj *graph.Issue) (*graph.Framework, error) {
+	return r.lookupFramework(ctx, obj.FrameworkName)
+}
+
+// Framework is the resolver for the framework field.
+func (r *postResolver) Framework(ctx context.Context, obj *graph.Post) (*graph.Framework, error) {
+	return r.lookupFramework(ctx, obj.FrameworkName)
+}
+
+// Answers is the resolver for the answers field, highest scored first.
+// They are loaded with the post by the SQL Store and looked up otherwise.
+func (r *postResolver) Answers(ctx context.Context, obj *graph.Post) ([]graph.Answer, error) {
+	if obj.Answers != nil {
+		return obj.Answers, nil
+	}
+	var answers []StackOverflowAnswer
+	err := r.db.WithContext(ctx).Where("question_id = ? AND site = ?", obj.QuestionID, obj.Site).
+		Order("score DESC").Find(&answers).Error
+	if err != nil {
+		return nil, err
+	}
+	return graphAnswers(answers), nil
+}
+
+// Frameworks is the resolver for the frameworks field.
+func (r *queryResolver) Frameworks(ctx context.Context) ([]graph.Framework, error) {
+	frameworks, err := loadFrameworks(r.db.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	result := make([]graph.Framework, len(frameworks))
+	for i, framework := range frameworks {
+		result[i] = graphFramework(framework)
+	}
+	return result, nil
+}
+
+// Framework is the resolver for the framework field.
+func (r *queryResolver) Framework(ctx context.Context, name string) (*graph.Framework, error) {
+	return r.lookupFramework(ctx, name)
+}
+
+// Posts is the resolver for the posts field.
+func (r *queryResolver) Posts(ctx context.Context, framework *string, tag *string, site *string, limit *int, offset *int) (*graph.PostPage, error) {
+	return r.listPosts(ctx, framework, tag, site, limit, offset)
+}
+
+// Issues is the resolver for the issues field.
+func (r *queryResolver) Issues(ctx context.Context, repo *string, state *graph.IssueState, label *string, limit *int, offset *int) (*graph.IssuePage, error) {
+	return r.listIssues(ctx, repo, state, label, limit, offset)
+}
+
+// Framework returns graph.FrameworkResolver implementation.
+func (r *graphqlResolver) Framework() graph.FrameworkResolver { return &frameworkResolver{r} }
+
+// Issue returns graph.IssueResolver implementation.
+func (r *graphqlResolver) Issue() graph.IssueResolver { return &issueResolver{r} }
+
+// Post returns graph.PostResolver implementation.
+func (r *graphqlResolver) Post() graph.PostResolver { return &postResolver{r} }
+
+// Query returns graph.QueryResolver implementation.
+func (r *graphqlResolver) Query() graph.QueryResolver { return &queryResolver{r} }
+
+type frameworkResolver struct{ *graphqlResolver }
+type issueResolver struct{ *graphqlResolver }
+type postResolver struct{ *graphqlResolver }
+type queryResolver struct{ *graphqlResolver }
+
+// lookupFramework returns the registered framework called name, or nil.
+func (r *graphqlResolver) lookupFramework(ctx context.Context, name string) (*graph.Framework, error) {
+	if name == "" {
+		return nil, nil
+	}
+	var framework Framework
+	err := r.db.WithContext(ctx).Where("LOWER(name) = ?", strings.ToLower(name)).First(&framework).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	f := graphFramework(framework)
+	return &f, nil
+}
+
+func (r *graphqlResolver) listPosts(ctx context.Context, framework, tag, site *string, limit, offset *int) (*graph.PostPage, error) {
+	n, skip, err := graphPage(limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	posts, total, err := r.records.ListPosts(ctx, PostFilter{
+		Framework: deref(framework),
+		Tag:       deref(tag),
+		Site:      deref(site),
+		Limit:     n,
+		Offset:    skip,
+	})
+	if err != nil {
+		return nil, err
+	}
+	page := &graph.PostPage{Total: int(total), Posts: make([]graph.Post, len(posts))}
+	for i, post := range posts {
+		page.Posts[i] = graphPost(post)
+	}
+	return page, nil
+}
+
+func (r *graphqlResolver) listIssues(ctx context.Context, repo *string, state *graph.IssueState, label *string, limit, offset *int) (*graph.IssuePage, error) {
+	n, skip, err := graphPage(limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	filter := IssueFilter{Repo: deref(repo), Label: deref(label), Limit: n, Offset: skip}
+	if state != nil {
+		filter.State = string(*state)
+	}
+	issues, total, err := r.records.ListIssues(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	page := &graph.IssuePage{Total: int(total), Issues: make([]graph.Issue, len(issues))}
+	for i, issue := range issues {
+		page.Issues[i] = graphIssue(issue)
+	}
+	return page, nil
+}
+
+// graphPage checks the limit and offset arguments of a paginated field,
+// which have the bounds of the REST listings.
+func graphPage(limit, offset *int) (int, int, error) {
+	n, skip := 50, 0
+	if limit != nil {
+		n = *limit
+	}
+	if offset != nil {
+		skip = *offset
+	}
+	if n < 1 || n > 500 {
+		return 0, 0, fmt.Errorf("limit must be between 1 and 500")
+	}
+	if skip < 0 {
+		return 0, 0, fmt.Errorf("offset must not be negative")
+	}
+	return n, skip, nil
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func graphFramework(f Framework) graph.Framework {
+	return graph.Framework{
+		ID:                 int(f.ID),
+		Name:               f.Name,
+		StackoverflowTag:   f.StackOverflowTag,
+		GithubRepo:         f.GitHubRepo,
+		StackexchangeSites: f.stackExchangeSites(),
+	}
+}
+
+func graphPost(post StackOverflowPost) graph.Post {
+	p := graph.Post{
+		QuestionID:    post.QuestionID,
+		Site:          post.Site,
+		Title:         post.Title,
+		Body:          post.Body,
+		Tags:          post.tagNames(),
+		CreationDate:  post.CreationDate,
+		CollectedAt:   post.CreatedAt,
+		FrameworkName: post.Framework,
+	}
+	if post.Answers != nil {
+		p.Answers = graphAnswers(post.Answers)
+	}
+	return p
+}
+
+func graphAnswers(answers []StackOverflowAnswer) []graph.Answer {
+	result := make([]graph.Answer, len(answers))
+	for i, a := range answers {
+		result[i] = graph.Answer{
+			AnswerID:     a.AnswerID,
+			Body:         a.Body,
+			Score:        a.Score,
+			IsAccepted:   a.IsAccepted,
+			Owner:        a.Owner,
+			CreationDate: a.CreationDate,
+		}
+	}
+	return result
+}
+
+func graphIssue(issue GitHubIssue) graph.Issue {
+	i := graph.Issue{
+		ID:            issue.ID,
+		Number:        issue.Number,
+		Title:         issue.Title,
+		Body:          issue.Body,
+		Labels:        issue.Labels,
+		Comments:      issue.Comments,
+		Reactions:     issue.Reactions,
+		State:         graph.IssueState(issue.State),
+		Author:        issue.Author,
+		Assignees:     issue.Assignees,
+		Repo:          issue.Repo,
+		CreatedAt:     issue.IssueCreatedAt,
+		UpdatedAt:     issue.IssueUpdatedAt,
+		ClosedAt:      issue.ClosedAt,
+		FrameworkName: issue.Framework,
+	}
+	if i.Labels == nil {
+		i.Labels = []string{}
+	}
+	if i.Assignees == nil {
+		i.Assignees = []string{}
+	}
+	return i
+}
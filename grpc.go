@@ -0,0 +1,265 @@
+package main
+
+//go:generate buf generate
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net"
+	"time"
+
+	"my-assignment/fetcherpb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"gorm.io/gorm"
+)
+
+// jobStreamInterval is how often TriggerFetch checks its job for progress.
+const jobStreamInterval = time.Second
+
+// grpcServer implements the Fetcher service of proto/fetcher/v1 over the
+// same Store as the HTTP API.
+type grpcServer struct {
+	fetcherpb.UnimplementedFetcherServer
+	db      *gorm.DB
+	records Store
+	store   *configStore
+}
+
+// serveGRPC serves the Fetcher service on GRPC_PORT in the background,
+// with the credentials and fetch rate limit of the HTTP API; see
+// grpcAuth. Reflection is enabled so that tools like grpcurl can discover
+// it.
+func serveGRPC(db *gorm.DB, records Store, store *configStore) error {
+	lis, err := net.Listen("tcp", ":"+store.Get().GRPCPort)
+	if err != nil {
+		return err
+	}
+	auth := newGRPCAuth(db, store)
+	srv := grpc.NewServer(grpc.UnaryInterceptor(auth.Unary), grpc.StreamInterceptor(auth.Stream))
+	fetcherpb.RegisterFetcherServer(srv, &grpcServer{db: db, records: records, store: store})
+	reflection.Register(srv)
+	go func() {
+		log.Fatal(srv.Serve(lis))
+	}()
+	return nil
+}
+
+// grpcPageSize applies the bounds of the HTTP listings to a page_size.
+func grpcPageSize(n int32) (int, error) {
+	switch {
+	case n == 0:
+		return 50, nil
+	case n < 0 || n > 500:
+		return 0, status.Error(codes.InvalidArgument, "page_size must be between 1 and 500")
+	}
+	return int(n), nil
+}
+
+func (s *grpcServer) ListPosts(ctx context.Context, req *fetcherpb.ListPostsRequest) (*fetcherpb.ListPostsResponse, error) {
+	limit, err := grpcPageSize(req.PageSize)
+	if err != nil {
+		return nil, err
+	}
+	if req.Offset < 0 {
+		return nil, status.Error(codes.InvalidArgument, "offset must not be negative")
+	}
+	posts, total, err := s.records.ListPosts(ctx, PostFilter{
+		Framework: req.Framework,
+		Tag:       req.Tag,
+		Site:      req.Site,
+		Limit:     limit,
+		Offset:    int(req.Offset),
+	})
+	if err != nil {
+		return nil, err
+	}
+	resp := &fetcherpb.ListPostsResponse{Total: total, Posts: make([]*fetcherpb.Post, len(posts))}
+	for i, post := range posts {
+		resp.Posts[i] = pbPost(post)
+	}
+	return resp, nil
+}
+
+func (s *grpcServer) ListIssues(ctx context.Context, req *fetcherpb.ListIssuesRequest) (*fetcherpb.ListIssuesResponse, error) {
+	limit, err := grpcPageSize(req.PageSize)
+	if err != nil {
+		return nil, err
+	}
+	if req.Offset < 0 {
+		return nil, status.Error(codes.InvalidArgument, "offset must not be negative")
+	}
+	if req.State != "" && req.State != "open" && req.State != "closed" {
+		return nil, status.Error(codes.InvalidArgument, "state must be open or closed")
+	}
+	issues, total, err := s.records.ListIssues(ctx, IssueFilter{
+		Repo:   req.Repo,
+		State:  req.State,
+		Label:  req.Label,
+		Limit:  limit,
+		Offset: int(req.Offset),
+	})
+	if err != nil {
+		return nil, err
+	}
+	resp := &fetcherpb.ListIssuesResponse{Total: total, Issues: make([]*fetcherpb.Issue, len(issues))}
+	for i, issue := range issues {
+		resp.Issues[i] = pbIssue(issue)
+	}
+	return resp, nil
+}
+
+// grpcRequestID returns the x-request-id metadata of a call, if it looks
+// like a request ID, or a new one.
+func grpcRequestID(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if ids := md.Get(requestIDHeader); len(ids) > 0 && requestIDPattern.MatchString(ids[0]) {
+		return ids[0]
+	}
+	return newRequestID()
+}
+
+// TriggerFetch queues a collection pass like /api/v1/fetch-data and
+// streams the job each time it changes. The pass keeps running if the
+// client goes away.
+func (s *grpcServer) TriggerFetch(_ *fetcherpb.TriggerFetchRequest, stream fetcherpb.Fetcher_TriggerFetchServer) error {
+	job, err := fetchWorkers.Enqueue(grpcRequestID(stream.Context()), s.store.Get(), fetchScope{})
+	if errors.Is(err, errFetchQueueFull) {
+		return status.Error(codes.ResourceExhausted, "the fetch queue is full; try again later")
+	}
+	if err != nil {
+		return status.Error(codes.Unavailable, err.Error())
+	}
+
+	ticker := time.NewTicker(jobStreamInterval)
+	defer ticker.Stop()
+	var last *fetcherpb.Job
+	for {
+		current := pbJob(job.snapshot())
+		if !proto.Equal(current, last) {
+			if err := stream.Send(current); err != nil {
+				return err
+			}
+			last = current
+		}
+		if current.Status != jobQueued && current.Status != jobRunning {
+			return nil
+		}
+		select {
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *grpcServer) GetStats(ctx context.Context, _ *fetcherpb.GetStatsRequest) (*fetcherpb.GetStatsResponse, error) {
+	stats, totals, err := listFrameworkStats(ctx, s.records, TimeRange{})
+	if err != nil {
+		return nil, err
+	}
+	resp := &fetcherpb.GetStatsResponse{Frameworks: make([]*fetcherpb.FrameworkStats, len(stats)), Totals: pbStats(totals)}
+	for i, st := range stats {
+		resp.Frameworks[i] = pbStats(st)
+	}
+	return resp, nil
+}
+
+// pbTime converts t, leaving zero times unset.
+func pbTime(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+func pbPost(post StackOverflowPost) *fetcherpb.Post {
+	p := &fetcherpb.Post{
+		QuestionId:   int64(post.QuestionID),
+		Site:         post.Site,
+		Framework:    post.Framework,
+		Title:        post.Title,
+		Body:         post.Body,
+		Tags:         post.tagNames(),
+		CreationDate: pbTime(post.CreationDate),
+		CollectedAt:  pbTime(post.CreatedAt),
+	}
+	for _, a := range post.Answers {
+		p.Answers = append(p.Answers, &fetcherpb.Answer{
+			AnswerId:     int64(a.AnswerID),
+			Body:         a.Body,
+			Score:        int32(a.Score),
+			IsAccepted:   a.IsAccepted,
+			Owner:        a.Owner,
+			CreationDate: pbTime(a.CreationDate),
+		})
+	}
+	return p
+}
+
+func pbIssue(issue GitHubIssue) *fetcherpb.Issue {
+	i := &fetcherpb.Issue{
+		Id:        int64(issue.ID),
+		Number:    int32(issue.Number),
+		Title:     issue.Title,
+		Body:      issue.Body,
+		Labels:    issue.Labels,
+		Comments:  int32(issue.Comments),
+		Reactions: int32(issue.Reactions),
+		State:     issue.State,
+		Author:    issue.Author,
+		Assignees: issue.Assignees,
+		Framework: issue.Framework,
+		Repo:      issue.Repo,
+		CreatedAt: pbTime(issue.IssueCreatedAt),
+		UpdatedAt: pbTime(issue.IssueUpdatedAt),
+	}
+	if issue.ClosedAt != nil {
+		i.ClosedAt = timestamppb.New(*issue.ClosedAt)
+	}
+	return i
+}
+
+func pbJob(job *fetchJob) *fetcherpb.Job {
+	j := &fetcherpb.Job{
+		Id:        job.ID,
+		Status:    job.Status,
+		Sources:   make(map[string]*fetcherpb.JobSource, len(job.Sources)),
+		Error:     job.Error,
+	}
+	if job.StartedAt != nil {
+		j.StartedAt = timestamppb.New(*job.StartedAt)
+	}
+	if job.FinishedAt != nil {
+		j.FinishedAt = timestamppb.New(*job.FinishedAt)
+	}
+	for name, src := range job.Sources {
+		counts := make(map[string]int64, len(src.Counts))
+		for kind, n := range src.Counts {
+			counts[kind] = int64(n)
+		}
+		j.Sources[name] = &fetcherpb.JobSource{Items: int64(src.Items), Counts: counts, Errors: src.Errors}
+	}
+	return j
+}
+
+func pbStats(st FrameworkStats) *fetcherpb.FrameworkStats {
+	s := &fetcherpb.FrameworkStats{
+		Framework:      st.Framework,
+		Posts:          st.Posts,
+		Issues:         st.Issues,
+		Answers:        st.Answers,
+		ItemsCollected: st.ItemsCollected,
+	}
+	if st.LastFetchAt != nil {
+		s.LastFetchAt = timestamppb.New(*st.LastFetchAt)
+	}
+	return s
+}
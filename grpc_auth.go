@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"my-assignment/fetcherpb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+)
+
+// grpcMethodRoles are the roles required by the Fetcher methods that need
+// more than the reader role REQUIRE_API_KEY asks for.
+var grpcMethodRoles = map[string]role{
+	fetcherpb.Fetcher_TriggerFetch_FullMethodName: roleOperator,
+}
+
+// grpcPublicPrefix marks the reflection service, which, like the API
+// documentation over HTTP, needs no credentials.
+const grpcPublicPrefix = "/grpc.reflection."
+
+// grpcAuth authenticates gRPC calls like jwtAuth and apiKeyAuth do HTTP
+// requests, from the x-api-key and authorization metadata, and holds
+// them to the same roles: reader for every method when REQUIRE_API_KEY is
+// set, and those of grpcMethodRoles always. TriggerFetch is also subject
+// to FETCH_RATE_LIMIT, per client address.
+type grpcAuth struct {
+	db         *gorm.DB
+	store      *configStore
+	fetchLimit *slidingLimiter
+}
+
+func newGRPCAuth(db *gorm.DB, store *configStore) *grpcAuth {
+	limits := store.Get().RateLimit
+	return &grpcAuth{db: db, store: store, fetchLimit: newSlidingLimiter(limits.FetchRequests, limits.FetchWindow)}
+}
+
+func (a *grpcAuth) Unary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if err := a.check(ctx, info.FullMethod); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (a *grpcAuth) Stream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := a.check(ss.Context(), info.FullMethod); err != nil {
+		return err
+	}
+	return handler(srv, ss)
+}
+
+// check admits a call to method, or returns the status rejecting it.
+func (a *grpcAuth) check(ctx context.Context, method string) error {
+	if strings.HasPrefix(method, grpcPublicPrefix) {
+		return nil
+	}
+	if method == fetcherpb.Fetcher_TriggerFetch_FullMethodName && !a.fetchLimit.Allow(grpcClientAddr(ctx)) {
+		return status.Error(codes.ResourceExhausted,
+			"rate limit of "+strconv.Itoa(a.fetchLimit.max)+" requests per "+a.fetchLimit.window.String()+" exceeded")
+	}
+
+	min, ok := grpcMethodRoles[method]
+	if !ok {
+		if !a.store.Get().RequireAPIKey {
+			return nil
+		}
+		min = roleReader
+	}
+	p, err := a.authenticate(ctx)
+	if err != nil {
+		return err
+	}
+	if p == nil {
+		return status.Error(codes.Unauthenticated, "missing x-api-key or authorization metadata")
+	}
+	if p.Role < min {
+		return status.Error(codes.PermissionDenied, "this method requires the "+min.String()+" role")
+	}
+	return nil
+}
+
+// authenticate returns the client of a call, by its bearer JWT or else
+// its API key, or nil when it sent neither.
+func (a *grpcAuth) authenticate(ctx context.Context) (*principal, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	first := func(key string) string {
+		if values := md.Get(key); len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	}
+
+	cfg := a.store.Get().JWT
+	if token, ok := strings.CutPrefix(first("authorization"), "Bearer "); ok && cfg.Enabled() && strings.Count(token, ".") == 2 {
+		claims, err := parseJWT(cfg, token)
+		if err != nil {
+			return nil, status.Error(codes.Unauthenticated, "invalid token: "+err.Error())
+		}
+		return &principal{Subject: claims.Subject, Role: claims.role()}, nil
+	}
+	key := first(strings.ToLower(apiKeyHeader))
+	if key == "" {
+		return nil, nil
+	}
+	p, err := apiKeyPrincipal(ctx, a.db, key)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	if p == nil {
+		return nil, status.Error(codes.Unauthenticated, "invalid API key")
+	}
+	return p, nil
+}
+
+// grpcClientAddr returns the IP address of the client of a call.
+func grpcClientAddr(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
+
+// slidingLimiter allows each key max events in any window, or every event
+// when max is zero. It is the gRPC counterpart of rateLimit.
+type slidingLimiter struct {
+	mu     sync.Mutex
+	max    int
+	window time.Duration
+	events map[string][]time.Time
+}
+
+func newSlidingLimiter(max int, window time.Duration) *slidingLimiter {
+	return &slidingLimiter{max: max, window: window, events: map[string][]time.Time{}}
+}
+
+// Allow records an event for key and reports whether it is within the
+// limit.
+func (l *slidingLimiter) Allow(key string) bool {
+	if l.max <= 0 {
+		return true
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	recent := l.events[key][:0]
+	for _, t := range l.events[key] {
+		if now.Sub(t) < l.window {
+			recent = append(recent, t)
+		}
+	}
+	if len(recent) >= l.max {
+		l.events[key] = recent
+		return false
+	}
+	l.events[key] = append(recent, now)
+	return true
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"gorm.io/gorm"
+)
+
+var hackerNewsAPICalls = promauto.NewCounter(prometheus.CounterOpts{
+	Name: "myapp_hackernews_api_calls_total",
+	Help: "Total number of API calls to the Hacker News Algolia search API",
+})
+
+// HackerNewsItem is a story or comment mentioning a framework.
+type HackerNewsItem struct {
+	ID          string    `json:"id" gorm:"primaryKey"`
+	Framework   string    `json:"framework" gorm:"index"`
+	Type        string    `json:"type"`
+	Title       string    `json:"title"`
+	Text        string    `json:"text"`
+	URL         string    `json:"url"`
+	Author      string    `json:"author"`
+	Points      int       `json:"points"`
+	NumComments int       `json:"num_comments"`
+	StoryID     int       `json:"story_id"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// hackerNewsQuery is the search term used for a framework. Short or
+// ambiguous names (e.g. "Go") can be overridden in the registry.
+func hackerNewsQuery(framework Framework) string {
+	if framework.HackerNewsQuery != "" {
+		return framework.HackerNewsQuery
+	}
+	return framework.Name
+}
+
+// fetchHackerNewsItems returns the most recent stories and comments
+// matching the framework's search term.
+func fetchHackerNewsItems(ctx context.Context, cfg *Config, framework Framework) ([]HackerNewsItem, error) {
+	hackerNewsAPICalls.Inc()
+
+	var result struct {
+		Hits []struct {
+			ObjectID    string   `json:"objectID"`
+			Title       string   `json:"title"`
+			StoryTitle  string   `json:"story_title"`
+			StoryText   string   `json:"story_text"`
+			CommentText string   `json:"comment_text"`
+			URL         string   `json:"url"`
+			Author      string   `json:"author"`
+			Points      int      `json:"points"`
+			NumComments int      `json:"num_comments"`
+			StoryID     int      `json:"story_id"`
+			CreatedAtI  int64    `json:"created_at_i"`
+			Tags        []string `json:"_tags"`
+		} `json:"hits"`
+	}
+
+	u := fmt.Sprintf("https://hn.algolia.com/api/v1/search_by_date?query=%s&tags=(story,comment)&hitsPerPage=%d",
+		url.QueryEscape(hackerNewsQuery(framework)), cfg.FetchLimit)
+	if err := getJSON(ctx, u, nil, &result); err != nil {
+		return nil, err
+	}
+
+	items := make([]HackerNewsItem, 0, len(result.Hits))
+	for _, hit := range result.Hits {
+		item := HackerNewsItem{
+			ID:          hit.ObjectID,
+			Framework:   framework.Name,
+			Type:        "story",
+			Title:       hit.Title,
+			Text:        hit.StoryText,
+			URL:         hit.URL,
+			Author:      hit.Author,
+			Points:      hit.Points,
+			NumComments: hit.NumComments,
+			StoryID:     hit.StoryID,
+			CreatedAt:   time.Unix(hit.CreatedAtI, 0).UTC(),
+		}
+		for _, tag := range hit.Tags {
+			if tag == "comment" {
+				item.Type = "comment"
+				item.Title = hit.StoryTitle
+				item.Text = hit.CommentText
+			}
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func init() {
+	registerSource(func(cfg *Config) Source { return hackerNewsSource{cfg} })
+}
+
+// hackerNewsSource collects Hacker News stories and comments.
+type hackerNewsSource struct{ cfg *Config }
+
+func (hackerNewsSource) Name() string { return "hackernews" }
+
+func (s hackerNewsSource) Fetch(ctx context.Context, framework Framework) ([]Item, error) {
+	items, err := fetchHackerNewsItems(ctx, s.cfg, framework)
+	return asItems(items), err
+}
+
+func (item HackerNewsItem) Save(db *gorm.DB) error {
+	return upsert(db, &item)
+}
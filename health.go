@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// healthCheckTimeout bounds each dependency check of /readyz.
+const healthCheckTimeout = 2 * time.Second
+
+var startedAt = time.Now()
+
+// dependencyStatus is the outcome of one readiness check. Optional
+// dependencies are reported without affecting readiness, as the endpoints
+// that do not use them keep working while they are down.
+type dependencyStatus struct {
+	Status    string  `json:"status"`
+	Required  bool    `json:"required"`
+	LatencyMS float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+type dependencyCheck struct {
+	name     string
+	required bool
+	check    func(ctx context.Context) error
+}
+
+// healthzHandler serves GET /healthz, which reports that the process is up.
+func healthzHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		return c.JSON(fiber.Map{"status": "ok", "uptime": time.Since(startedAt).Round(time.Second).String()})
+	}
+}
+
+// livezHandler serves GET /livez, the liveness probe. It only depends on
+// the server answering requests, so that a failing dependency never gets
+// the process restarted.
+func livezHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		return c.JSON(fiber.Map{"status": "ok"})
+	}
+}
+
+// readyzHandler serves GET /readyz, the readiness probe: the database must
+// be reachable with every migration applied, and MongoDB reachable when it
+// is the storage backend. Configured search, analytics and cache backends
+// are checked too but are optional. It responds 503 when not ready.
+func readyzHandler(db *gorm.DB, records Store, store *configStore) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		cfg := store.Get()
+		checks := []dependencyCheck{
+			{"database", true, func(ctx context.Context) error {
+				sqlDB, err := db.DB()
+				if err != nil {
+					return err
+				}
+				return sqlDB.PingContext(ctx)
+			}},
+			{"migrations", true, func(ctx context.Context) error {
+				return pendingMigrations(db.WithContext(ctx))
+			}},
+		}
+		if cfg.StorageBackend == storageMongoDB {
+			checks = append(checks, dependencyCheck{"mongodb", true, records.Ping})
+		}
+		if cfg.Elasticsearch.Enabled() {
+			checks = append(checks, dependencyCheck{"elasticsearch", false, func(ctx context.Context) error {
+				return esDo(ctx, cfg.Elasticsearch, "GET", "/", "", nil, nil)
+			}})
+		}
+		if cfg.ClickHouse.Enabled() {
+			checks = append(checks, dependencyCheck{"clickhouse", false, func(ctx context.Context) error {
+				return chExec(ctx, cfg.ClickHouse, "SELECT 1", nil, nil, nil)
+			}})
+		}
+		if cfg.Cache.Enabled() {
+			checks = append(checks, dependencyCheck{"cache", false, readCache.Ping})
+		}
+
+		ready := true
+		statuses := make(map[string]dependencyStatus, len(checks))
+		for _, check := range checks {
+			ctx, cancel := context.WithTimeout(c.UserContext(), healthCheckTimeout)
+			start := time.Now()
+			err := check.check(ctx)
+			cancel()
+
+			status := dependencyStatus{Status: "ok", Required: check.required, LatencyMS: float64(time.Since(start).Microseconds()) / 1000}
+			if err != nil {
+				status.Status, status.Error = "error", err.Error()
+				if check.required {
+					ready = false
+				}
+			}
+			statuses[check.name] = status
+		}
+
+		if !ready {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"status": "unavailable", "checks": statuses})
+		}
+		return c.JSON(fiber.Map{"status": "ok", "checks": statuses})
+	}
+}
+
+// pendingMigrations returns an error listing the migrations not yet
+// applied to db.
+func pendingMigrations(db *gorm.DB) error {
+	ids, applied, err := migrationStatus(db)
+	if err != nil {
+		return err
+	}
+	var pending []string
+	for _, id := range ids {
+		if !applied[id] {
+			pending = append(pending, id)
+		}
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("%d pending: %s", len(pending), strings.Join(pending, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const userAgent = "my-assignment-data-fetcher/1.0 (+https://github.com/parammodi9/Ase5)"
+
+var httpClient = &http.Client{Timeout: 30 * time.Second}
+
+// getJSON issues a GET request and decodes the JSON response into out.
+func getJSON(ctx context.Context, url string, header http.Header, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	return doJSON(req, out)
+}
+
+// postJSON sends body as JSON and decodes the JSON response into out.
+func postJSON(ctx context.Context, url string, header http.Header, body, out interface{}) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return doJSON(req, out)
+}
+
+// doJSON performs req, counts the response towards the collected-bytes
+// metric, and decodes it into out. Non-2xx responses are returned as errors
+// including the start of the response body.
+func doJSON(req *http.Request, out interface{}) error {
+	_, err := doJSONHeader(req, out)
+	return err
+}
+
+// doJSONHeader is doJSON for callers that also need the response headers,
+// e.g. to read rate-limit information. The headers are returned even when
+// the request fails with a non-2xx status.
+func doJSONHeader(req *http.Request, out interface{}) (http.Header, error) {
+	if req.Header.Get("Accept") == "" {
+		req.Header.Set("Accept", "application/json")
+	}
+
+	header, body, err := doRequest(req)
+	if err != nil {
+		return header, err
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return header, fmt.Errorf("decoding response from %s: %w", redactedURL(req.URL), err)
+	}
+	return header, nil
+}
+
+// getXML issues a GET request and decodes the XML response into out.
+func getXML(ctx context.Context, url string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/rss+xml, application/atom+xml, application/xml;q=0.9, */*;q=0.8")
+
+	_, body, err := doRequest(req)
+	if err != nil {
+		return err
+	}
+	if err := xml.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("decoding response from %s: %w", redactedURL(req.URL), err)
+	}
+	return nil
+}
+
+// doRequest performs req and returns the response headers and body,
+// counting the body towards the collected-bytes metric and the fetch run
+// of its context, if any, and archiving
+// successful responses when archival is configured. The request ID of its
+// context, if any, is sent along. Non-2xx responses are returned as
+// *httpStatusError. The URL in its errors is redacted, as they end up in
+// fetch runs and job results.
+func doRequest(req *http.Request) (http.Header, []byte, error) {
+	if req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", userAgent)
+	}
+	if id := requestIDFrom(req.Context()); id != "" {
+		req.Header.Set(requestIDHeader, id)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			urlErr.URL = redactedURL(req.URL)
+		}
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return resp.Header, nil, fmt.Errorf("reading response from %s: %w", redactedURL(req.URL), err)
+	}
+	dataCollected.Add(float64(len(body)))
+	countFetchBytes(req.Context(), len(body))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.Header, body, &httpStatusError{Method: req.Method, URL: redactedURL(req.URL), StatusCode: resp.StatusCode, Body: truncate(strings.TrimSpace(string(body)), 200)}
+	}
+	responseArchive.Save(req, resp.Header, body)
+	return resp.Header, body, nil
+}
+
+// credentialParams are the query parameters several APIs take
+// credentials in.
+var credentialParams = []string{"key", "api_key", "apiKey", "access_token"}
+
+// redactedURL is u without credentials, for logs, errors and archived
+// responses.
+func redactedURL(u *url.URL) string {
+	clean := *u
+	clean.User = nil
+	query := clean.Query()
+	for _, name := range credentialParams {
+		query.Del(name)
+	}
+	clean.RawQuery = query.Encode()
+	return clean.String()
+}
+
+// httpStatusError is a non-2xx response to a request made by doRequest.
+// Body holds the start of the response body.
+type httpStatusError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Body       string
+}
+
+func (e *httpStatusError) Error() string {
+	return fmt.Sprintf("%s %s: status %d: %s", e.Method, e.URL, e.StatusCode, e.Body)
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
@@ -0,0 +1,81 @@
+package main
+
+import "github.com/gofiber/fiber/v2"
+
+// listIssuesHandler serves GET /api/v1/issues, the stored GitHub issues,
+// newest first, optionally filtered by ?repo=, ?state= and ?label=. It
+// takes the same pagination, cursor and sort parameters as /api/v1/posts,
+// sort being score (the reaction count), created_at or updated_at, and
+// the same ?fields= selection.
+func listIssuesHandler(records Store) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		page, perPage, err := pageParams(c)
+		if err != nil {
+			return err
+		}
+		state := c.Query("state")
+		if state != "" && state != "open" && state != "closed" {
+			return fiber.NewError(fiber.StatusBadRequest, "state must be open or closed")
+		}
+		sort, err := sortParams(c, "score", "created_at", "updated_at")
+		if err != nil {
+			return err
+		}
+		period, err := timeRangeParams(c)
+		if err != nil {
+			return err
+		}
+		fields, err := fieldsParam[GitHubIssue](c)
+		if err != nil {
+			return err
+		}
+		filter := IssueFilter{
+			Repo:      c.Query("repo"),
+			State:     state,
+			Label:     c.Query("label"),
+			TimeRange: period,
+			Sort:      sort,
+			Limit:     perPage,
+			Offset:    (page - 1) * perPage,
+		}
+		var after IssueCursor
+		if resume, err := cursorParam(c, &after); err != nil {
+			return err
+		} else if resume {
+			filter.After = &after
+		}
+
+		issues, total, err := records.ListIssues(c.UserContext(), filter)
+		if err != nil {
+			return listError(err)
+		}
+		if issues == nil {
+			issues = []GitHubIssue{}
+		}
+		var next interface{}
+		if sort.Field == "" && len(issues) == perPage {
+			next = encodeCursor(IssueCursor{ID: issues[len(issues)-1].ID})
+		}
+		selected, err := selectFields(issues, fields)
+		if err != nil {
+			return err
+		}
+		return c.JSON(fiber.Map{"total": total, "page": page, "per_page": perPage, "next_cursor": next, "issues": selected})
+	}
+}
+
+// purgeIssuesHandler serves DELETE /api/v1/issues; see purgePostsHandler.
+// ?before= compares with when the issue was opened.
+func purgeIssuesHandler(records Store) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		filter, err := purgeParams(c)
+		if err != nil {
+			return err
+		}
+		purged, err := records.PurgeIssues(c.UserContext(), filter)
+		if err != nil {
+			return err
+		}
+		return c.JSON(fiber.Map{"purged": purged})
+	}
+}
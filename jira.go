@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"gorm.io/gorm"
+)
+
+var jiraAPICalls = promauto.NewCounter(prometheus.CounterOpts{
+	Name: "myapp_jira_api_calls_total",
+	Help: "Total number of API calls to Jira Cloud",
+})
+
+// JiraConfig holds the Jira Cloud site and API credentials. The collector
+// is skipped entirely while URL is empty.
+type JiraConfig struct {
+	URL   string
+	Email string
+	Token string
+}
+
+func (j JiraConfig) Enabled() bool {
+	return j.URL != ""
+}
+
+type JiraIssue struct {
+	ID          string     `json:"id" gorm:"primaryKey"`
+	Key         string     `json:"key" gorm:"index"`
+	Framework   string     `json:"framework" gorm:"index"`
+	Summary     string     `json:"summary"`
+	Description string     `json:"description"`
+	Status      string     `json:"status"`
+	Priority    string     `json:"priority"`
+	Resolution  string     `json:"resolution"`
+	Labels      []string   `json:"labels" gorm:"serializer:json"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	ResolvedAt  *time.Time `json:"resolved_at"`
+}
+
+// jiraTime parses Jira's timestamp format, which omits the colon in the
+// UTC offset and so is not RFC 3339.
+type jiraTime struct {
+	time.Time
+}
+
+func (t *jiraTime) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(string(data), `"`)
+	if s == "" || s == "null" {
+		return nil
+	}
+	parsed, err := time.Parse("2006-01-02T15:04:05.000-0700", s)
+	if err != nil {
+		return err
+	}
+	t.Time = parsed
+	return nil
+}
+
+type jiraNamed struct {
+	Name string `json:"name"`
+}
+
+// fetchJiraIssues runs the framework's JQL query and returns the newest
+// matching issues.
+func fetchJiraIssues(ctx context.Context, cfg *Config, framework Framework) ([]JiraIssue, error) {
+	if !cfg.Jira.Enabled() || framework.JiraJQL == "" {
+		return nil, nil
+	}
+	jiraAPICalls.Inc()
+
+	var result struct {
+		Issues []struct {
+			ID     string `json:"id"`
+			Key    string `json:"key"`
+			Fields struct {
+				Summary        string     `json:"summary"`
+				Description    string     `json:"description"`
+				Status         jiraNamed  `json:"status"`
+				Priority       *jiraNamed `json:"priority"`
+				Resolution     *jiraNamed `json:"resolution"`
+				Labels         []string   `json:"labels"`
+				Created        jiraTime   `json:"created"`
+				Updated        jiraTime   `json:"updated"`
+				ResolutionDate jiraTime   `json:"resolutiondate"`
+			} `json:"fields"`
+		} `json:"issues"`
+	}
+
+	// API v2 returns descriptions as plain text rather than v3's ADF documents.
+	query := url.Values{
+		"jql":        {framework.JiraJQL + " ORDER BY updated DESC"},
+		"maxResults": {fmt.Sprint(cfg.FetchLimit)},
+		"fields":     {"summary,description,status,priority,resolution,labels,created,updated,resolutiondate"},
+	}
+	u := strings.TrimSuffix(cfg.Jira.URL, "/") + "/rest/api/2/search?" + query.Encode()
+	header := http.Header{}
+	header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(cfg.Jira.Email+":"+cfg.Jira.Token)))
+	if err := getJSON(ctx, u, header, &result); err != nil {
+		return nil, err
+	}
+
+	issues := make([]JiraIssue, 0, len(result.Issues))
+	for _, raw := range result.Issues {
+		f := raw.Fields
+		issue := JiraIssue{
+			ID:          raw.ID,
+			Key:         raw.Key,
+			Framework:   framework.Name,
+			Summary:     f.Summary,
+			Description: f.Description,
+			Status:      f.Status.Name,
+			Labels:      f.Labels,
+			CreatedAt:   f.Created.Time,
+			UpdatedAt:   f.Updated.Time,
+		}
+		if f.Priority != nil {
+			issue.Priority = f.Priority.Name
+		}
+		if f.Resolution != nil {
+			issue.Resolution = f.Resolution.Name
+		}
+		if !f.ResolutionDate.IsZero() {
+			resolved := f.ResolutionDate.Time
+			issue.ResolvedAt = &resolved
+		}
+		issues = append(issues, issue)
+	}
+	return issues, nil
+}
+
+func init() {
+	registerSource(func(cfg *Config) Source { return jiraSource{cfg} })
+}
+
+// jiraSource collects issues matching a framework's JQL query.
+type jiraSource struct{ cfg *Config }
+
+func (jiraSource) Name() string { return "jira" }
+
+func (s jiraSource) Fetch(ctx context.Context, framework Framework) ([]Item, error) {
+	issues, err := fetchJiraIssues(ctx, s.cfg, framework)
+	return asItems(issues), err
+}
+
+func (issue JiraIssue) Save(db *gorm.DB) error {
+	return upsert(db, &issue)
+}
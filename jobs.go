@@ -0,0 +1,275 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// Statuses of a fetchJob.
+const (
+	jobQueued    = "queued"
+	jobRunning   = "running"
+	jobSucceeded = "succeeded"
+	jobFailed    = "failed"
+)
+
+// maxFetchJobs bounds the finished jobs kept for GET /api/v1/jobs/:id;
+// the oldest are forgotten first.
+const maxFetchJobs = 100
+
+// fetchJob tracks one collection pass, queued until a worker starts it. A
+// pass fails when it cannot run at all or when any source reports an
+// error, even though the items fetched alongside the error are still
+// stored.
+type fetchJob struct {
+	mu         sync.Mutex
+	done       chan struct{}
+	ID         string                `json:"id"`
+	RequestID  string                `json:"request_id,omitempty"` // X-Request-ID of the request that started the job
+	Status     string                `json:"status"`
+	QueuedAt   time.Time             `json:"queued_at"`
+	StartedAt  *time.Time            `json:"started_at,omitempty"`
+	FinishedAt *time.Time            `json:"finished_at,omitempty"`
+	Sources    map[string]*jobSource `json:"sources"`
+	Error      string                `json:"error,omitempty"`
+}
+
+// jobSource sums a source's fetch runs within a job. Skipped says why
+// the source was not collected.
+type jobSource struct {
+	Items   int            `json:"items"`
+	Counts  map[string]int `json:"counts"`
+	Errors  []string       `json:"errors,omitempty"`
+	Skipped string         `json:"skipped,omitempty"`
+}
+
+// newFetchJob returns a queued job for the request with ID requestID.
+func newFetchJob(requestID string) *fetchJob {
+	id := make([]byte, 8)
+	rand.Read(id)
+	return &fetchJob{
+		done:      make(chan struct{}),
+		ID:        hex.EncodeToString(id),
+		RequestID: requestID,
+		Status:    jobQueued,
+		QueuedAt:  time.Now().UTC(),
+		Sources:   map[string]*jobSource{},
+	}
+}
+
+// start marks the job running.
+func (j *fetchJob) start() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	now := time.Now().UTC()
+	j.StartedAt = &now
+	j.Status = jobRunning
+}
+
+// Done is closed once the job has finished.
+func (j *fetchJob) Done() <-chan struct{} {
+	return j.done
+}
+
+// source returns the summary of the named source, adding it if needed.
+// The caller holds j.mu.
+func (j *fetchJob) source(name string) *jobSource {
+	src := j.Sources[name]
+	if src == nil {
+		src = &jobSource{Counts: map[string]int{}}
+		j.Sources[name] = src
+	}
+	return src
+}
+
+// record adds a fetch run and the error, if any, of storing its items.
+func (j *fetchJob) record(run FetchRun, storeErr error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	src := j.source(run.Source)
+	if run.Error != "" {
+		src.Errors = append(src.Errors, fmt.Sprintf("%s: %s", run.Framework, run.Error))
+	}
+	if storeErr != nil {
+		src.Errors = append(src.Errors, fmt.Sprintf("%s: storing: %v", run.Framework, storeErr))
+		return
+	}
+	src.Items += run.Items
+	for kind, n := range run.Counts {
+		src.Counts[kind] += n
+	}
+}
+
+// fail records that source could not be collected at all.
+func (j *fetchJob) fail(source string, err error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	src := j.source(source)
+	src.Errors = append(src.Errors, err.Error())
+}
+
+// skip records that source was left out, and why.
+func (j *fetchJob) skip(source, reason string) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.source(source).Skipped = reason
+}
+
+// finish marks the job done; err is set when the pass could not run.
+func (j *fetchJob) finish(err error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	now := time.Now().UTC()
+	j.FinishedAt = &now
+	j.Status = jobSucceeded
+	if err != nil {
+		j.Error = err.Error()
+		j.Status = jobFailed
+	}
+	for _, src := range j.Sources {
+		if len(src.Errors) > 0 {
+			j.Status = jobFailed
+		}
+	}
+	close(j.done)
+}
+
+// Err returns nil once the job has succeeded, and otherwise an error made
+// of the job's own error and those of its sources.
+func (j *fetchJob) Err() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	var messages []string
+	if j.Error != "" {
+		messages = append(messages, j.Error)
+	}
+	names := make([]string, 0, len(j.Sources))
+	for name := range j.Sources {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		for _, e := range j.Sources[name].Errors {
+			messages = append(messages, name+": "+e)
+		}
+	}
+	if len(messages) == 0 {
+		if j.Status == jobSucceeded {
+			return nil
+		}
+		messages = append(messages, "job "+j.Status)
+	}
+	return fmt.Errorf("collection pass failed: %s", strings.Join(messages, "; "))
+}
+
+// snapshot returns a copy of the job that is safe to encode while the job
+// is still running.
+func (j *fetchJob) snapshot() *fetchJob {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	c := &fetchJob{ID: j.ID, RequestID: j.RequestID, Status: j.Status, QueuedAt: j.QueuedAt, StartedAt: j.StartedAt, FinishedAt: j.FinishedAt,
+		Sources: make(map[string]*jobSource, len(j.Sources)), Error: j.Error}
+	for name, src := range j.Sources {
+		counts := make(map[string]int, len(src.Counts))
+		for kind, n := range src.Counts {
+			counts[kind] = n
+		}
+		c.Sources[name] = &jobSource{Items: src.Items, Counts: counts, Errors: append([]string(nil), src.Errors...), Skipped: src.Skipped}
+	}
+	return c
+}
+
+// jobRegistry holds the jobs started by the API, oldest first.
+type jobRegistry struct {
+	mu   sync.Mutex
+	jobs []*fetchJob
+}
+
+var fetchJobs = &jobRegistry{}
+
+// Add registers a job.
+func (r *jobRegistry) Add(job *fetchJob) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.jobs = append(r.jobs, job)
+	if len(r.jobs) > maxFetchJobs {
+		r.jobs = r.jobs[len(r.jobs)-maxFetchJobs:]
+	}
+}
+
+// Remove forgets a job that was never queued.
+func (r *jobRegistry) Remove(job *fetchJob) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.jobs = slices.DeleteFunc(r.jobs, func(j *fetchJob) bool { return j == job })
+}
+
+func (r *jobRegistry) Get(id string) *fetchJob {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, job := range r.jobs {
+		if job.ID == id {
+			return job
+		}
+	}
+	return nil
+}
+
+// triggerFetchHandler serves GET /api/v1/fetch-data, queuing a collection
+// pass for the workers, and POST /api/v1/fetch/:framework, queuing one for
+// that framework alone. Either takes ?source= to collect from a single
+// enabled source, such as github or stackoverflow.
+func triggerFetchHandler(db *gorm.DB, store *configStore) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		cfg := store.Get()
+		var scope fetchScope
+		if name := c.Params("framework"); name != "" {
+			framework, err := findFramework(db, name)
+			if err != nil {
+				return err
+			}
+			scope.Framework = framework.Name
+		}
+		if scope.Source = c.Query("source"); scope.Source != "" {
+			var enabled []string
+			for _, src := range newSources(cfg) {
+				if cfg.Flags.Enabled(sourceFlag(src)) {
+					enabled = append(enabled, src.Name())
+				}
+			}
+			if !slices.Contains(enabled, scope.Source) {
+				return fiber.NewError(fiber.StatusBadRequest, "source must be one of "+strings.Join(enabled, ", "))
+			}
+		}
+
+		job, err := fetchWorkers.Enqueue(requestIDFrom(c.UserContext()), cfg, scope)
+		if err != nil {
+			return err
+		}
+		return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+			"message": "Data fetching initiated",
+			"job_id":  job.ID,
+			"status":  apiV1Prefix + "/jobs/" + job.ID,
+		})
+	}
+}
+
+// getJobHandler serves GET /api/v1/jobs/:id, the status of a collection pass
+// started through /api/v1/fetch-data or /api/v1/fetch/:framework.
+func getJobHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		job := fetchJobs.Get(c.Params("id"))
+		if job == nil {
+			return fiber.NewError(fiber.StatusNotFound, "job not found")
+		}
+		return c.JSON(job.snapshot())
+	}
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"crypto/rsa"
+	"fmt"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// JWTConfig enables bearer JWT authentication. Tokens are signed with
+// Secret (HS256) or with the private key of PublicKey, a PEM-encoded RSA
+// public key (RS256); either or both may be set. When Issuer is set,
+// tokens must have been issued by it. Roles come from the roles claim.
+type JWTConfig struct {
+	Secret    string
+	PublicKey string
+	Issuer    string
+}
+
+func (c JWTConfig) Enabled() bool {
+	return c.Secret != "" || c.PublicKey != ""
+}
+
+// role is a privilege level. Each role includes the ones below it.
+type role int
+
+const (
+	roleNone role = iota
+	// roleReader may read the stored records.
+	roleReader
+	// roleOperator may also start collection runs.
+	roleOperator
+	// roleAdmin may also change the registry, purge records and read the
+	// configuration.
+	roleAdmin
+)
+
+var roleNames = map[string]role{"reader": roleReader, "operator": roleOperator, "admin": roleAdmin}
+
+func (r role) String() string {
+	for name, level := range roleNames {
+		if level == r {
+			return name
+		}
+	}
+	return "none"
+}
+
+// principalKey holds the authenticated client of a request in its locals.
+const principalKey = "principal"
+
+// principal is the client a request was authenticated as, by a JWT or an
+// API key.
+type principal struct {
+	Subject string
+	Role    role
+}
+
+// principalOf returns the client a request was authenticated as, or nil.
+func principalOf(c *fiber.Ctx) *principal {
+	p, _ := c.Locals(principalKey).(*principal)
+	return p
+}
+
+// jwtClaims are the claims read from a bearer JWT.
+type jwtClaims struct {
+	Roles []string `json:"roles"`
+	jwt.RegisteredClaims
+}
+
+// role returns the highest known role among the claimed ones.
+func (c jwtClaims) role() role {
+	highest := roleNone
+	for _, name := range c.Roles {
+		highest = max(highest, roleNames[strings.ToLower(name)])
+	}
+	return highest
+}
+
+// parseJWT verifies a bearer token against cfg and returns its claims.
+func parseJWT(cfg JWTConfig, token string) (*jwtClaims, error) {
+	var methods []string
+	var publicKey *rsa.PublicKey
+	if cfg.Secret != "" {
+		methods = append(methods, jwt.SigningMethodHS256.Alg())
+	}
+	if cfg.PublicKey != "" {
+		key, err := jwt.ParseRSAPublicKeyFromPEM([]byte(cfg.PublicKey))
+		if err != nil {
+			return nil, fmt.Errorf("parsing JWT_PUBLIC_KEY_FILE: %w", err)
+		}
+		methods, publicKey = append(methods, jwt.SigningMethodRS256.Alg()), key
+	}
+	options := []jwt.ParserOption{jwt.WithValidMethods(methods), jwt.WithExpirationRequired()}
+	if cfg.Issuer != "" {
+		options = append(options, jwt.WithIssuer(cfg.Issuer))
+	}
+
+	claims := &jwtClaims{}
+	_, err := jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		if t.Method == jwt.SigningMethodRS256 {
+			return publicKey, nil
+		}
+		return []byte(cfg.Secret), nil
+	}, options...)
+	if err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// jwtAuth is middleware authenticating requests that carry a bearer JWT,
+// when JWTs are configured. Bearer values that are not JWTs, such as the
+// admin token, are left to adminAuth; invalid JWTs are rejected.
+func jwtAuth(store *configStore) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		cfg := store.Get().JWT
+		token, ok := strings.CutPrefix(c.Get(fiber.HeaderAuthorization), "Bearer ")
+		if !cfg.Enabled() || !ok || strings.Count(token, ".") != 2 {
+			return c.Next()
+		}
+		claims, err := parseJWT(cfg, token)
+		if err != nil {
+			return fiber.NewError(fiber.StatusUnauthorized, "invalid token: "+err.Error())
+		}
+		c.Locals(principalKey, &principal{Subject: claims.Subject, Role: claims.role()})
+		return c.Next()
+	}
+}
+
+// requireRole is middleware admitting only clients authenticated with at
+// least role min.
+func requireRole(min role) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		p := principalOf(c)
+		if p == nil {
+			return fiber.NewError(fiber.StatusUnauthorized, "missing "+apiKeyHeader+" header or bearer token")
+		}
+		if p.Role < min {
+			return fiber.NewError(fiber.StatusForbidden, "this endpoint requires the "+min.String()+" role")
+		}
+		return c.Next()
+	}
+}
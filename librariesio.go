@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"gorm.io/gorm"
+)
+
+var librariesIOAPICalls = promauto.NewCounter(prometheus.CounterOpts{
+	Name: "myapp_librariesio_api_calls_total",
+	Help: "Total number of API calls to Libraries.io",
+})
+
+// librariesIOPlatforms maps the ecosystems of advisoryPackages to
+// Libraries.io platform names.
+var librariesIOPlatforms = map[string]string{
+	"GO":  "go",
+	"NPM": "npm",
+	"PIP": "pypi",
+}
+
+// LibrariesIOSnapshot records a package's ecosystem reach for one UTC day.
+// Re-fetching on the same day overwrites that day's row.
+type LibrariesIOSnapshot struct {
+	Platform            string    `json:"platform" gorm:"primaryKey"`
+	Package             string    `json:"package" gorm:"primaryKey"`
+	Date                time.Time `json:"date" gorm:"primaryKey;type:date"`
+	Framework           string    `json:"framework" gorm:"index"`
+	DependentsCount     int       `json:"dependents_count"`
+	DependentReposCount int       `json:"dependent_repos_count"`
+	SourceRank          int       `json:"source_rank"`
+	LatestRelease       string    `json:"latest_release"`
+	RecordedAt          time.Time `json:"recorded_at"`
+}
+
+func init() {
+	registerSource(func(cfg *Config) Source { return librariesIOSource{cfg} })
+}
+
+// librariesIOSource collects dependents and SourceRank of a framework's
+// packages. It requires LIBRARIES_IO_KEY.
+type librariesIOSource struct{ cfg *Config }
+
+func (librariesIOSource) Name() string { return "librariesio" }
+
+func (s librariesIOSource) Fetch(ctx context.Context, framework Framework) ([]Item, error) {
+	snapshots, err := fetchLibrariesIOSnapshots(ctx, s.cfg, framework)
+	return asItems(snapshots), err
+}
+
+func fetchLibrariesIOSnapshots(ctx context.Context, cfg *Config, framework Framework) ([]LibrariesIOSnapshot, error) {
+	if cfg.LibrariesIOKey == "" {
+		return nil, nil
+	}
+
+	var snapshots []LibrariesIOSnapshot
+	for _, pkg := range framework.advisoryPackages() {
+		platform := librariesIOPlatforms[pkg.ecosystem]
+		librariesIOAPICalls.Inc()
+
+		var project struct {
+			DependentsCount     int    `json:"dependents_count"`
+			DependentReposCount int    `json:"dependent_repos_count"`
+			Rank                int    `json:"rank"`
+			LatestReleaseNumber string `json:"latest_release_number"`
+		}
+		u := fmt.Sprintf("https://libraries.io/api/%s/%s?api_key=%s", platform, url.PathEscape(pkg.name), url.QueryEscape(cfg.LibrariesIOKey))
+		if err := getJSON(ctx, u, nil, &project); err != nil {
+			return snapshots, err
+		}
+
+		now := time.Now().UTC()
+		snapshots = append(snapshots, LibrariesIOSnapshot{
+			Platform:            platform,
+			Package:             pkg.name,
+			Date:                now.Truncate(24 * time.Hour),
+			Framework:           framework.Name,
+			DependentsCount:     project.DependentsCount,
+			DependentReposCount: project.DependentReposCount,
+			SourceRank:          project.Rank,
+			LatestRelease:       project.LatestReleaseNumber,
+			RecordedAt:          now,
+		})
+	}
+	return snapshots, nil
+}
+
+func (snapshot LibrariesIOSnapshot) Save(db *gorm.DB) error {
+	return upsert(db, &snapshot)
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"gorm.io/gorm"
+)
+
+var lobstersAPICalls = promauto.NewCounter(prometheus.CounterOpts{
+	Name: "myapp_lobsters_api_calls_total",
+	Help: "Total number of API calls to lobste.rs",
+})
+
+// LobstersStory is a lobste.rs story carrying one of a framework's tags.
+type LobstersStory struct {
+	ShortID      string    `json:"short_id" gorm:"primaryKey"`
+	Framework    string    `json:"framework" gorm:"index"`
+	Title        string    `json:"title"`
+	URL          string    `json:"url"`
+	Description  string    `json:"description"`
+	Submitter    string    `json:"submitter"`
+	Tags         []string  `json:"tags" gorm:"serializer:json"`
+	Score        int       `json:"score"`
+	CommentCount int       `json:"comment_count"`
+	CommentsURL  string    `json:"comments_url"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+func init() {
+	registerSource(func(cfg *Config) Source { return lobstersSource{cfg} })
+}
+
+// lobstersSource collects lobste.rs stories with a framework's tags.
+type lobstersSource struct{ cfg *Config }
+
+func (lobstersSource) Name() string { return "lobsters" }
+
+func (s lobstersSource) Fetch(ctx context.Context, framework Framework) ([]Item, error) {
+	stories, err := fetchLobstersStories(ctx, s.cfg, framework)
+	return asItems(stories), err
+}
+
+// fetchLobstersStories returns the newest stories for each of the
+// framework's lobste.rs tags, up to cfg.FetchLimit per tag.
+func fetchLobstersStories(ctx context.Context, cfg *Config, framework Framework) ([]LobstersStory, error) {
+	var stories []LobstersStory
+	for _, tag := range framework.LobstersTags {
+		lobstersAPICalls.Inc()
+
+		var list []struct {
+			ShortID      string    `json:"short_id"`
+			Title        string    `json:"title"`
+			URL          string    `json:"url"`
+			Description  string    `json:"description_plain"`
+			Score        int       `json:"score"`
+			CommentCount int       `json:"comment_count"`
+			CommentsURL  string    `json:"comments_url"`
+			CreatedAt    time.Time `json:"created_at"`
+			Tags         []string  `json:"tags"`
+			// submitter_user is a username, or a user object on older
+			// versions of the site.
+			SubmitterUser json.RawMessage `json:"submitter_user"`
+		}
+		if err := getJSON(ctx, fmt.Sprintf("https://lobste.rs/t/%s.json", url.PathEscape(tag)), nil, &list); err != nil {
+			return stories, err
+		}
+
+		for i, s := range list {
+			if i == cfg.FetchLimit {
+				break
+			}
+			stories = append(stories, LobstersStory{
+				ShortID:      s.ShortID,
+				Framework:    framework.Name,
+				Title:        s.Title,
+				URL:          s.URL,
+				Description:  s.Description,
+				Submitter:    lobstersUsername(s.SubmitterUser),
+				Tags:         s.Tags,
+				Score:        s.Score,
+				CommentCount: s.CommentCount,
+				CommentsURL:  s.CommentsURL,
+				CreatedAt:    s.CreatedAt,
+			})
+		}
+	}
+	return stories, nil
+}
+
+func lobstersUsername(raw json.RawMessage) string {
+	var name string
+	if json.Unmarshal(raw, &name) == nil {
+		return name
+	}
+	var user struct {
+		Username string `json:"username"`
+	}
+	json.Unmarshal(raw, &user)
+	return user.Username
+}
+
+func (story LobstersStory) Save(db *gorm.DB) error {
+	return upsert(db, &story)
+}
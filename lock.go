@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"hash/fnv"
+
+	"gorm.io/gorm"
+)
+
+// fetchLockPrefix namespaces the database locks taken by collection
+// passes.
+const fetchLockPrefix = "my-assignment.fetch."
+
+// fetchLocks serializes the collection of each source across every
+// instance sharing the database, with Postgres advisory locks or MySQL
+// named locks. Instances on SQLite do not share their database, so there
+// the locks always succeed.
+type fetchLocks struct {
+	db     *gorm.DB
+	driver string
+}
+
+func newFetchLocks(db *gorm.DB, driver string) *fetchLocks {
+	return &fetchLocks{db: db, driver: driver}
+}
+
+// TryAcquire takes the lock on collecting source without waiting. It
+// reports false when another pass, on this instance or another one,
+// holds it; otherwise release must be called once the source is done.
+// The lock lives on a connection of its own and is also released if that
+// connection is lost.
+func (l *fetchLocks) TryAcquire(ctx context.Context, source string) (release func(), ok bool, err error) {
+	var acquire, unlock string
+	var key interface{}
+	switch l.driver {
+	case driverPostgres:
+		h := fnv.New64a()
+		h.Write([]byte(fetchLockPrefix + source))
+		acquire, unlock, key = "SELECT pg_try_advisory_lock($1)", "SELECT pg_advisory_unlock($1)", int64(h.Sum64())
+	case driverMySQL:
+		// GET_LOCK returns 1 when the lock was taken and 0 on timeout.
+		acquire, unlock, key = "SELECT GET_LOCK(?, 0) = 1", "SELECT RELEASE_LOCK(?)", fetchLockPrefix+source
+	default:
+		return func() {}, true, nil
+	}
+
+	sqlDB, err := primary(l.db).DB()
+	if err != nil {
+		return nil, false, err
+	}
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("locking %s collection: %w", source, err)
+	}
+	if err := conn.QueryRowContext(ctx, acquire, key).Scan(&ok); err != nil || !ok {
+		conn.Close()
+		if err != nil {
+			return nil, false, fmt.Errorf("locking %s collection: %w", source, err)
+		}
+		return nil, false, nil
+	}
+	return func() { releaseFetchLock(conn, unlock, key) }, true, nil
+}
+
+// releaseFetchLock unlocks key and returns conn to the pool. Should the
+// unlock fail, the connection is discarded so that the lock goes with
+// its session.
+func releaseFetchLock(conn *sql.Conn, unlock string, key interface{}) {
+	var released sql.NullBool
+	if err := conn.QueryRowContext(context.Background(), unlock, key).Scan(&released); err != nil {
+		conn.Raw(func(interface{}) error { return driver.ErrBadConn })
+	}
+	conn.Close()
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"gorm.io/gorm"
+)
+
+var mastodonAPICalls = promauto.NewCounter(prometheus.CounterOpts{
+	Name: "myapp_mastodon_api_calls_total",
+	Help: "Total number of API calls to Mastodon instances",
+})
+
+// MastodonStatus is a toot carrying one of a framework's hashtags. The same
+// toot federates to many instances, so it is keyed by its canonical URI.
+type MastodonStatus struct {
+	URI             string    `json:"uri" gorm:"primaryKey"`
+	Framework       string    `json:"framework" gorm:"index"`
+	Instance        string    `json:"instance"`
+	Hashtag         string    `json:"hashtag"`
+	Account         string    `json:"account"`
+	Content         string    `json:"content"`
+	URL             string    `json:"url"`
+	Language        string    `json:"language"`
+	ReblogsCount    int       `json:"reblogs_count"`
+	FavouritesCount int       `json:"favourites_count"`
+	RepliesCount    int       `json:"replies_count"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// fetchMastodonStatuses returns the latest public toots for each of the
+// framework's hashtags on every configured instance.
+func fetchMastodonStatuses(ctx context.Context, cfg *Config, framework Framework) ([]MastodonStatus, error) {
+	var statuses []MastodonStatus
+	for _, instance := range cfg.MastodonInstances {
+		for _, hashtag := range framework.MastodonHashtags {
+			mastodonAPICalls.Inc()
+
+			var result []struct {
+				URI             string    `json:"uri"`
+				URL             string    `json:"url"`
+				Content         string    `json:"content"`
+				Language        string    `json:"language"`
+				ReblogsCount    int       `json:"reblogs_count"`
+				FavouritesCount int       `json:"favourites_count"`
+				RepliesCount    int       `json:"replies_count"`
+				CreatedAt       time.Time `json:"created_at"`
+				Account         struct {
+					Acct string `json:"acct"`
+				} `json:"account"`
+			}
+			u := fmt.Sprintf("https://%s/api/v1/timelines/tag/%s?limit=%d",
+				instance, url.PathEscape(hashtag), cfg.FetchLimit)
+			if err := getJSON(ctx, u, nil, &result); err != nil {
+				return statuses, fmt.Errorf("instance %s: %w", instance, err)
+			}
+
+			for _, s := range result {
+				statuses = append(statuses, MastodonStatus{
+					URI:             s.URI,
+					Framework:       framework.Name,
+					Instance:        instance,
+					Hashtag:         hashtag,
+					Account:         s.Account.Acct,
+					Content:         s.Content,
+					URL:             s.URL,
+					Language:        s.Language,
+					ReblogsCount:    s.ReblogsCount,
+					FavouritesCount: s.FavouritesCount,
+					RepliesCount:    s.RepliesCount,
+					CreatedAt:       s.CreatedAt,
+				})
+			}
+		}
+	}
+	return statuses, nil
+}
+
+func init() {
+	registerSource(func(cfg *Config) Source { return mastodonSource{cfg} })
+}
+
+// mastodonSource collects toots with a framework's hashtags.
+type mastodonSource struct{ cfg *Config }
+
+func (mastodonSource) Name() string { return "mastodon" }
+
+func (s mastodonSource) Fetch(ctx context.Context, framework Framework) ([]Item, error) {
+	statuses, err := fetchMastodonStatuses(ctx, s.cfg, framework)
+	return asItems(statuses), err
+}
+
+func (status MastodonStatus) Save(db *gorm.DB) error {
+	return upsert(db, &status)
+}
@@ -0,0 +1,912 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// migrationOptions keeps the applied migration IDs in schema_migrations.
+var migrationOptions = &gormigrate.Options{
+	TableName:                 "schema_migrations",
+	IDColumnName:              "id",
+	IDColumnSize:              255,
+	ValidateUnknownMigrations: true,
+}
+
+// migrations is the ordered schema history. Applied migrations must never
+// be edited: schema changes go into a new entry appended at the end, with
+// a Rollback that undoes exactly what its Migrate did. IDs are prefixed
+// with a UTC timestamp so that they sort in application order.
+var migrations = []*gormigrate.Migration{
+	{
+		// The schema as AutoMigrate created it before versioned migrations
+		// were introduced. AutoMigrate only adds what is missing, so this
+		// also adopts databases created by earlier releases.
+		ID: "20231015000000_initial_schema",
+		Migrate: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(initialSchema()...)
+		},
+		Rollback: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(initialSchema()...)
+		},
+	},
+	{
+		ID: "20231016000000_collection_timestamps",
+		Migrate: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(collectionTimestamps()...)
+		},
+		Rollback: func(tx *gorm.DB) error {
+			for _, model := range collectionTimestamps() {
+				if err := dropColumns(tx, model, "DeletedAt", "UpdatedAt", "CreatedAt"); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		// Answers are kept in stack_overflow_answers; the old JSON column
+		// was never populated by the collector.
+		ID: "20231017000000_drop_post_answers_column",
+		Migrate: func(tx *gorm.DB) error {
+			return dropColumns(tx, &postAnswersColumn{}, "Answers")
+		},
+		Rollback: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&postAnswersColumn{})
+		},
+	},
+	{
+		ID: "20231018000000_post_tags",
+		Migrate: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(postTags()...)
+		},
+		Rollback: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(postTags()...)
+		},
+	},
+	{
+		ID: "20231019000000_issue_details",
+		Migrate: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&issueDetails{})
+		},
+		Rollback: func(tx *gorm.DB) error {
+			return dropColumns(tx, &issueDetails{}, "ClosedAt", "IssueUpdatedAt", "IssueCreatedAt", "Assignees", "Author", "State")
+		},
+	},
+	{
+		ID: "20231020000000_fetch_runs",
+		Migrate: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&fetchRunsTable{})
+		},
+		Rollback: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&fetchRunsTable{})
+		},
+	},
+	{
+		// Posts used to be inserted on every fetch. Only the most recently
+		// written copy of each question is kept before the unique index
+		// that upserts rely on is created.
+		ID: "20231021000000_unique_posts",
+		Migrate: func(tx *gorm.DB) error {
+			if tx.Migrator().HasIndex(&uniquePost{}, "idx_stack_overflow_posts_question") {
+				return nil
+			}
+			if err := dedupePosts(tx); err != nil {
+				return err
+			}
+			return tx.Migrator().CreateIndex(&uniquePost{}, "idx_stack_overflow_posts_question")
+		},
+		Rollback: func(tx *gorm.DB) error {
+			return tx.Migrator().DropIndex(&uniquePost{}, "idx_stack_overflow_posts_question")
+		},
+	},
+	{
+		ID: "20231022000000_framework_columns",
+		Migrate: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(frameworkColumns()...)
+		},
+		Rollback: func(tx *gorm.DB) error {
+			models := frameworkColumns()
+			if err := dropColumns(tx, models[0], "CreationDate", "Framework"); err != nil {
+				return err
+			}
+			return dropColumns(tx, models[1], "Framework")
+		},
+	},
+	{
+		ID: "20231023000000_issue_repo",
+		Migrate: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&issueRepo{})
+		},
+		Rollback: func(tx *gorm.DB) error {
+			return dropColumns(tx, &issueRepo{}, "Repo")
+		},
+	},
+	{
+		// Full-text search is only implemented for Postgres; the other
+		// drivers search with LIKE.
+		ID: "20231024000000_search_vectors",
+		Migrate: func(tx *gorm.DB) error {
+			if tx.Dialector.Name() != driverPostgres {
+				return nil
+			}
+			for _, table := range searchVectorTables {
+				err := tx.Exec(`ALTER TABLE ? ADD COLUMN IF NOT EXISTS search_vector tsvector
+GENERATED ALWAYS AS (setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+    setweight(to_tsvector('english', coalesce(body, '')), 'B')) STORED`, clause.Table{Name: table}).Error
+				if err != nil {
+					return err
+				}
+				err = tx.Exec("CREATE INDEX IF NOT EXISTS ? ON ? USING GIN (search_vector)",
+					clause.Table{Name: "idx_" + table + "_search_vector"}, clause.Table{Name: table}).Error
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Rollback: func(tx *gorm.DB) error {
+			if tx.Dialector.Name() != driverPostgres {
+				return nil
+			}
+			for _, table := range searchVectorTables {
+				if err := tx.Exec("ALTER TABLE ? DROP COLUMN IF EXISTS search_vector", clause.Table{Name: table}).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		ID: "20231025000000_answer_framework",
+		Migrate: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&answerFramework{})
+		},
+		Rollback: func(tx *gorm.DB) error {
+			return dropColumns(tx, &answerFramework{}, "Framework")
+		},
+	},
+	{
+		ID: "20231026000000_post_score",
+		Migrate: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&postScore{})
+		},
+		Rollback: func(tx *gorm.DB) error {
+			return dropColumns(tx, &postScore{}, "Score")
+		},
+	},
+	{
+		ID: "20231027000000_api_keys",
+		Migrate: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&apiKeysTable{})
+		},
+		Rollback: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&apiKeysTable{})
+		},
+	},
+	{
+		ID: "20231028000000_webhooks",
+		Migrate: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&webhooksTable{})
+		},
+		Rollback: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&webhooksTable{})
+		},
+	},
+	{
+		ID: "20231029000000_fetch_run_bytes",
+		Migrate: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&fetchRunBytes{})
+		},
+		Rollback: func(tx *gorm.DB) error {
+			return dropColumns(tx, &fetchRunBytes{}, "Bytes")
+		},
+	},
+}
+
+// fetchRunBytes is the column added to fetch runs by
+// 20231029000000_fetch_run_bytes.
+type fetchRunBytes struct {
+	Bytes int64
+}
+
+func (fetchRunBytes) TableName() string { return "fetch_runs" }
+
+// webhooksTable is webhooks as created by 20231028000000_webhooks.
+type webhooksTable struct {
+	ID        uint     `gorm:"primaryKey"`
+	URL       string   `gorm:"not null"`
+	Events    []string `gorm:"serializer:json"`
+	Secret    string
+	CreatedAt time.Time
+}
+
+func (webhooksTable) TableName() string { return "webhooks" }
+
+// apiKeysTable is api_keys as created by 20231027000000_api_keys.
+type apiKeysTable struct {
+	ID         uint   `gorm:"primaryKey"`
+	Name       string `gorm:"uniqueIndex;size:191;not null"`
+	Hash       string `gorm:"uniqueIndex;size:64;not null"`
+	Prefix     string
+	CreatedAt  time.Time
+	LastUsedAt *time.Time
+}
+
+func (apiKeysTable) TableName() string { return "api_keys" }
+
+// postScore is the column added to posts by 20231026000000_post_score.
+type postScore struct {
+	Score int `gorm:"index"`
+}
+
+func (postScore) TableName() string { return "stack_overflow_posts" }
+
+// answerFramework is the column added to answers by
+// 20231025000000_answer_framework.
+type answerFramework struct {
+	Framework string `gorm:"index"`
+}
+
+func (answerFramework) TableName() string { return "stack_overflow_answers" }
+
+// searchVectorTables are the tables given a search_vector column by
+// 20231024000000_search_vectors.
+var searchVectorTables = []string{"stack_overflow_posts", "git_hub_issues"}
+
+// issueRepo is the column added to issues by 20231023000000_issue_repo.
+type issueRepo struct {
+	Repo string `gorm:"index"`
+}
+
+func (issueRepo) TableName() string { return "git_hub_issues" }
+
+// frameworkColumns returns the posts and issues models reduced to the
+// columns added by 20231022000000_framework_columns.
+func frameworkColumns() []interface{} {
+	type StackOverflowPost struct {
+		Framework    string `gorm:"index"`
+		CreationDate time.Time
+	}
+	type GitHubIssue struct {
+		Framework string `gorm:"index"`
+	}
+	return []interface{}{&StackOverflowPost{}, &GitHubIssue{}}
+}
+
+// uniquePost is the index added to posts by 20231021000000_unique_posts.
+type uniquePost struct {
+	QuestionID int    `gorm:"uniqueIndex:idx_stack_overflow_posts_question"`
+	Site       string `gorm:"uniqueIndex:idx_stack_overflow_posts_question;default:stackoverflow"`
+}
+
+func (uniquePost) TableName() string { return "stack_overflow_posts" }
+
+// dedupePosts reduces every set of posts sharing a site and question ID to
+// its most recently updated row. It works row by row because the table
+// has no column that tells duplicates apart.
+func dedupePosts(tx *gorm.DB) error {
+	type post struct {
+		QuestionID int
+		Site       string
+		Title      string
+		Body       string
+		CreatedAt  time.Time
+		UpdatedAt  time.Time
+		DeletedAt  gorm.DeletedAt
+	}
+	var dupes []struct {
+		QuestionID int
+		Site       string
+	}
+	err := tx.Table("stack_overflow_posts").Select("question_id, site").
+		Group("question_id, site").Having("COUNT(*) > 1").Scan(&dupes).Error
+	if err != nil {
+		return err
+	}
+	for _, d := range dupes {
+		var keep post
+		err := tx.Table("stack_overflow_posts").Unscoped().
+			Where("question_id = ? AND site = ?", d.QuestionID, d.Site).
+			Order("updated_at DESC").Limit(1).Scan(&keep).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Exec("DELETE FROM stack_overflow_posts WHERE question_id = ? AND site = ?", d.QuestionID, d.Site).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Table("stack_overflow_posts").Create(&keep).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fetchRunsTable is fetch_runs as created by 20231020000000_fetch_runs.
+type fetchRunsTable struct {
+	ID         uint      `gorm:"primaryKey"`
+	Source     string    `gorm:"index"`
+	Framework  string    `gorm:"index"`
+	StartedAt  time.Time `gorm:"index"`
+	FinishedAt time.Time
+	Items      int
+	Counts     map[string]int `gorm:"serializer:json"`
+	Error      string
+}
+
+func (fetchRunsTable) TableName() string { return "fetch_runs" }
+
+// issueDetails is the set of columns added to issues by
+// 20231019000000_issue_details.
+type issueDetails struct {
+	State          string `gorm:"index"`
+	Author         string
+	Assignees      []string  `gorm:"serializer:json"`
+	IssueCreatedAt time.Time `gorm:"index"`
+	IssueUpdatedAt time.Time
+	ClosedAt       *time.Time
+}
+
+func (issueDetails) TableName() string { return "git_hub_issues" }
+
+// postTags returns the tags table and the post_tags join table as
+// created by 20231018000000_post_tags.
+func postTags() []interface{} {
+	type Tag struct {
+		Name string `gorm:"primaryKey;size:191"`
+	}
+	type PostTag struct {
+		QuestionID int    `gorm:"primaryKey;autoIncrement:false"`
+		Site       string `gorm:"primaryKey;size:191"`
+		TagName    string `gorm:"primaryKey;size:191"`
+	}
+	return []interface{}{&Tag{}, &PostTag{}}
+}
+
+// postAnswersColumn is the answers column dropped by
+// 20231017000000_drop_post_answers_column.
+type postAnswersColumn struct {
+	Answers string
+}
+
+func (postAnswersColumn) TableName() string { return "stack_overflow_posts" }
+
+// collectionTimestamps returns the posts and issues models as of
+// 20231016000000_collection_timestamps, reduced to the columns it adds.
+func collectionTimestamps() []interface{} {
+	type StackOverflowPost struct {
+		CreatedAt time.Time
+		UpdatedAt time.Time
+		DeletedAt gorm.DeletedAt `gorm:"index"`
+	}
+	type GitHubIssue struct {
+		CreatedAt time.Time
+		UpdatedAt time.Time
+		DeletedAt gorm.DeletedAt `gorm:"index"`
+	}
+	return []interface{}{&StackOverflowPost{}, &GitHubIssue{}}
+}
+
+// initialSchema returns every model as of 20231015000000_initial_schema,
+// reduced to its stored columns. Later migrations change some of them, so
+// the current models must not be used here.
+func initialSchema() []interface{} {
+	type StackOverflowPost struct {
+		QuestionID int
+		Site       string `gorm:"index;default:stackoverflow"`
+		Title      string
+		Body       string
+		Answers    string
+	}
+
+	type GitHubIssue struct {
+		ID        int
+		Number    int
+		Title     string
+		Body      string
+		Labels    []string `gorm:"serializer:json"`
+		Comments  int
+		Reactions int
+	}
+
+	type Framework struct {
+		ID                  uint   `gorm:"primaryKey"`
+		Name                string `gorm:"uniqueIndex;size:191;not null"`
+		StackOverflowTag    string
+		GitHubRepo          string
+		StackExchangeSites  []string `gorm:"serializer:json"`
+		StackOverflowTeam   string
+		Subreddits          []string `gorm:"serializer:json"`
+		HackerNewsQuery     string
+		GitLabProject       string
+		BitbucketRepo       string
+		JiraJQL             string
+		DevToTag            string
+		NpmPackage          string
+		PyPIPackage         string
+		GoModule            string
+		NVDKeyword          string
+		YouTubeQuery        string
+		DockerImages        []string `gorm:"serializer:json"`
+		MastodonHashtags    []string `gorm:"serializer:json"`
+		ArxivQueries        []string `gorm:"serializer:json"`
+		LobstersTags        []string `gorm:"serializer:json"`
+		DiscourseURL        string
+		DiscourseCategories []string `gorm:"serializer:json"`
+		Feeds               []string `gorm:"serializer:json"`
+	}
+
+	type RedditPost struct {
+		ID          string `gorm:"primaryKey"`
+		Framework   string `gorm:"index"`
+		Subreddit   string
+		Title       string
+		Body        string
+		Author      string
+		Score       int
+		NumComments int
+		Permalink   string
+		CreatedAt   time.Time
+	}
+
+	type RedditComment struct {
+		ID        string `gorm:"primaryKey"`
+		PostID    string `gorm:"index"`
+		Author    string
+		Body      string
+		Score     int
+		CreatedAt time.Time
+	}
+
+	type HackerNewsItem struct {
+		ID          string `gorm:"primaryKey"`
+		Framework   string `gorm:"index"`
+		Type        string
+		Title       string
+		Text        string
+		URL         string
+		Author      string
+		Points      int
+		NumComments int
+		StoryID     int
+		CreatedAt   time.Time
+	}
+
+	type GitLabIssue struct {
+		ID        int `gorm:"primaryKey;autoIncrement:false"`
+		IID       int
+		Framework string `gorm:"index"`
+		Project   string
+		Title     string
+		Body      string
+		State     string
+		Author    string
+		Labels    []string `gorm:"serializer:json"`
+		WebURL    string
+		CreatedAt time.Time
+		UpdatedAt time.Time
+	}
+
+	type JiraIssue struct {
+		ID          string `gorm:"primaryKey"`
+		Key         string `gorm:"index"`
+		Framework   string `gorm:"index"`
+		Summary     string
+		Description string
+		Status      string
+		Priority    string
+		Resolution  string
+		Labels      []string `gorm:"serializer:json"`
+		CreatedAt   time.Time
+		UpdatedAt   time.Time
+		ResolvedAt  *time.Time
+	}
+
+	type DiscourseTopic struct {
+		Forum        string `gorm:"primaryKey"`
+		TopicID      int    `gorm:"primaryKey;autoIncrement:false"`
+		Framework    string `gorm:"index"`
+		Category     string
+		Title        string
+		Body         string
+		ReplyCount   int
+		Views        int
+		LikeCount    int
+		URL          string
+		CreatedAt    time.Time
+		LastPostedAt time.Time
+	}
+
+	type GitHubDiscussion struct {
+		ID                string `gorm:"primaryKey"`
+		Number            int
+		Framework         string `gorm:"index"`
+		Repo              string
+		Title             string
+		Body              string
+		URL               string
+		Category          string
+		Author            string
+		UpvoteCount       int
+		CommentCount      int
+		AnswerID          string
+		AnswerBody        string
+		AnswerAuthor      string
+		AnswerUpvoteCount int
+		AnswerChosenAt    *time.Time
+		CreatedAt         time.Time
+		UpdatedAt         time.Time
+	}
+
+	type GitHubPullRequest struct {
+		ID           int `gorm:"primaryKey;autoIncrement:false"`
+		Number       int
+		Framework    string `gorm:"index"`
+		Repo         string
+		Title        string
+		Body         string
+		State        string
+		Draft        bool
+		Author       string
+		Labels       []string `gorm:"serializer:json"`
+		Additions    int
+		Deletions    int
+		ChangedFiles int
+		Commits      int
+		CreatedAt    time.Time
+		UpdatedAt    time.Time
+		ClosedAt     *time.Time
+		MergedAt     *time.Time
+	}
+
+	type GitHubRelease struct {
+		ID          int    `gorm:"primaryKey;autoIncrement:false"`
+		Framework   string `gorm:"index"`
+		Repo        string
+		TagName     string
+		Name        string
+		Body        string
+		Draft       bool
+		Prerelease  bool
+		URL         string
+		PublishedAt *time.Time `gorm:"index"`
+	}
+
+	type GitHubRepoSnapshot struct {
+		Repo       string    `gorm:"primaryKey"`
+		Date       time.Time `gorm:"primaryKey;type:date"`
+		Framework  string    `gorm:"index"`
+		Stars      int
+		Forks      int
+		OpenIssues int
+		Watchers   int
+		RecordedAt time.Time
+	}
+
+	type GitHubCommitActivity struct {
+		Repo      string    `gorm:"primaryKey"`
+		Week      time.Time `gorm:"primaryKey"`
+		Framework string    `gorm:"index"`
+		Total     int
+		Days      []int `gorm:"serializer:json"`
+	}
+
+	type GitHubContributor struct {
+		Repo        string `gorm:"primaryKey"`
+		Login       string `gorm:"primaryKey"`
+		Framework   string `gorm:"index"`
+		Commits     int
+		Additions   int
+		Deletions   int
+		FirstWeek   time.Time
+		LastWeek    time.Time
+		ActiveWeeks int
+		CollectedAt time.Time
+	}
+
+	type StackOverflowAnswer struct {
+		AnswerID     int `gorm:"primaryKey;autoIncrement:false"`
+		QuestionID   int `gorm:"index"`
+		Site         string
+		Body         string
+		Score        int
+		IsAccepted   bool
+		Owner        string
+		CreationDate time.Time
+	}
+
+	type StackOverflowComment struct {
+		CommentID    int `gorm:"primaryKey;autoIncrement:false"`
+		QuestionID   int `gorm:"index"`
+		Site         string
+		Body         string
+		Score        int
+		Owner        string
+		CreationDate time.Time
+	}
+
+	type GitHubIssueComment struct {
+		ID        int `gorm:"primaryKey;autoIncrement:false"`
+		IssueID   int `gorm:"index"`
+		Author    string
+		Body      string
+		CreatedAt time.Time
+		UpdatedAt time.Time
+	}
+
+	type DevToArticle struct {
+		ID                     int    `gorm:"primaryKey;autoIncrement:false"`
+		Framework              string `gorm:"index"`
+		Title                  string
+		BodyMarkdown           string
+		URL                    string
+		Author                 string
+		Tags                   []string `gorm:"serializer:json"`
+		PublicReactionsCount   int
+		PositiveReactionsCount int
+		CommentsCount          int
+		ReadingTimeMinutes     int
+		PublishedAt            time.Time
+	}
+
+	type FeedEntry struct {
+		GUID        string `gorm:"primaryKey"`
+		Framework   string `gorm:"index"`
+		Feed        string
+		Title       string
+		Link        string
+		Author      string
+		Content     string
+		PublishedAt time.Time
+	}
+
+	type MastodonStatus struct {
+		URI             string `gorm:"primaryKey"`
+		Framework       string `gorm:"index"`
+		Instance        string
+		Hashtag         string
+		Account         string
+		Content         string
+		URL             string
+		Language        string
+		ReblogsCount    int
+		FavouritesCount int
+		RepliesCount    int
+		CreatedAt       time.Time
+	}
+
+	type NpmDownload struct {
+		Package   string    `gorm:"primaryKey"`
+		Period    string    `gorm:"primaryKey"`
+		Date      time.Time `gorm:"primaryKey;type:date"`
+		Framework string    `gorm:"index"`
+		Downloads int64
+	}
+
+	type PyPIDownload struct {
+		Package   string    `gorm:"primaryKey"`
+		Date      time.Time `gorm:"primaryKey;type:date"`
+		Framework string    `gorm:"index"`
+		Downloads int64
+	}
+
+	type DockerHubSnapshot struct {
+		Image      string    `gorm:"primaryKey"`
+		Date       time.Time `gorm:"primaryKey;type:date"`
+		Framework  string    `gorm:"index"`
+		PullCount  int64
+		StarCount  int
+		RecordedAt time.Time
+	}
+
+	type GoModuleVersion struct {
+		Module      string `gorm:"primaryKey"`
+		Version     string `gorm:"primaryKey"`
+		Framework   string `gorm:"index"`
+		PublishedAt time.Time
+	}
+
+	type NVDVulnerability struct {
+		CVEID        string `gorm:"primaryKey"`
+		Framework    string `gorm:"primaryKey"`
+		Description  string
+		Status       string
+		CVSSVersion  string
+		BaseScore    float64
+		Severity     string
+		Vector       string
+		Published    time.Time
+		LastModified time.Time
+	}
+
+	type GitHubAdvisory struct {
+		GHSAID          string `gorm:"primaryKey"`
+		Framework       string `gorm:"primaryKey"`
+		Ecosystem       string `gorm:"primaryKey"`
+		Package         string `gorm:"primaryKey"`
+		Summary         string
+		Description     string
+		Severity        string
+		CVSSScore       float64
+		CVSSVector      string
+		CVEIDs          []string `gorm:"serializer:json"`
+		VulnerableRange string
+		PatchedVersion  string
+		Permalink       string
+		PublishedAt     time.Time
+		UpdatedAt       time.Time
+		WithdrawnAt     *time.Time
+	}
+
+	type LibrariesIOSnapshot struct {
+		Platform            string    `gorm:"primaryKey"`
+		Package             string    `gorm:"primaryKey"`
+		Date                time.Time `gorm:"primaryKey;type:date"`
+		Framework           string    `gorm:"index"`
+		DependentsCount     int
+		DependentReposCount int
+		SourceRank          int
+		LatestRelease       string
+		RecordedAt          time.Time
+	}
+
+	type BitbucketIssue struct {
+		Repo      string `gorm:"primaryKey"`
+		ID        int    `gorm:"primaryKey;autoIncrement:false"`
+		Framework string `gorm:"index"`
+		Title     string
+		Body      string
+		State     string
+		Kind      string
+		Priority  string
+		Reporter  string
+		Votes     int
+		WebURL    string
+		CreatedAt time.Time
+		UpdatedAt time.Time
+	}
+
+	type YouTubeVideo struct {
+		VideoID      string `gorm:"primaryKey"`
+		Framework    string `gorm:"index"`
+		Title        string
+		Description  string
+		ChannelID    string
+		ChannelTitle string
+		ViewCount    int64
+		LikeCount    int64
+		CommentCount int64
+		PublishedAt  time.Time
+	}
+
+	type Paper struct {
+		ArxivID    string `gorm:"primaryKey"`
+		Framework  string `gorm:"primaryKey"`
+		Query      string
+		Title      string
+		Abstract   string
+		Authors    []string `gorm:"serializer:json"`
+		Categories []string `gorm:"serializer:json"`
+		URL        string
+		PDFURL     string
+		Published  time.Time
+		Updated    time.Time
+	}
+
+	type LobstersStory struct {
+		ShortID      string `gorm:"primaryKey"`
+		Framework    string `gorm:"index"`
+		Title        string
+		URL          string
+		Description  string
+		Submitter    string
+		Tags         []string `gorm:"serializer:json"`
+		Score        int
+		CommentCount int
+		CommentsURL  string
+		CreatedAt    time.Time
+	}
+
+	return []interface{}{&StackOverflowPost{}, &GitHubIssue{}, &Framework{},
+		&RedditPost{}, &RedditComment{}, &HackerNewsItem{}, &GitLabIssue{},
+		&JiraIssue{}, &DiscourseTopic{}, &GitHubDiscussion{},
+		&GitHubPullRequest{}, &GitHubRelease{}, &GitHubRepoSnapshot{},
+		&GitHubCommitActivity{}, &GitHubContributor{}, &StackOverflowAnswer{},
+		&StackOverflowComment{}, &GitHubIssueComment{}, &DevToArticle{},
+		&FeedEntry{}, &MastodonStatus{}, &NpmDownload{},
+		&PyPIDownload{}, &DockerHubSnapshot{}, &GoModuleVersion{},
+		&NVDVulnerability{}, &GitHubAdvisory{}, &LibrariesIOSnapshot{},
+		&BitbucketIssue{}, &YouTubeVideo{}, &Paper{},
+		&LobstersStory{}}
+}
+
+// dropColumns drops fields of model along with any index on them.
+func dropColumns(tx *gorm.DB, model interface{}, fields ...string) error {
+	m := tx.Migrator()
+	for _, field := range fields {
+		if m.HasIndex(model, field) {
+			if err := m.DropIndex(model, field); err != nil {
+				return err
+			}
+		}
+		if !m.HasColumn(model, field) {
+			continue
+		}
+		if err := dropColumn(tx, model, field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dropColumn drops a single column. The sqlite migrator does so by
+// rebuilding the table, which loses its other indexes, so sqlite uses
+// ALTER TABLE DROP COLUMN, available since SQLite 3.35, instead.
+func dropColumn(tx *gorm.DB, model interface{}, field string) error {
+	if tx.Dialector.Name() != driverSQLite {
+		return tx.Migrator().DropColumn(model, field)
+	}
+	stmt := &gorm.Statement{DB: tx}
+	if err := stmt.Parse(model); err != nil {
+		return err
+	}
+	f := stmt.Schema.LookUpField(field)
+	if f == nil {
+		return fmt.Errorf("%s has no field %s", stmt.Schema.Name, field)
+	}
+	return tx.Exec("ALTER TABLE ? DROP COLUMN ?", clause.Table{Name: stmt.Table}, clause.Column{Name: f.DBName}).Error
+}
+
+// newMigrator runs the migrations against the primary database.
+func newMigrator(db *gorm.DB) *gormigrate.Gormigrate {
+	return gormigrate.New(primary(db), migrationOptions, migrations)
+}
+
+// migrateDatabase applies every pending migration.
+func migrateDatabase(db *gorm.DB) error {
+	if err := newMigrator(db).Migrate(); err != nil {
+		return fmt.Errorf("failed to migrate database: %w", err)
+	}
+	return nil
+}
+
+// rollbackDatabase reverts the last steps applied migrations.
+func rollbackDatabase(db *gorm.DB, steps int) error {
+	m := newMigrator(db)
+	for i := 0; i < steps; i++ {
+		if err := m.RollbackLast(); err != nil {
+			if errors.Is(err, gormigrate.ErrNoRunMigration) {
+				return nil
+			}
+			return fmt.Errorf("failed to roll back database: %w", err)
+		}
+	}
+	return nil
+}
+
+// migrationStatus reports, for every known migration in order, whether it
+// has been applied.
+func migrationStatus(db *gorm.DB) ([]string, map[string]bool, error) {
+	db = primary(db)
+	applied := map[string]bool{}
+	if db.Migrator().HasTable(migrationOptions.TableName) {
+		var ids []string
+		if err := db.Table(migrationOptions.TableName).Pluck(migrationOptions.IDColumnName, &ids).Error; err != nil {
+			return nil, nil, fmt.Errorf("reading %s: %w", migrationOptions.TableName, err)
+		}
+		for _, id := range ids {
+			applied[id] = true
+		}
+	}
+	ids := make([]string, len(migrations))
+	for i, m := range migrations {
+		ids[i] = m.ID
+	}
+	return ids, applied, nil
+}
@@ -0,0 +1,362 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"html"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	// milvusBatchSize is the number of documents embedded and inserted per
+	// round trip.
+	milvusBatchSize = 64
+	// embeddingMaxChars keeps inputs comfortably below the embedding
+	// models' token limits.
+	embeddingMaxChars = 8000
+)
+
+var htmlTagPattern = regexp.MustCompile(`<[^>]*>`)
+
+// MilvusConfig locates the Milvus collection holding embeddings of post
+// and issue bodies. Token is a Zilliz API key or a user:password pair. The
+// vector indexer and the similarity endpoint are disabled while URL is
+// empty.
+type MilvusConfig struct {
+	URL        string
+	Token      string
+	Collection string
+}
+
+func (m MilvusConfig) Enabled() bool {
+	return m.URL != ""
+}
+
+// EmbeddingConfig points at an OpenAI-compatible /embeddings endpoint.
+// Dimensions must match the model's output size.
+type EmbeddingConfig struct {
+	URL        string
+	APIKey     string
+	Model      string
+	Dimensions int
+}
+
+// vectorIndexer wraps a Store and additionally writes an embedding of
+// every searchable item into Milvus, in batches. Pending documents are
+// flushed on Close.
+type vectorIndexer struct {
+	Store
+	milvus    MilvusConfig
+	embedding EmbeddingConfig
+
+	mu      sync.Mutex
+	pending []searchDocument
+	ready   bool
+}
+
+func newVectorIndexer(milvus MilvusConfig, embedding EmbeddingConfig, next Store) *vectorIndexer {
+	return &vectorIndexer{Store: next, milvus: milvus, embedding: embedding}
+}
+
+func (v *vectorIndexer) Save(ctx context.Context, items ...Item) error {
+	if err := v.Store.Save(ctx, items...); err != nil {
+		return err
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	for _, item := range items {
+		doc, ok := item.(searchable)
+		if !ok {
+			continue
+		}
+		v.pending = append(v.pending, doc.searchDocument())
+		if len(v.pending) < milvusBatchSize {
+			continue
+		}
+		if err := v.flush(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (v *vectorIndexer) Close(ctx context.Context) error {
+	v.mu.Lock()
+	err := v.flush(ctx)
+	v.mu.Unlock()
+	if closeErr := v.Store.Close(ctx); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// flush embeds the pending documents and replaces their vectors in Milvus,
+// creating the collection first if needed. The caller must hold v.mu.
+func (v *vectorIndexer) flush(ctx context.Context) error {
+	if len(v.pending) == 0 {
+		return nil
+	}
+	docs := v.pending
+	v.pending = nil
+
+	if !v.ready {
+		if err := ensureMilvusCollection(ctx, v.milvus, v.embedding.Dimensions); err != nil {
+			return err
+		}
+		v.ready = true
+	}
+
+	inputs := make([]string, len(docs))
+	for i, doc := range docs {
+		inputs[i] = embeddingInput(doc.Title, doc.Body)
+	}
+	vectors, err := embed(ctx, v.embedding, inputs)
+	if err != nil {
+		return fmt.Errorf("embedding %d documents: %w", len(docs), err)
+	}
+
+	ids := make([]int64, len(docs))
+	rows := make([]map[string]interface{}, len(docs))
+	for i, doc := range docs {
+		ids[i] = milvusID(doc.ID)
+		rows[i] = map[string]interface{}{
+			"id":     ids[i],
+			"vector": vectors[i],
+			"kind":   doc.Kind,
+			"site":   doc.Site,
+			"ref":    doc.Ref,
+			"title":  doc.Title,
+		}
+	}
+
+	// Milvus has no upsert by primary key here, so stale vectors of
+	// re-fetched items are deleted before the insert.
+	if err := milvusDo(ctx, v.milvus, "/v1/vector/delete", map[string]interface{}{
+		"collectionName": v.milvus.Collection,
+		"id":             ids,
+	}, nil); err != nil {
+		return fmt.Errorf("deleting stale vectors: %w", err)
+	}
+	if err := milvusDo(ctx, v.milvus, "/v1/vector/insert", map[string]interface{}{
+		"collectionName": v.milvus.Collection,
+		"data":           rows,
+	}, nil); err != nil {
+		return fmt.Errorf("inserting %d vectors: %w", len(rows), err)
+	}
+	return nil
+}
+
+// ensureMilvusCollection creates the collection unless it already exists.
+// Fields other than id and vector are stored as dynamic fields.
+func ensureMilvusCollection(ctx context.Context, m MilvusConfig, dimensions int) error {
+	var collections []string
+	if err := milvusDo(ctx, m, "/v1/vector/collections", nil, &collections); err != nil {
+		return fmt.Errorf("listing Milvus collections: %w", err)
+	}
+	for _, name := range collections {
+		if name == m.Collection {
+			return nil
+		}
+	}
+	err := milvusDo(ctx, m, "/v1/vector/collections/create", map[string]interface{}{
+		"collectionName": m.Collection,
+		"dimension":      dimensions,
+		"metricType":     "COSINE",
+		"primaryField":   "id",
+		"vectorField":    "vector",
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("creating Milvus collection %s: %w", m.Collection, err)
+	}
+	return nil
+}
+
+// milvusID maps a search document ID to the collection's int64 primary
+// key.
+func milvusID(id string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(id))
+	return int64(h.Sum64() &^ (1 << 63))
+}
+
+// embeddingInput is the text embedded for a document: the title and the
+// body with HTML removed, truncated to embeddingMaxChars.
+func embeddingInput(title, body string) string {
+	text := title + "\n\n" + html.UnescapeString(htmlTagPattern.ReplaceAllString(body, " "))
+	text = strings.Join(strings.Fields(text), " ")
+	if len(text) > embeddingMaxChars {
+		text = strings.ToValidUTF8(text[:embeddingMaxChars], "")
+	}
+	return text
+}
+
+// embed returns one vector per input, in input order.
+func embed(ctx context.Context, cfg EmbeddingConfig, inputs []string) ([][]float32, error) {
+	payload, err := json.Marshal(map[string]interface{}{"model": cfg.Model, "input": inputs})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, cfg.URL, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if cfg.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+cfg.APIKey)
+	}
+
+	// Embeddings are not collected data, so this bypasses doRequest and
+	// with it the collected-bytes metric and response archival.
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading response from %s: %w", req.URL.Redacted(), err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("POST %s: status %d: %s", req.URL.Redacted(), resp.StatusCode, truncate(strings.TrimSpace(string(data)), 200))
+	}
+
+	var result struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("decoding response from %s: %w", req.URL.Redacted(), err)
+	}
+	if len(result.Data) != len(inputs) {
+		return nil, fmt.Errorf("got %d embeddings for %d inputs", len(result.Data), len(inputs))
+	}
+
+	vectors := make([][]float32, len(inputs))
+	for _, d := range result.Data {
+		if d.Index < 0 || d.Index >= len(inputs) {
+			return nil, fmt.Errorf("embedding index %d out of range", d.Index)
+		}
+		if len(d.Embedding) != cfg.Dimensions {
+			return nil, fmt.Errorf("embedding has %d dimensions, EMBEDDINGS_DIMENSIONS is %d", len(d.Embedding), cfg.Dimensions)
+		}
+		vectors[d.Index] = d.Embedding
+	}
+	return vectors, nil
+}
+
+// milvusDo calls the Milvus RESTful API, a POST when body is set and a GET
+// otherwise, and decodes the data field of the response into out. Milvus
+// reports failures in the response's code field rather than the HTTP
+// status.
+func milvusDo(ctx context.Context, m MilvusConfig, path string, body, out interface{}) error {
+	method := http.MethodGet
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		method, reader = http.MethodPost, bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(m.URL, "/")+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if m.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+m.Token)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("reading response from %s: %w", req.URL.Redacted(), err)
+	}
+	var result struct {
+		Code    int             `json:"code"`
+		Message string          `json:"message"`
+		Data    json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return fmt.Errorf("%s %s: status %d: %s", method, req.URL.Redacted(), resp.StatusCode, truncate(strings.TrimSpace(string(data)), 200))
+	}
+	if result.Code != 0 && result.Code != 200 {
+		return fmt.Errorf("%s %s: Milvus code %d: %s", method, req.URL.Redacted(), result.Code, result.Message)
+	}
+	if out == nil || len(result.Data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(result.Data, out)
+}
+
+// similarResult is a stored post or issue near the query text; Distance is
+// the cosine similarity, higher being closer.
+type similarResult struct {
+	Kind     string  `json:"kind"`
+	Site     string  `json:"site,omitempty"`
+	Ref      int     `json:"ref"`
+	Title    string  `json:"title"`
+	Distance float64 `json:"distance"`
+}
+
+// similarHandler serves GET /api/v1/search/similar?q=, returning the posts and
+// issues whose embeddings are nearest to that of q. kind=post|issue
+// narrows the results.
+func similarHandler(store *configStore) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		cfg := store.Get()
+		if !cfg.Milvus.Enabled() {
+			return fiber.NewError(fiber.StatusServiceUnavailable, "similarity search requires MILVUS_URL")
+		}
+		q := strings.TrimSpace(c.Query("q"))
+		if q == "" {
+			return fiber.NewError(fiber.StatusBadRequest, "q is required")
+		}
+		kind := c.Query("kind")
+		if kind != "" && kind != "post" && kind != "issue" {
+			return fiber.NewError(fiber.StatusBadRequest, "kind must be post or issue")
+		}
+		limit, err := strconv.Atoi(c.Query("limit", "10"))
+		if err != nil || limit < 1 || limit > 100 {
+			return fiber.NewError(fiber.StatusBadRequest, "limit must be between 1 and 100")
+		}
+
+		vectors, err := embed(c.UserContext(), cfg.Embedding, []string{embeddingInput(q, "")})
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadGateway, "embedding query: "+err.Error())
+		}
+		request := map[string]interface{}{
+			"collectionName": cfg.Milvus.Collection,
+			"vector":         vectors[0],
+			"limit":          limit,
+			"outputFields":   []string{"kind", "site", "ref", "title"},
+		}
+		if kind != "" {
+			request["filter"] = fmt.Sprintf("kind == %q", kind)
+		}
+
+		results := []similarResult{}
+		if err := milvusDo(c.UserContext(), cfg.Milvus, "/v1/vector/search", request, &results); err != nil {
+			return fiber.NewError(fiber.StatusBadGateway, err.Error())
+		}
+		return c.JSON(results)
+	}
+}
@@ -0,0 +1,435 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoDBConfig locates the document store used when STORAGE_BACKEND is
+// mongodb.
+type MongoDBConfig struct {
+	URI      string
+	Database string
+}
+
+// document is implemented by items that the MongoDB backend stores as
+// documents rather than rows.
+type document interface {
+	Item
+	mongoDocument() (collection string, id string, doc bson.M)
+}
+
+// mongoStore keeps StackOverflow posts and GitHub issues, together with
+// the API payloads they were parsed from, in MongoDB. The framework
+// registry and any other item are handled by fallback.
+type mongoStore struct {
+	client   *mongo.Client
+	db       *mongo.Database
+	fallback Store
+}
+
+func newMongoStore(ctx context.Context, cfg MongoDBConfig, fallback Store) (*mongoStore, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(cfg.URI))
+	if err != nil {
+		return nil, fmt.Errorf("connecting to MongoDB: %w", err)
+	}
+	if err := client.Ping(ctx, nil); err != nil {
+		client.Disconnect(context.Background())
+		return nil, fmt.Errorf("connecting to MongoDB: %w", err)
+	}
+	return &mongoStore{client: client, db: client.Database(cfg.Database), fallback: fallback}, nil
+}
+
+func (s *mongoStore) Frameworks(ctx context.Context) ([]Framework, error) {
+	return s.fallback.Frameworks(ctx)
+}
+
+// Save replaces the stored copy of each document, inserting it if it is
+// new.
+func (s *mongoStore) Save(ctx context.Context, items ...Item) error {
+	var rest []Item
+	for _, item := range items {
+		d, ok := item.(document)
+		if !ok {
+			rest = append(rest, item)
+			continue
+		}
+
+		collection, id, doc := d.mongoDocument()
+		doc["fetched_at"] = time.Now().UTC()
+		_, err := s.db.Collection(collection).ReplaceOne(ctx, bson.M{"_id": id}, doc, options.Replace().SetUpsert(true))
+		if err != nil {
+			return fmt.Errorf("storing %s %s: %w", collection, id, err)
+		}
+	}
+	if len(rest) == 0 {
+		return nil
+	}
+	return s.fallback.Save(ctx, rest...)
+}
+
+func (s *mongoStore) ListPosts(ctx context.Context, filter PostFilter) ([]StackOverflowPost, int64, error) {
+	if filter.After != nil {
+		return nil, 0, errCursorUnsupported
+	}
+	query := mongoTextFilter(filter.Text)
+	if filter.Site != "" {
+		query["site"] = filter.Site
+	}
+	if filter.Framework != "" {
+		query["framework"] = mongoEqualFold(filter.Framework)
+	}
+	if filter.Tag != "" {
+		query["tags"] = filter.Tag
+	}
+	mongoTimeRange(query, "creation_date", filter.TimeRange)
+	var docs []struct {
+		QuestionID   int       `bson:"question_id"`
+		Site         string    `bson:"site"`
+		Framework    string    `bson:"framework"`
+		Title        string    `bson:"title"`
+		Body         string    `bson:"body"`
+		Score        int       `bson:"score"`
+		Tags         []string  `bson:"tags"`
+		CreationDate time.Time `bson:"creation_date"`
+	}
+	total, err := s.find(ctx, "stackoverflow_posts", query, mongoSort(postSortFields, filter.Sort), filter.Limit, filter.Offset, &docs)
+	if err != nil {
+		return nil, 0, err
+	}
+	posts := make([]StackOverflowPost, 0, len(docs))
+	for _, doc := range docs {
+		post := StackOverflowPost{QuestionID: doc.QuestionID, Site: doc.Site, Framework: doc.Framework,
+			Title: doc.Title, Body: doc.Body, Score: doc.Score, CreationDate: doc.CreationDate}
+		for _, name := range doc.Tags {
+			post.Tags = append(post.Tags, Tag{Name: name})
+		}
+		posts = append(posts, post)
+	}
+	return posts, total, nil
+}
+
+func (s *mongoStore) ListIssues(ctx context.Context, filter IssueFilter) ([]GitHubIssue, int64, error) {
+	if filter.After != nil {
+		return nil, 0, errCursorUnsupported
+	}
+	query := mongoTextFilter(filter.Text)
+	if filter.Repo != "" {
+		query["repo"] = mongoEqualFold(filter.Repo)
+	}
+	if filter.State != "" {
+		query["state"] = filter.State
+	}
+	if filter.Label != "" {
+		query["labels"] = filter.Label
+	}
+	mongoTimeRange(query, "opened_at", filter.TimeRange)
+	var docs []struct {
+		ID        string     `bson:"_id"`
+		Number    int        `bson:"number"`
+		Title     string     `bson:"title"`
+		Body      string     `bson:"body"`
+		Labels    []string   `bson:"labels"`
+		Comments  int        `bson:"comments"`
+		Reactions int        `bson:"reactions"`
+		State     string     `bson:"state"`
+		Author    string     `bson:"author"`
+		Assignees []string   `bson:"assignees"`
+		Framework string     `bson:"framework"`
+		Repo      string     `bson:"repo"`
+		OpenedAt  time.Time  `bson:"opened_at"`
+		UpdatedAt time.Time  `bson:"updated_at"`
+		ClosedAt  *time.Time `bson:"closed_at"`
+	}
+	total, err := s.find(ctx, "github_issues", query, mongoSort(issueSortFields, filter.Sort), filter.Limit, filter.Offset, &docs)
+	if err != nil {
+		return nil, 0, err
+	}
+	issues := make([]GitHubIssue, 0, len(docs))
+	for _, doc := range docs {
+		id, _ := strconv.Atoi(doc.ID)
+		issues = append(issues, GitHubIssue{
+			ID:        id,
+			Number:    doc.Number,
+			Title:     doc.Title,
+			Body:      doc.Body,
+			Labels:    doc.Labels,
+			Comments:  doc.Comments,
+			Reactions: doc.Reactions,
+			State:     doc.State,
+			Author:    doc.Author,
+			Assignees: doc.Assignees,
+			Framework: doc.Framework,
+			Repo:      doc.Repo,
+
+			IssueCreatedAt: doc.OpenedAt,
+			IssueUpdatedAt: doc.UpdatedAt,
+			ClosedAt:       doc.ClosedAt,
+		})
+	}
+	return issues, total, nil
+}
+
+// ListTags counts the posts carrying each tag.
+func (s *mongoStore) ListTags(ctx context.Context, filter TagFilter) ([]TagCount, error) {
+	match := bson.M{}
+	if filter.Site != "" {
+		match["site"] = filter.Site
+	}
+	if filter.Framework != "" {
+		match["framework"] = mongoEqualFold(filter.Framework)
+	}
+	mongoTimeRange(match, "creation_date", filter.TimeRange)
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$unwind", Value: "$tags"}},
+		{{Key: "$group", Value: bson.M{"_id": "$tags", "posts": bson.M{"$sum": 1}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "posts", Value: -1}, {Key: "_id", Value: 1}}}},
+	}
+	if filter.Limit > 0 {
+		pipeline = append(pipeline, bson.D{{Key: "$limit", Value: filter.Limit}})
+	}
+
+	cursor, err := s.db.Collection("stackoverflow_posts").Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("counting tags: %w", err)
+	}
+	var counts []struct {
+		Name  string `bson:"_id"`
+		Posts int64  `bson:"posts"`
+	}
+	if err := cursor.All(ctx, &counts); err != nil {
+		return nil, fmt.Errorf("counting tags: %w", err)
+	}
+	tags := make([]TagCount, 0, len(counts))
+	for _, c := range counts {
+		tags = append(tags, TagCount{Name: c.Name, Posts: c.Posts})
+	}
+	return tags, nil
+}
+
+// Document fields the listings sort by. Documents are replaced on every
+// fetch, so the time they were first collected is not kept.
+var (
+	postSortFields  = map[string]string{"score": "score", "created_at": "fetched_at", "updated_at": "fetched_at", "creation_date": "creation_date"}
+	issueSortFields = map[string]string{"score": "reactions", "created_at": "opened_at", "updated_at": "updated_at"}
+)
+
+// mongoSort returns the sort document of order, most recently fetched
+// first by default.
+func mongoSort(fields map[string]string, order SortOrder) bson.D {
+	field, ok := fields[order.Field]
+	if !ok {
+		return bson.D{{Key: "fetched_at", Value: -1}, {Key: "_id", Value: 1}}
+	}
+	direction := -1
+	if order.Ascending {
+		direction = 1
+	}
+	return bson.D{{Key: field, Value: direction}, {Key: "_id", Value: 1}}
+}
+
+// find decodes one page of the documents in collection matching query,
+// sorted by sort, into out and returns the total number of matches.
+func (s *mongoStore) find(ctx context.Context, collection string, query bson.M, sort bson.D, limit, offset int, out interface{}) (int64, error) {
+	coll := s.db.Collection(collection)
+	total, err := coll.CountDocuments(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("counting %s: %w", collection, err)
+	}
+	opts := options.Find().
+		SetSort(sort).
+		SetSkip(int64(offset)).
+		SetProjection(bson.M{"raw": 0})
+	if limit > 0 {
+		opts.SetLimit(int64(limit))
+	}
+	cursor, err := coll.Find(ctx, query, opts)
+	if err != nil {
+		return 0, fmt.Errorf("querying %s: %w", collection, err)
+	}
+	if err := cursor.All(ctx, out); err != nil {
+		return 0, fmt.Errorf("reading %s: %w", collection, err)
+	}
+	return total, nil
+}
+
+// mongoTextFilter matches text case-insensitively in the title or body.
+func mongoTextFilter(text string) bson.M {
+	if text == "" {
+		return bson.M{}
+	}
+	pattern := primitive.Regex{Pattern: regexp.QuoteMeta(text), Options: "i"}
+	return bson.M{"$or": bson.A{bson.M{"title": pattern}, bson.M{"body": pattern}}}
+}
+
+// ListRuns lists the fetch runs, which are kept by fallback.
+func (s *mongoStore) ListRuns(ctx context.Context, filter RunFilter) ([]FetchRun, int64, error) {
+	return s.fallback.ListRuns(ctx, filter)
+}
+
+// FrameworkStats counts the posts and issues stored in MongoDB, and takes
+// everything else from fallback.
+func (s *mongoStore) FrameworkStats(ctx context.Context, period TimeRange) (map[string]*FrameworkStats, error) {
+	stats, err := s.fallback.FrameworkStats(ctx, period)
+	if err != nil {
+		return nil, err
+	}
+	created := map[string]string{"stackoverflow_posts": "creation_date", "github_issues": "opened_at"}
+	for _, collection := range []string{"stackoverflow_posts", "github_issues"} {
+		match := bson.M{}
+		mongoTimeRange(match, created[collection], period)
+		cursor, err := s.db.Collection(collection).Aggregate(ctx, mongo.Pipeline{
+			{{Key: "$match", Value: match}},
+			{{Key: "$group", Value: bson.M{"_id": "$framework", "n": bson.M{"$sum": 1}}}},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("counting %s: %w", collection, err)
+		}
+		var counts []struct {
+			Framework string `bson:"_id"`
+			N         int64  `bson:"n"`
+		}
+		if err := cursor.All(ctx, &counts); err != nil {
+			return nil, fmt.Errorf("counting %s: %w", collection, err)
+		}
+		for _, c := range counts {
+			if stats[c.Framework] == nil {
+				stats[c.Framework] = &FrameworkStats{Framework: c.Framework}
+			}
+			if collection == "stackoverflow_posts" {
+				stats[c.Framework].Posts = c.N
+			} else {
+				stats[c.Framework].Issues = c.N
+			}
+		}
+	}
+	return stats, nil
+}
+
+func (s *mongoStore) Search(ctx context.Context, filter SearchFilter) (searchResponse, error) {
+	return searchByListing(ctx, s, filter)
+}
+
+// mongoTimeRange restricts query to the documents whose field falls in r.
+func mongoTimeRange(query bson.M, field string, r TimeRange) {
+	bounds := bson.M{}
+	if !r.From.IsZero() {
+		bounds["$gte"] = r.From
+	}
+	if !r.To.IsZero() {
+		bounds["$lt"] = r.To
+	}
+	if len(bounds) > 0 {
+		query[field] = bounds
+	}
+}
+
+// mongoEqualFold matches values equal to value ignoring case.
+func mongoEqualFold(value string) primitive.Regex {
+	return primitive.Regex{Pattern: "^" + regexp.QuoteMeta(value) + "$", Options: "i"}
+}
+
+// PurgePosts deletes the matching post documents.
+func (s *mongoStore) PurgePosts(ctx context.Context, filter PurgeFilter) (int64, error) {
+	return s.purge(ctx, "stackoverflow_posts", "creation_date", filter)
+}
+
+// PurgeIssues deletes the matching issue documents.
+func (s *mongoStore) PurgeIssues(ctx context.Context, filter PurgeFilter) (int64, error) {
+	return s.purge(ctx, "github_issues", "opened_at", filter)
+}
+
+func (s *mongoStore) purge(ctx context.Context, collection, createdField string, filter PurgeFilter) (int64, error) {
+	query := bson.M{}
+	if filter.Framework != "" {
+		query["framework"] = mongoEqualFold(filter.Framework)
+	}
+	if !filter.Before.IsZero() {
+		query[createdField] = bson.M{"$lt": filter.Before}
+	}
+	result, err := s.db.Collection(collection).DeleteMany(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("purging %s: %w", collection, err)
+	}
+	return result.DeletedCount, nil
+}
+
+func (s *mongoStore) Ping(ctx context.Context) error {
+	if err := s.client.Ping(ctx, nil); err != nil {
+		return err
+	}
+	return s.fallback.Ping(ctx)
+}
+
+func (s *mongoStore) Close(ctx context.Context) error {
+	err := s.client.Disconnect(ctx)
+	if closeErr := s.fallback.Close(ctx); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func (post StackOverflowPost) mongoDocument() (string, string, bson.M) {
+	id := fmt.Sprintf("%s/%d", post.Site, post.QuestionID)
+	return "stackoverflow_posts", id, bson.M{
+		"_id":           id,
+		"question_id":   post.QuestionID,
+		"site":          post.Site,
+		"framework":     post.Framework,
+		"title":         post.Title,
+		"body":          post.Body,
+		"score":         post.Score,
+		"tags":          post.tagNames(),
+		"creation_date": post.CreationDate,
+		"raw":           rawDocument(post.Raw),
+	}
+}
+
+func (issue GitHubIssue) mongoDocument() (string, string, bson.M) {
+	id := fmt.Sprint(issue.ID)
+	return "github_issues", id, bson.M{
+		"_id":        id,
+		"number":     issue.Number,
+		"title":      issue.Title,
+		"body":       issue.Body,
+		"labels":     issue.Labels,
+		"comments":   issue.Comments,
+		"reactions":  issue.Reactions,
+		"state":      issue.State,
+		"author":     issue.Author,
+		"assignees":  issue.Assignees,
+		"framework":  issue.Framework,
+		"repo":       issue.Repo,
+		"opened_at":  issue.IssueCreatedAt,
+		"updated_at": issue.IssueUpdatedAt,
+		"closed_at":  issue.ClosedAt,
+		"raw":        rawDocument(issue.Raw),
+	}
+}
+
+// rawDocument converts an API payload to a BSON document so that it is
+// embedded as a queryable subdocument rather than a string. It returns nil
+// when there is no payload or it is not a JSON object.
+func rawDocument(raw json.RawMessage) bson.D {
+	if len(raw) == 0 {
+		return nil
+	}
+	var doc bson.D
+	if err := bson.UnmarshalExtJSON(raw, false, &doc); err != nil {
+		return nil
+	}
+	return doc
+}
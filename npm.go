@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"gorm.io/gorm"
+)
+
+var npmAPICalls = promauto.NewCounter(prometheus.CounterOpts{
+	Name: "myapp_npm_api_calls_total",
+	Help: "Total number of API calls to the npm downloads API",
+})
+
+const (
+	npmPeriodDay  = "day"
+	npmPeriodWeek = "week"
+)
+
+// NpmDownload is the download count of an npm package for one period.
+// Daily rows cover the last month; weekly rows are keyed by the first day
+// of the week they cover.
+type NpmDownload struct {
+	Package   string    `json:"package" gorm:"primaryKey"`
+	Period    string    `json:"period" gorm:"primaryKey"`
+	Date      time.Time `json:"date" gorm:"primaryKey;type:date"`
+	Framework string    `json:"framework" gorm:"index"`
+	Downloads int64     `json:"downloads"`
+}
+
+// fetchNpmDownloads returns the daily download counts for the last month
+// and the total for the last week of the framework's npm package.
+func fetchNpmDownloads(ctx context.Context, framework Framework) ([]NpmDownload, error) {
+	if framework.NpmPackage == "" {
+		return nil, nil
+	}
+
+	npmAPICalls.Inc()
+	var daily struct {
+		Downloads []struct {
+			Day       string `json:"day"`
+			Downloads int64  `json:"downloads"`
+		} `json:"downloads"`
+	}
+	if err := getJSON(ctx, fmt.Sprintf("https://api.npmjs.org/downloads/range/last-month/%s", framework.NpmPackage), nil, &daily); err != nil {
+		return nil, err
+	}
+
+	downloads := make([]NpmDownload, 0, len(daily.Downloads)+1)
+	for _, d := range daily.Downloads {
+		day, err := time.Parse(time.DateOnly, d.Day)
+		if err != nil {
+			return downloads, fmt.Errorf("parsing npm download day %q: %w", d.Day, err)
+		}
+		downloads = append(downloads, NpmDownload{
+			Package:   framework.NpmPackage,
+			Period:    npmPeriodDay,
+			Date:      day,
+			Framework: framework.Name,
+			Downloads: d.Downloads,
+		})
+	}
+
+	npmAPICalls.Inc()
+	var weekly struct {
+		Downloads int64  `json:"downloads"`
+		Start     string `json:"start"`
+	}
+	if err := getJSON(ctx, fmt.Sprintf("https://api.npmjs.org/downloads/point/last-week/%s", framework.NpmPackage), nil, &weekly); err != nil {
+		return downloads, err
+	}
+	start, err := time.Parse(time.DateOnly, weekly.Start)
+	if err != nil {
+		return downloads, fmt.Errorf("parsing npm download week %q: %w", weekly.Start, err)
+	}
+	downloads = append(downloads, NpmDownload{
+		Package:   framework.NpmPackage,
+		Period:    npmPeriodWeek,
+		Date:      start,
+		Framework: framework.Name,
+		Downloads: weekly.Downloads,
+	})
+	return downloads, nil
+}
+
+func init() {
+	registerSource(func(cfg *Config) Source { return npmSource{cfg} })
+}
+
+// npmSource collects download counts of a framework's npm package.
+type npmSource struct{ cfg *Config }
+
+func (npmSource) Name() string { return "npm" }
+
+func (npmSource) Fetch(ctx context.Context, framework Framework) ([]Item, error) {
+	downloads, err := fetchNpmDownloads(ctx, framework)
+	return asItems(downloads), err
+}
+
+func (download NpmDownload) Save(db *gorm.DB) error {
+	return upsert(db, &download)
+}
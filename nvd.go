@@ -0,0 +1,192 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"gorm.io/gorm"
+)
+
+var nvdAPICalls = promauto.NewCounter(prometheus.CounterOpts{
+	Name: "myapp_nvd_api_calls_total",
+	Help: "Total number of API calls to the NVD CVE API",
+})
+
+const nvdCVEURL = "https://services.nvd.nist.gov/rest/json/cves/2.0"
+
+// nvdTimeLayout is the zone-less UTC timestamp format used by the NVD API.
+const nvdTimeLayout = "2006-01-02T15:04:05.000"
+
+// NVDVulnerability is a CVE whose description mentions a framework. A CVE
+// can match several frameworks, so it is keyed by both.
+type NVDVulnerability struct {
+	CVEID        string    `json:"cve_id" gorm:"primaryKey"`
+	Framework    string    `json:"framework" gorm:"primaryKey"`
+	Description  string    `json:"description"`
+	Status       string    `json:"status"`
+	CVSSVersion  string    `json:"cvss_version"`
+	BaseScore    float64   `json:"base_score"`
+	Severity     string    `json:"severity"`
+	Vector       string    `json:"vector"`
+	Published    time.Time `json:"published"`
+	LastModified time.Time `json:"last_modified"`
+}
+
+// nvdThrottle spaces out NVD requests to stay within the public rate limit
+// of 5 requests per 30 seconds, or 50 with an API key.
+var nvdThrottle struct {
+	sync.Mutex
+	last time.Time
+}
+
+func waitNVD(ctx context.Context, apiKey string) error {
+	interval := 6 * time.Second
+	if apiKey != "" {
+		interval = 600 * time.Millisecond
+	}
+
+	nvdThrottle.Lock()
+	defer nvdThrottle.Unlock()
+	if wait := time.Until(nvdThrottle.last.Add(interval)); wait > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+	nvdThrottle.last = time.Now()
+	return nil
+}
+
+// nvdKeyword is the term searched for in CVE descriptions. Short or
+// ambiguous names (e.g. "Go") can be overridden in the registry.
+func nvdKeyword(framework Framework) string {
+	if framework.NVDKeyword != "" {
+		return framework.NVDKeyword
+	}
+	return framework.Name
+}
+
+func init() {
+	registerSource(func(cfg *Config) Source { return nvdSource{cfg} })
+}
+
+// nvdSource collects CVEs whose descriptions match a framework.
+type nvdSource struct{ cfg *Config }
+
+func (nvdSource) Name() string { return "nvd" }
+
+func (s nvdSource) Fetch(ctx context.Context, framework Framework) ([]Item, error) {
+	vulns, err := fetchNVDVulnerabilities(ctx, s.cfg, framework)
+	return asItems(vulns), err
+}
+
+type nvdResponse struct {
+	TotalResults    int `json:"totalResults"`
+	Vulnerabilities []struct {
+		CVE struct {
+			ID           string `json:"id"`
+			Status       string `json:"vulnStatus"`
+			Published    string `json:"published"`
+			LastModified string `json:"lastModified"`
+			Descriptions []struct {
+				Lang  string `json:"lang"`
+				Value string `json:"value"`
+			} `json:"descriptions"`
+			Metrics map[string][]struct {
+				BaseSeverity string `json:"baseSeverity"`
+				CVSSData     struct {
+					Version      string  `json:"version"`
+					BaseScore    float64 `json:"baseScore"`
+					BaseSeverity string  `json:"baseSeverity"`
+					VectorString string  `json:"vectorString"`
+				} `json:"cvssData"`
+			} `json:"metrics"`
+		} `json:"cve"`
+	} `json:"vulnerabilities"`
+}
+
+// fetchNVDVulnerabilities returns the most recently published
+// cfg.FetchLimit CVEs matching the framework. NVD lists results oldest
+// first, so the total is looked up before requesting the last page.
+func fetchNVDVulnerabilities(ctx context.Context, cfg *Config, framework Framework) ([]NVDVulnerability, error) {
+	query := url.Values{
+		"keywordSearch":  {nvdKeyword(framework)},
+		"resultsPerPage": {"1"},
+	}
+	var probe nvdResponse
+	if err := getNVD(ctx, cfg, query, &probe); err != nil {
+		return nil, err
+	}
+	if probe.TotalResults == 0 {
+		return nil, nil
+	}
+
+	start := probe.TotalResults - cfg.FetchLimit
+	if start < 0 {
+		start = 0
+	}
+	query.Set("resultsPerPage", strconv.Itoa(cfg.FetchLimit))
+	query.Set("startIndex", strconv.Itoa(start))
+	var result nvdResponse
+	if err := getNVD(ctx, cfg, query, &result); err != nil {
+		return nil, err
+	}
+
+	vulns := make([]NVDVulnerability, 0, len(result.Vulnerabilities))
+	for _, v := range result.Vulnerabilities {
+		vuln := NVDVulnerability{
+			CVEID:     v.CVE.ID,
+			Framework: framework.Name,
+			Status:    v.CVE.Status,
+		}
+		vuln.Published, _ = time.Parse(nvdTimeLayout, v.CVE.Published)
+		vuln.LastModified, _ = time.Parse(nvdTimeLayout, v.CVE.LastModified)
+		for _, d := range v.CVE.Descriptions {
+			if d.Lang == "en" {
+				vuln.Description = d.Value
+				break
+			}
+		}
+		// Prefer the newest CVSS version NVD scored the CVE with.
+		for _, key := range []string{"cvssMetricV40", "cvssMetricV31", "cvssMetricV30", "cvssMetricV2"} {
+			if metrics := v.CVE.Metrics[key]; len(metrics) > 0 {
+				m := metrics[0]
+				vuln.CVSSVersion = m.CVSSData.Version
+				vuln.BaseScore = m.CVSSData.BaseScore
+				vuln.Vector = m.CVSSData.VectorString
+				vuln.Severity = m.CVSSData.BaseSeverity
+				if vuln.Severity == "" {
+					vuln.Severity = m.BaseSeverity
+				}
+				break
+			}
+		}
+		vulns = append(vulns, vuln)
+	}
+	return vulns, nil
+}
+
+func getNVD(ctx context.Context, cfg *Config, query url.Values, out interface{}) error {
+	if err := waitNVD(ctx, cfg.NVDAPIKey); err != nil {
+		return err
+	}
+	nvdAPICalls.Inc()
+
+	var header http.Header
+	if cfg.NVDAPIKey != "" {
+		header = http.Header{"apiKey": {cfg.NVDAPIKey}}
+	}
+	return getJSON(ctx, fmt.Sprintf("%s?%s", nvdCVEURL, query.Encode()), header, out)
+}
+
+func (vuln NVDVulnerability) Save(db *gorm.DB) error {
+	return upsert(db, &vuln)
+}
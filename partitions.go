@@ -0,0 +1,370 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Values of DB_PARTITION_BY.
+const (
+	partitionByMonth     = "month"
+	partitionByFramework = "framework"
+)
+
+// partitionedTable is a table that DB_PARTITION_BY applies to. Postgres
+// requires the unique keys of a partitioned table to contain its partition
+// column, so the column is added to the table's primary key and unique
+// indexes, and to the conflict target of upserts into it. A post collected
+// for two frameworks is therefore stored once per framework when
+// partitioning by framework.
+type partitionedTable struct {
+	Name  string
+	Model interface{}
+	// MonthColumn is the timestamp partitioned on by month. It is the
+	// source's own creation time, which does not change between fetches.
+	MonthColumn string
+}
+
+var partitionedTables = []partitionedTable{
+	{Name: "stack_overflow_posts", Model: &StackOverflowPost{}, MonthColumn: "creation_date"},
+	{Name: "git_hub_issues", Model: &GitHubIssue{}, MonthColumn: "issue_created_at"},
+}
+
+func (t partitionedTable) column(scheme string) string {
+	switch scheme {
+	case partitionByMonth:
+		return t.MonthColumn
+	case partitionByFramework:
+		return "framework"
+	}
+	return ""
+}
+
+// method is the partitioning method used for scheme.
+func (t partitionedTable) method(scheme string) string {
+	if scheme == partitionByMonth {
+		return "RANGE"
+	}
+	return "LIST"
+}
+
+// keyDef is the table's partition key under scheme as pg_get_partkeydef
+// reports it, e.g. "RANGE (creation_date)".
+func (t partitionedTable) keyDef(scheme string) string {
+	return fmt.Sprintf("%s (%s)", t.method(scheme), t.column(scheme))
+}
+
+// partitionedItem is implemented by the items stored in a partitioned
+// table.
+type partitionedItem interface {
+	Item
+	partitionKey() (table string, created time.Time, framework string)
+}
+
+func (post StackOverflowPost) partitionKey() (string, time.Time, string) {
+	return "stack_overflow_posts", post.CreationDate, post.Framework
+}
+
+func (issue GitHubIssue) partitionKey() (string, time.Time, string) {
+	return "git_hub_issues", issue.IssueCreatedAt, issue.Framework
+}
+
+// partition is one partition of a table: a calendar month in UTC or the
+// rows of one framework.
+type partition struct {
+	Scheme    string
+	Table     string
+	Month     time.Time
+	Framework string
+}
+
+func newPartition(scheme, table string, created time.Time, framework string) partition {
+	created = created.UTC()
+	return partition{
+		Scheme:    scheme,
+		Table:     table,
+		Month:     time.Date(created.Year(), created.Month(), 1, 0, 0, 0, 0, time.UTC),
+		Framework: framework,
+	}
+}
+
+// name is the partition's table name, e.g. git_hub_issues_y2023m10. Framework
+// partitions carry a hash of the framework name, as names differing only in
+// punctuation would otherwise collide.
+func (p partition) name() string {
+	if p.Scheme == partitionByMonth {
+		return fmt.Sprintf("%s_y%04dm%02d", p.Table, p.Month.Year(), p.Month.Month())
+	}
+	var slug strings.Builder
+	for _, r := range strings.ToLower(p.Framework) {
+		if slug.Len() == 24 {
+			break
+		}
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			slug.WriteRune(r)
+		}
+	}
+	h := fnv.New32a()
+	h.Write([]byte(p.Framework))
+	return fmt.Sprintf("%s_%s_%08x", p.Table, slug.String(), h.Sum32())
+}
+
+// create creates the partition unless it exists. Postgres does not accept
+// bind parameters in DDL, so the bounds are written as literals.
+func (p partition) create(db *gorm.DB) error {
+	var bounds string
+	if p.Scheme == partitionByMonth {
+		bounds = fmt.Sprintf("FROM (%s) TO (%s)", sqlTimestamp(p.Month), sqlTimestamp(p.Month.AddDate(0, 1, 0)))
+	} else {
+		bounds = fmt.Sprintf("IN (%s)", sqlString(p.Framework))
+	}
+	return db.Exec("CREATE TABLE IF NOT EXISTS ? PARTITION OF ? FOR VALUES "+bounds,
+		clause.Table{Name: p.name()}, clause.Table{Name: p.Table}).Error
+}
+
+func sqlTimestamp(t time.Time) string {
+	return "'" + t.UTC().Format("2006-01-02 15:04:05") + "+00'"
+}
+
+func sqlString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// partitioner creates the partitions that items are about to be stored
+// in, remembering the ones it has already created.
+type partitioner struct {
+	scheme string
+
+	mu      sync.Mutex
+	created map[string]bool
+}
+
+func (p *partitioner) ensure(db *gorm.DB, items []Item) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, item := range items {
+		pi, ok := item.(partitionedItem)
+		if !ok {
+			continue
+		}
+		table, created, framework := pi.partitionKey()
+		part := newPartition(p.scheme, table, created, framework)
+		name := part.name()
+		if p.created[name] {
+			continue
+		}
+		if err := part.create(db); err != nil {
+			return fmt.Errorf("creating partition %s: %w", name, err)
+		}
+		p.created[name] = true
+	}
+	return nil
+}
+
+type partitioningKey struct{}
+
+// withPartitioning tells upsert that the partitioned tables are
+// partitioned by scheme.
+func withPartitioning(ctx context.Context, scheme string) context.Context {
+	return context.WithValue(ctx, partitioningKey{}, scheme)
+}
+
+// partitionColumn returns the partition column of table under the scheme
+// attached to ctx, or "" if the table is not partitioned.
+func partitionColumn(ctx context.Context, table string) string {
+	scheme, _ := ctx.Value(partitioningKey{}).(string)
+	for _, t := range partitionedTables {
+		if t.Name == table {
+			return t.column(scheme)
+		}
+	}
+	return ""
+}
+
+// partitionKeyDef returns the partition key of table, or "" if the table
+// is not partitioned.
+func partitionKeyDef(db *gorm.DB, table string) (string, error) {
+	var def string
+	err := db.Raw(`SELECT pg_get_partkeydef(c.oid) FROM pg_partitioned_table p
+JOIN pg_class c ON c.oid = p.partrelid
+WHERE c.relname = ? AND c.relnamespace = current_schema()::regnamespace`, table).Scan(&def).Error
+	if err != nil {
+		return "", fmt.Errorf("reading partition key of %s: %w", table, err)
+	}
+	return def, nil
+}
+
+// checkPartitioning verifies that the partitioned tables are partitioned
+// by scheme.
+func checkPartitioning(db *gorm.DB, scheme string) error {
+	for _, t := range partitionedTables {
+		def, err := partitionKeyDef(db, t.Name)
+		if err != nil {
+			return err
+		}
+		if def != t.keyDef(scheme) {
+			return fmt.Errorf("%s is not partitioned by %s; run migrate to partition it", t.Name, scheme)
+		}
+	}
+	return nil
+}
+
+// partitionTables converts the partitioned tables to Postgres declarative
+// partitioning by scheme, unless they already are. Each table is rebuilt
+// in a transaction: it is renamed, an empty partitioned copy is created
+// with a partition for every key present, and the rows are moved over.
+// Switching a table from one scheme to another is not supported.
+func partitionTables(db *gorm.DB, scheme string) error {
+	if scheme == "" {
+		return nil
+	}
+	db = primary(db)
+	for _, t := range partitionedTables {
+		def, err := partitionKeyDef(db, t.Name)
+		if err != nil {
+			return err
+		}
+		if def == t.keyDef(scheme) {
+			continue
+		}
+		if def != "" {
+			return fmt.Errorf("%s is already partitioned by %s; repartitioning is not supported", t.Name, def)
+		}
+		if err := db.Transaction(func(tx *gorm.DB) error { return partitionTable(tx, scheme, t) }); err != nil {
+			return fmt.Errorf("partitioning %s: %w", t.Name, err)
+		}
+	}
+	return nil
+}
+
+func partitionTable(tx *gorm.DB, scheme string, t partitionedTable) error {
+	table, old := clause.Table{Name: t.Name}, clause.Table{Name: t.Name + "_unpartitioned"}
+	column := clause.Column{Name: t.column(scheme)}
+
+	stmt := &gorm.Statement{DB: tx}
+	if err := stmt.Parse(t.Model); err != nil {
+		return err
+	}
+
+	// Rows stored before the partition column existed have it unset,
+	// which no partition accepts.
+	unset := sqlString("")
+	if scheme == partitionByMonth {
+		unset = sqlTimestamp(time.Time{})
+	}
+	if err := tx.Exec("UPDATE ? SET ? = "+unset+" WHERE ? IS NULL", table, column, column).Error; err != nil {
+		return err
+	}
+
+	// Indexes other than the unique ones are recreated as they were,
+	// including those created outside the model, such as the full-text
+	// search index.
+	var indexDefs []string
+	err := tx.Raw(`SELECT indexdef FROM pg_indexes
+WHERE schemaname = current_schema() AND tablename = ? AND indexdef NOT LIKE 'CREATE UNIQUE %'`, t.Name).Scan(&indexDefs).Error
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Exec("ALTER TABLE ? RENAME TO ?", table, old).Error; err != nil {
+		return err
+	}
+	err = tx.Exec("CREATE TABLE ? (LIKE ? INCLUDING DEFAULTS INCLUDING GENERATED) PARTITION BY "+t.method(scheme)+" (?)", table, old, column).Error
+	if err != nil {
+		return err
+	}
+
+	// Serial columns keep their sequence, which would otherwise be
+	// dropped along with the old table.
+	for _, field := range stmt.Schema.PrimaryFields {
+		var seq sql.NullString
+		if err := tx.Raw("SELECT pg_get_serial_sequence(?, ?)", old.Name, field.DBName).Row().Scan(&seq); err != nil {
+			return err
+		}
+		if seq.Valid {
+			if err := tx.Exec("ALTER SEQUENCE "+seq.String+" OWNED BY ?.?", table, clause.Column{Name: field.DBName}).Error; err != nil {
+				return err
+			}
+		}
+	}
+
+	var keys []string
+	if scheme == partitionByMonth {
+		err = tx.Raw("SELECT DISTINCT to_char(? AT TIME ZONE 'UTC', 'YYYY-MM') FROM ?", column, old).Scan(&keys).Error
+	} else {
+		err = tx.Raw("SELECT DISTINCT ? FROM ?", column, old).Scan(&keys).Error
+	}
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		part := partition{Scheme: scheme, Table: t.Name, Framework: key}
+		if scheme == partitionByMonth {
+			if part.Month, err = time.Parse("2006-01", key); err != nil {
+				return err
+			}
+		}
+		if err := part.create(tx); err != nil {
+			return fmt.Errorf("creating partition %s: %w", part.name(), err)
+		}
+	}
+
+	// Generated columns are computed again on insert.
+	var names []string
+	err = tx.Raw(`SELECT column_name FROM information_schema.columns
+WHERE table_schema = current_schema() AND table_name = ? AND is_generated = 'NEVER'
+ORDER BY ordinal_position`, old.Name).Scan(&names).Error
+	if err != nil {
+		return err
+	}
+	for i, name := range names {
+		names[i] = tx.Statement.Quote(name)
+	}
+	list := strings.Join(names, ", ")
+	if err := tx.Exec("INSERT INTO ? ("+list+") SELECT "+list+" FROM ?", table, old).Error; err != nil {
+		return err
+	}
+	if err := tx.Exec("DROP TABLE ?", old).Error; err != nil {
+		return err
+	}
+
+	for _, def := range indexDefs {
+		if err := tx.Exec(def).Error; err != nil {
+			return err
+		}
+	}
+	// Unique keys are recreated under the same name with the partition
+	// column added.
+	for _, idx := range stmt.Schema.ParseIndexes() {
+		if idx.Class != "UNIQUE" {
+			continue
+		}
+		var columns []interface{}
+		for _, field := range idx.Fields {
+			columns = append(columns, clause.Column{Name: field.DBName})
+		}
+		columns = append(columns, column)
+		if err := tx.Exec("CREATE UNIQUE INDEX ? ON ? ?", clause.Table{Name: idx.Name}, table, columns).Error; err != nil {
+			return err
+		}
+	}
+	if len(stmt.Schema.PrimaryFields) > 0 {
+		var columns []interface{}
+		for _, field := range stmt.Schema.PrimaryFields {
+			columns = append(columns, clause.Column{Name: field.DBName})
+		}
+		columns = append(columns, column)
+		if err := tx.Exec("ALTER TABLE ? ADD PRIMARY KEY ?", table, columns).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
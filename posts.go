@@ -0,0 +1,264 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// pageParams parses the ?page= and ?per_page= parameters of the paginated
+// listing endpoints. Pages are numbered from 1.
+func pageParams(c *fiber.Ctx) (page, perPage int, err error) {
+	page, err = strconv.Atoi(c.Query("page", "1"))
+	if err != nil || page < 1 {
+		return 0, 0, fiber.NewError(fiber.StatusBadRequest, "page must be a positive integer")
+	}
+	perPage, err = strconv.Atoi(c.Query("per_page", "50"))
+	if err != nil || perPage < 1 || perPage > 500 {
+		return 0, 0, fiber.NewError(fiber.StatusBadRequest, "per_page must be between 1 and 500")
+	}
+	return page, perPage, nil
+}
+
+// sortParams parses the ?sort= and ?order= parameters of a listing, sort
+// being one of keys and order asc or desc, the default. The default order
+// of a listing has no direction, so ?order= requires ?sort=.
+func sortParams(c *fiber.Ctx, keys ...string) (SortOrder, error) {
+	var order SortOrder
+	if field := c.Query("sort"); field != "" {
+		if !slices.Contains(keys, field) {
+			return order, fiber.NewError(fiber.StatusBadRequest, "sort must be one of "+strings.Join(keys, ", "))
+		}
+		order.Field = field
+	} else if c.Query("order") != "" {
+		return order, fiber.NewError(fiber.StatusBadRequest, "order requires sort")
+	}
+	switch c.Query("order", "desc") {
+	case "asc":
+		order.Ascending = true
+	case "desc":
+	default:
+		return order, fiber.NewError(fiber.StatusBadRequest, "order must be asc or desc")
+	}
+	return order, nil
+}
+
+// cursorParam parses the ?cursor= parameter of a listing, the next_cursor
+// of the page before, into after and reports whether one was given.
+// Cursors follow the listing's default order, so they cannot be combined
+// with ?page=, ?sort= or ?order=.
+func cursorParam(c *fiber.Ctx, after interface{}) (bool, error) {
+	cursor := c.Query("cursor")
+	if cursor == "" {
+		return false, nil
+	}
+	if c.Query("page") != "" || c.Query("sort") != "" || c.Query("order") != "" {
+		return false, fiber.NewError(fiber.StatusBadRequest, "cursor cannot be combined with page, sort or order")
+	}
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err == nil {
+		err = json.Unmarshal(data, after)
+	}
+	if err != nil {
+		return false, fiber.NewError(fiber.StatusBadRequest, "invalid cursor")
+	}
+	return true, nil
+}
+
+// encodeCursor returns the opaque next_cursor resuming a listing after
+// position, a PostCursor or an IssueCursor.
+func encodeCursor(position interface{}) string {
+	data, _ := json.Marshal(position)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// listError returns err, a failure of a listing, as the response to send:
+// a cursor the backend cannot resume from is the client's error.
+func listError(err error) error {
+	if errors.Is(err, errCursorUnsupported) {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	return err
+}
+
+// fieldsParam parses the ?fields= parameter of a listing of T, a
+// comma-separated list of the JSON fields to return, and returns nil when
+// it is absent so that the records are returned whole.
+func fieldsParam[T any](c *fiber.Ctx) ([]string, error) {
+	param := c.Query("fields")
+	if param == "" {
+		return nil, nil
+	}
+	var known []string
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			known = append(known, name)
+		}
+	}
+	var fields []string
+	for _, field := range strings.Split(param, ",") {
+		field = strings.TrimSpace(field)
+		if !slices.Contains(known, field) {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "fields must be a comma-separated list of "+strings.Join(known, ", "))
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// selectFields returns records as they are encoded, keeping only fields
+// unless fields is nil.
+func selectFields[T any](records []T, fields []string) (interface{}, error) {
+	if fields == nil {
+		return records, nil
+	}
+	selected := make([]map[string]json.RawMessage, len(records))
+	for i, record := range records {
+		data, err := json.Marshal(record)
+		if err != nil {
+			return nil, err
+		}
+		var all map[string]json.RawMessage
+		if err := json.Unmarshal(data, &all); err != nil {
+			return nil, err
+		}
+		selected[i] = make(map[string]json.RawMessage, len(fields))
+		for _, field := range fields {
+			if value, ok := all[field]; ok {
+				selected[i][field] = value
+			}
+		}
+	}
+	return selected, nil
+}
+
+// timeRangeParams parses the ?from= and ?to= RFC 3339 bounds of the
+// listing, export and stats endpoints; see TimeRange.
+func timeRangeParams(c *fiber.Ctx) (TimeRange, error) {
+	var period TimeRange
+	for _, bound := range []struct {
+		name string
+		t    *time.Time
+	}{{"from", &period.From}, {"to", &period.To}} {
+		value := c.Query(bound.name)
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return period, fiber.NewError(fiber.StatusBadRequest, bound.name+" must be an RFC 3339 time")
+		}
+		*bound.t = t
+	}
+	if !period.From.IsZero() && !period.To.IsZero() && !period.From.Before(period.To) {
+		return period, fiber.NewError(fiber.StatusBadRequest, "from must be before to")
+	}
+	return period, nil
+}
+
+// listPostsHandler serves GET /api/v1/posts, the stored StackOverflow
+// posts, most recently collected first unless ?sort= is score,
+// created_at, updated_at or creation_date, optionally filtered by
+// ?framework=, ?tag=, ?site= and when they were asked, ?from= and ?to=.
+// In the default order, a full page carries a next_cursor from which
+// ?cursor= resumes without the cost of skipping the earlier pages.
+// ?fields= trims the posts to the listed fields, such as
+// question_id,title, sparing clients the bodies they do not need.
+func listPostsHandler(records Store) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		page, perPage, err := pageParams(c)
+		if err != nil {
+			return err
+		}
+		sort, err := sortParams(c, "score", "created_at", "updated_at", "creation_date")
+		if err != nil {
+			return err
+		}
+		period, err := timeRangeParams(c)
+		if err != nil {
+			return err
+		}
+		fields, err := fieldsParam[StackOverflowPost](c)
+		if err != nil {
+			return err
+		}
+		filter := PostFilter{
+			Site:      c.Query("site"),
+			Framework: c.Query("framework"),
+			Tag:       c.Query("tag"),
+			TimeRange: period,
+			Sort:      sort,
+			Limit:     perPage,
+			Offset:    (page - 1) * perPage,
+		}
+		var after PostCursor
+		if resume, err := cursorParam(c, &after); err != nil {
+			return err
+		} else if resume {
+			filter.After = &after
+		}
+
+		posts, total, err := records.ListPosts(c.UserContext(), filter)
+		if err != nil {
+			return listError(err)
+		}
+		if posts == nil {
+			posts = []StackOverflowPost{}
+		}
+		var next interface{}
+		if sort.Field == "" && len(posts) == perPage {
+			last := posts[len(posts)-1]
+			next = encodeCursor(PostCursor{CreatedAt: last.CreatedAt, Site: last.Site, QuestionID: last.QuestionID})
+		}
+		selected, err := selectFields(posts, fields)
+		if err != nil {
+			return err
+		}
+		return c.JSON(fiber.Map{"total": total, "page": page, "per_page": perPage, "next_cursor": next, "posts": selected})
+	}
+}
+
+// purgeParams parses the ?framework= and ?before= filters of the purge
+// endpoints. One of them is required so that a bare DELETE does not wipe
+// the table; before is an RFC 3339 time or a date, taken as UTC midnight.
+func purgeParams(c *fiber.Ctx) (PurgeFilter, error) {
+	filter := PurgeFilter{Framework: c.Query("framework")}
+	if before := c.Query("before"); before != "" {
+		t, err := time.Parse(time.RFC3339, before)
+		if err != nil {
+			if t, err = time.Parse(time.DateOnly, before); err != nil {
+				return filter, fiber.NewError(fiber.StatusBadRequest, "before must be an RFC 3339 time or a YYYY-MM-DD date")
+			}
+		}
+		filter.Before = t
+	}
+	if filter.Framework == "" && filter.Before.IsZero() {
+		return filter, fiber.NewError(fiber.StatusBadRequest, "framework or before is required")
+	}
+	return filter, nil
+}
+
+// purgePostsHandler serves DELETE /api/v1/posts, removing the stored posts
+// of ?framework= or asked before ?before=, and reports how many it removed.
+func purgePostsHandler(records Store) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		filter, err := purgeParams(c)
+		if err != nil {
+			return err
+		}
+		purged, err := records.PurgePosts(c.UserContext(), filter)
+		if err != nil {
+			return err
+		}
+		return c.JSON(fiber.Map{"purged": purged})
+	}
+}
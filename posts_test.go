@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// listingStore records the filter of the last post listing.
+type listingStore struct {
+	Store
+	filter *PostFilter
+}
+
+func (s listingStore) ListPosts(ctx context.Context, filter PostFilter) ([]StackOverflowPost, int64, error) {
+	*s.filter = filter
+	return nil, 0, nil
+}
+
+func TestListPostsOrderRequiresSort(t *testing.T) {
+	var filter PostFilter
+	app := fiber.New()
+	app.Get("/posts", listPostsHandler(listingStore{filter: &filter}))
+
+	for _, tc := range []struct {
+		query     string
+		status    int
+		ascending bool
+	}{
+		{"order=asc", fiber.StatusBadRequest, false},
+		{"order=desc", fiber.StatusBadRequest, false},
+		{"sort=score&order=asc", fiber.StatusOK, true},
+		{"", fiber.StatusOK, false},
+	} {
+		filter = PostFilter{}
+		resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/posts?"+tc.query, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != tc.status {
+			t.Errorf("?%s: got status %d, want %d", tc.query, resp.StatusCode, tc.status)
+		}
+		if filter.Sort.Ascending != tc.ascending {
+			t.Errorf("?%s: got ascending %v, want %v", tc.query, filter.Sort.Ascending, tc.ascending)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+
+	"gorm.io/gorm/logger"
+)
+
+// Environment profiles selected by APP_ENV.
+const (
+	envDev     = "dev"
+	envStaging = "staging"
+	envProd    = "prod"
+)
+
+// Database drivers.
+const (
+	driverPostgres = "postgres"
+	driverMySQL    = "mysql"
+	driverSQLite   = "sqlite"
+)
+
+// Log levels, from most to least verbose.
+const (
+	logLevelDebug = "debug"
+	logLevelInfo  = "info"
+	logLevelWarn  = "warn"
+)
+
+// profile holds the per-environment defaults. Individual settings can still
+// be overridden through their own environment variables.
+type profile struct {
+	dbDriver   string
+	logLevel   string
+	fetchLimit int
+}
+
+var profiles = map[string]profile{
+	envDev:     {dbDriver: driverSQLite, logLevel: logLevelDebug, fetchLimit: 10},
+	envStaging: {dbDriver: driverPostgres, logLevel: logLevelInfo, fetchLimit: 50},
+	envProd:    {dbDriver: driverPostgres, logLevel: logLevelWarn, fetchLimit: 100},
+}
+
+func lookupProfile(env string) (profile, error) {
+	p, ok := profiles[env]
+	if !ok {
+		return profile{}, fmt.Errorf("unknown APP_ENV %q (expected %s, %s or %s)", env, envDev, envStaging, envProd)
+	}
+	return p, nil
+}
+
+// gormLogLevel maps the service log level onto gorm's SQL logger.
+func gormLogLevel(level string) logger.LogLevel {
+	switch level {
+	case logLevelDebug:
+		return logger.Info
+	case logLevelInfo:
+		return logger.Warn
+	default:
+		return logger.Error
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"gorm.io/gorm"
+)
+
+var pypiStatsAPICalls = promauto.NewCounter(prometheus.CounterOpts{
+	Name: "myapp_pypistats_api_calls_total",
+	Help: "Total number of API calls to pypistats.org",
+})
+
+// PyPIDownload is the daily download count of a PyPI package, excluding
+// mirrors.
+type PyPIDownload struct {
+	Package   string    `json:"package" gorm:"primaryKey"`
+	Date      time.Time `json:"date" gorm:"primaryKey;type:date"`
+	Framework string    `json:"framework" gorm:"index"`
+	Downloads int64     `json:"downloads"`
+}
+
+// fetchPyPIDownloads returns the daily downloads pypistats.org holds for
+// the framework's PyPI package, which covers roughly the last six months.
+func fetchPyPIDownloads(ctx context.Context, framework Framework) ([]PyPIDownload, error) {
+	if framework.PyPIPackage == "" {
+		return nil, nil
+	}
+	pypiStatsAPICalls.Inc()
+
+	var result struct {
+		Data []struct {
+			Category  string `json:"category"`
+			Date      string `json:"date"`
+			Downloads int64  `json:"downloads"`
+		} `json:"data"`
+	}
+	u := fmt.Sprintf("https://pypistats.org/api/packages/%s/overall?mirrors=false", url.PathEscape(framework.PyPIPackage))
+	if err := getJSON(ctx, u, nil, &result); err != nil {
+		return nil, err
+	}
+
+	downloads := make([]PyPIDownload, 0, len(result.Data))
+	for _, d := range result.Data {
+		if d.Category != "without_mirrors" {
+			continue
+		}
+		date, err := time.Parse(time.DateOnly, d.Date)
+		if err != nil {
+			return downloads, fmt.Errorf("parsing pypistats date %q: %w", d.Date, err)
+		}
+		downloads = append(downloads, PyPIDownload{
+			Package:   framework.PyPIPackage,
+			Date:      date,
+			Framework: framework.Name,
+			Downloads: d.Downloads,
+		})
+	}
+	return downloads, nil
+}
+
+func init() {
+	registerSource(func(cfg *Config) Source { return pypiSource{cfg} })
+}
+
+// pypiSource collects download counts of a framework's PyPI package.
+type pypiSource struct{ cfg *Config }
+
+func (pypiSource) Name() string { return "pypi" }
+
+func (pypiSource) Fetch(ctx context.Context, framework Framework) ([]Item, error) {
+	downloads, err := fetchPyPIDownloads(ctx, framework)
+	return asItems(downloads), err
+}
+
+func (download PyPIDownload) Save(db *gorm.DB) error {
+	return upsert(db, &download)
+}
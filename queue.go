@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"gorm.io/gorm"
+)
+
+// errFetchQueueFull rejects a collection pass while every worker is busy
+// and FETCH_QUEUE_SIZE passes are already waiting.
+var errFetchQueueFull = fiber.NewError(fiber.StatusServiceUnavailable, "the fetch queue is full; try again later")
+
+// queuedFetch is a collection pass waiting for a worker.
+type queuedFetch struct {
+	job   *fetchJob
+	cfg   *Config
+	scope fetchScope
+}
+
+// fetchQueue runs the collection passes started through the API, the gRPC
+// service and the scheduler on a fixed number of workers, so that
+// concurrent triggers wait in a bounded queue rather than each starting
+// its own pass.
+type fetchQueue struct {
+	mu      sync.Mutex
+	pending chan queuedFetch
+}
+
+var fetchWorkers = &fetchQueue{}
+
+var fetchQueueLength = promauto.NewGaugeFunc(prometheus.GaugeOpts{
+	Name: "myapp_fetch_queue_length",
+	Help: "Number of collection passes waiting for a worker",
+}, func() float64 { return float64(fetchWorkers.Len()) })
+
+// Start starts cfg.FetchWorkers workers taking passes from a queue of
+// cfg.FetchQueueSize. Each pass is cancelled after cfg.FetchJobTimeout,
+// unless it is zero. Changing these settings requires a restart.
+func (q *fetchQueue) Start(db *gorm.DB, cfg *Config) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.pending = make(chan queuedFetch, cfg.FetchQueueSize)
+	for i := 0; i < cfg.FetchWorkers; i++ {
+		go runFetchWorker(q.pending, db, cfg.FetchJobTimeout)
+	}
+}
+
+// Enqueue registers a job for a collection pass of cfg limited to scope,
+// on behalf of the request with ID requestID, and queues it. It returns
+// errFetchQueueFull when the queue has no room.
+func (q *fetchQueue) Enqueue(requestID string, cfg *Config, scope fetchScope) (*fetchJob, error) {
+	q.mu.Lock()
+	pending := q.pending
+	q.mu.Unlock()
+	if pending == nil {
+		return nil, errors.New("fetch workers are not running")
+	}
+
+	// The job is registered first, as a worker may take it, and finish
+	// it, as soon as it is queued.
+	job := newFetchJob(requestID)
+	fetchJobs.Add(job)
+	select {
+	case pending <- queuedFetch{job: job, cfg: cfg, scope: scope}:
+	default:
+		fetchJobs.Remove(job)
+		return nil, errFetchQueueFull
+	}
+	return job, nil
+}
+
+// Len returns the number of passes waiting for a worker.
+func (q *fetchQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.pending)
+}
+
+// runFetchWorker runs the passes taken from pending one at a time, each
+// with its own context.
+func runFetchWorker(pending <-chan queuedFetch, db *gorm.DB, timeout time.Duration) {
+	for next := range pending {
+		ctx, cancel := context.Background(), context.CancelFunc(func() {})
+		if timeout > 0 {
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+		}
+		runFetch(ctx, db, next.cfg, next.job, next.scope)
+		cancel()
+	}
+}
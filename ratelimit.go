@@ -0,0 +1,50 @@
+package main
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/limiter"
+)
+
+// RateLimitConfig limits the requests each client, identified by its IP
+// address, may make to the HTTP API: Requests per Window across all
+// endpoints, and FetchRequests per FetchWindow to the endpoints that start
+// a collection run, which spend the external APIs' quotas. A zero count
+// disables that limit. Windows slide, and counts are kept per process.
+type RateLimitConfig struct {
+	Requests      int
+	Window        time.Duration
+	FetchRequests int
+	FetchWindow   time.Duration
+}
+
+// unlimitedPaths are never rate limited, so that probes and scrapes keep
+// working for a client that is over its limit.
+var unlimitedPaths = map[string]bool{
+	"/healthz": true,
+	"/livez":   true,
+	"/readyz":  true,
+	"/metrics": true,
+}
+
+// rateLimit returns middleware allowing each client max requests per
+// window, or passing every request through when max is zero. Responses
+// carry X-RateLimit-Limit, X-RateLimit-Remaining and X-RateLimit-Reset,
+// and rejected ones a Retry-After header and status 429.
+func rateLimit(max int, window time.Duration) fiber.Handler {
+	if max <= 0 {
+		return func(c *fiber.Ctx) error { return c.Next() }
+	}
+	return limiter.New(limiter.Config{
+		Next:              func(c *fiber.Ctx) bool { return unlimitedPaths[c.Path()] },
+		Max:               max,
+		Expiration:        window,
+		LimiterMiddleware: limiter.SlidingWindow{},
+		LimitReached: func(c *fiber.Ctx) error {
+			return fiber.NewError(fiber.StatusTooManyRequests,
+				"rate limit of "+strconv.Itoa(max)+" requests per "+window.String()+" exceeded")
+		},
+	})
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"gorm.io/gorm"
+)
+
+// redditTopComments is the number of top-level comments kept per post.
+const redditTopComments = 5
+
+var redditAPICalls = promauto.NewCounter(prometheus.CounterOpts{
+	Name: "myapp_reddit_api_calls_total",
+	Help: "Total number of API calls to Reddit",
+})
+
+type RedditPost struct {
+	ID          string    `json:"id" gorm:"primaryKey"`
+	Framework   string    `json:"framework" gorm:"index"`
+	Subreddit   string    `json:"subreddit"`
+	Title       string    `json:"title"`
+	Body        string    `json:"body"`
+	Author      string    `json:"author"`
+	Score       int       `json:"score"`
+	NumComments int       `json:"num_comments"`
+	Permalink   string    `json:"permalink"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type RedditComment struct {
+	ID        string    `json:"id" gorm:"primaryKey"`
+	PostID    string    `json:"post_id" gorm:"index"`
+	Author    string    `json:"author"`
+	Body      string    `json:"body"`
+	Score     int       `json:"score"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// redditListing is the envelope Reddit wraps around posts and comments.
+type redditListing struct {
+	Data struct {
+		Children []struct {
+			Kind string `json:"kind"`
+			Data struct {
+				ID          string  `json:"id"`
+				Subreddit   string  `json:"subreddit"`
+				Title       string  `json:"title"`
+				Selftext    string  `json:"selftext"`
+				Body        string  `json:"body"`
+				Author      string  `json:"author"`
+				Score       int     `json:"score"`
+				NumComments int     `json:"num_comments"`
+				Permalink   string  `json:"permalink"`
+				CreatedUTC  float64 `json:"created_utc"`
+			} `json:"data"`
+		} `json:"children"`
+	} `json:"data"`
+}
+
+// fetchRedditPosts collects the newest submissions from each of the
+// framework's subreddits, along with their top comments.
+func fetchRedditPosts(ctx context.Context, cfg *Config, framework Framework) ([]RedditPost, []RedditComment, error) {
+	var posts []RedditPost
+	var comments []RedditComment
+
+	for _, subreddit := range framework.Subreddits {
+		redditAPICalls.Inc()
+
+		var listing redditListing
+		u := fmt.Sprintf("https://www.reddit.com/r/%s/new.json?limit=%d", url.PathEscape(subreddit), cfg.FetchLimit)
+		if err := getJSON(ctx, u, nil, &listing); err != nil {
+			return posts, comments, err
+		}
+
+		for _, child := range listing.Data.Children {
+			p := child.Data
+			posts = append(posts, RedditPost{
+				ID:          p.ID,
+				Framework:   framework.Name,
+				Subreddit:   p.Subreddit,
+				Title:       p.Title,
+				Body:        p.Selftext,
+				Author:      p.Author,
+				Score:       p.Score,
+				NumComments: p.NumComments,
+				Permalink:   "https://www.reddit.com" + p.Permalink,
+				CreatedAt:   time.Unix(int64(p.CreatedUTC), 0).UTC(),
+			})
+
+			if p.NumComments == 0 {
+				continue
+			}
+			postComments, err := fetchRedditTopComments(ctx, p.ID)
+			if err != nil {
+				return posts, comments, err
+			}
+			comments = append(comments, postComments...)
+		}
+	}
+	return posts, comments, nil
+}
+
+func fetchRedditTopComments(ctx context.Context, postID string) ([]RedditComment, error) {
+	redditAPICalls.Inc()
+
+	// The comments endpoint returns two listings: the post, then its comments.
+	var listings []redditListing
+	u := fmt.Sprintf("https://www.reddit.com/comments/%s.json?sort=top&depth=1&limit=%d", url.PathEscape(postID), redditTopComments)
+	if err := getJSON(ctx, u, nil, &listings); err != nil {
+		return nil, err
+	}
+	if len(listings) < 2 {
+		return nil, nil
+	}
+
+	var comments []RedditComment
+	for _, child := range listings[1].Data.Children {
+		if child.Kind != "t1" || len(comments) == redditTopComments {
+			continue
+		}
+		c := child.Data
+		comments = append(comments, RedditComment{
+			ID:        c.ID,
+			PostID:    postID,
+			Author:    c.Author,
+			Body:      c.Body,
+			Score:     c.Score,
+			CreatedAt: time.Unix(int64(c.CreatedUTC), 0).UTC(),
+		})
+	}
+	return comments, nil
+}
+
+func init() {
+	registerSource(func(cfg *Config) Source { return redditSource{cfg} })
+}
+
+// redditSource collects posts and their top comments from a framework's subreddits.
+type redditSource struct{ cfg *Config }
+
+func (redditSource) Name() string { return "reddit" }
+
+func (s redditSource) Fetch(ctx context.Context, framework Framework) ([]Item, error) {
+	posts, comments, err := fetchRedditPosts(ctx, s.cfg, framework)
+	return append(asItems(posts), asItems(comments)...), err
+}
+
+func (post RedditPost) Save(db *gorm.DB) error {
+	return upsert(db, &post)
+}
+
+func (comment RedditComment) Save(db *gorm.DB) error {
+	return upsert(db, &comment)
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// configStore holds the live configuration. Readers take a snapshot with
+// Get and keep using it for the duration of their work, so a reload never
+// changes settings underneath an in-flight fetch.
+type configStore struct {
+	current atomic.Pointer[Config]
+}
+
+func newConfigStore(cfg *Config) *configStore {
+	s := &configStore{}
+	s.current.Store(cfg)
+	return s
+}
+
+func (s *configStore) Get() *Config {
+	return s.current.Load()
+}
+
+// Reload re-reads the environment, config file, and secret backend and
+// swaps in the result, rescheduling collection if its schedules changed.
+// On error the previous configuration stays active.
+func (s *configStore) Reload() error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	s.current.Store(cfg)
+	fetchSchedule.Configure(cfg)
+	return nil
+}
+
+// Watch reloads the configuration on SIGHUP and whenever the config file
+// changes, until ctx is cancelled.
+func (s *configStore) Watch(ctx context.Context) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	var fileEvents <-chan fsnotify.Event
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		log.Printf("Config file watching disabled: %v", err)
+	} else {
+		defer watcher.Close()
+		// Watch the directory rather than the file so that editors which
+		// save by rename, and Kubernetes ConfigMap symlink swaps, are seen.
+		if err := watcher.Add(filepath.Dir(configPath())); err != nil {
+			log.Printf("Config file watching disabled: %v", err)
+		} else {
+			fileEvents = watcher.Events
+		}
+	}
+
+	// Editors often emit several events per save; coalesce them.
+	var debounce <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			s.reload("SIGHUP")
+		case ev := <-fileEvents:
+			if filepath.Clean(ev.Name) == filepath.Clean(configPath()) {
+				debounce = time.After(250 * time.Millisecond)
+			}
+		case <-debounce:
+			debounce = nil
+			s.reload("config file change")
+		}
+	}
+}
+
+// reloadHandler serves POST /admin/reload: it reloads the configuration
+// like SIGHUP and adds the frameworks it lists that are not registered
+// yet. Collection passes read the registry when they start, so the next
+// one, scheduled or not, collects the added frameworks.
+func reloadHandler(db *gorm.DB, store *configStore) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if err := store.Reload(); err != nil {
+			return fiber.NewError(fiber.StatusUnprocessableEntity, "reloading configuration: "+err.Error())
+		}
+		added, err := addConfiguredFrameworks(primary(db), store.Get().Frameworks)
+		if err != nil {
+			return err
+		}
+		frameworks, err := loadFrameworks(primary(db))
+		if err != nil {
+			return err
+		}
+		logf(c.UserContext(), "Config reloaded (admin request), %d frameworks added to the registry", added)
+		return c.JSON(fiber.Map{"added": added, "frameworks": frameworks})
+	}
+}
+
+func (s *configStore) reload(reason string) {
+	if err := s.Reload(); err != nil {
+		log.Printf("Config reload (%s) failed, keeping previous configuration: %v", reason, err)
+		return
+	}
+	log.Printf("Config reloaded (%s)", reason)
+}
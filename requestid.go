@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log"
+	"regexp"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// requestIDHeader carries the ID of an API request. It is echoed in the
+// response and passed on to the calls made on the request's behalf, and
+// collection passes keep the ID of the request that started them.
+const requestIDHeader = fiber.HeaderXRequestID
+
+// requestIDPattern matches the client-supplied request IDs that are kept;
+// others are replaced by a generated one.
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+type requestIDKey struct{}
+
+func newRequestID() string {
+	id := make([]byte, 16)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+// withRequestID returns ctx carrying the request ID id, if any.
+func withRequestID(ctx context.Context, id string) context.Context {
+	if id == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+func requestIDFrom(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// logf logs like log.Printf, prefixed with the request ID of ctx when it
+// has one.
+func logf(ctx context.Context, format string, args ...interface{}) {
+	if id := requestIDFrom(ctx); id != "" {
+		format = "[" + id + "] " + format
+	}
+	log.Printf(format, args...)
+}
+
+// requestID is middleware giving each request an ID: the X-Request-ID
+// it came with, if that looks like one, or a new random one. The ID is
+// set on the response and on the request's user context, and server
+// errors are logged with it.
+func requestID() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id := c.Get(requestIDHeader)
+		if !requestIDPattern.MatchString(id) {
+			id = newRequestID()
+		}
+		c.Set(requestIDHeader, id)
+		c.SetUserContext(withRequestID(c.UserContext(), id))
+
+		err := c.Next()
+		var e *fiber.Error
+		if err != nil && (!errors.As(err, &e) || e.Code >= fiber.StatusInternalServerError) {
+			logf(c.UserContext(), "%s %s: %v", c.Method(), c.OriginalURL(), err)
+		}
+		return err
+	}
+}
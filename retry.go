@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var apiRetries = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "myapp_api_retries_total",
+	Help: "Total number of external API calls retried after a transient failure",
+}, []string{"api"})
+
+// retryPolicy retries external API calls that fail transiently: with a
+// 5xx status or a timeout. Each pass configures it from FETCH_MAX_ATTEMPTS
+// and FETCH_RETRY_DELAY.
+type retryPolicy struct {
+	mu       sync.Mutex
+	attempts int
+	delay    time.Duration
+}
+
+var apiRetry = &retryPolicy{attempts: 1}
+
+// Configure sets the number of tries of a call and the initial wait
+// between them.
+func (p *retryPolicy) Configure(attempts int, delay time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.attempts, p.delay = attempts, delay
+}
+
+// Do calls fn until it succeeds, fails with an error that is not
+// transient, or has been tried the configured number of times. The wait
+// between tries doubles each time and is jittered by up to half of it
+// either way, so that instances do not retry in step. api labels the
+// retries metric.
+func (p *retryPolicy) Do(ctx context.Context, api string, fn func() error) error {
+	p.mu.Lock()
+	attempts, delay := p.attempts, p.delay
+	p.mu.Unlock()
+
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= attempts || ctx.Err() != nil || !transient(err) {
+			return err
+		}
+		wait := delay
+		if delay > 0 {
+			wait = delay/2 + time.Duration(rand.Int63n(int64(delay)))
+		}
+		logf(ctx, "Retrying %s call in %s after attempt %d of %d: %v", api, wait.Round(time.Millisecond), attempt, attempts, err)
+		apiRetries.WithLabelValues(api).Inc()
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(wait):
+		}
+		delay *= 2
+	}
+}
+
+// transient reports whether err is a 5xx response or a timeout, which
+// may well not recur.
+func transient(err error) bool {
+	var status *httpStatusError
+	if errors.As(err, &status) {
+		return status.StatusCode >= http.StatusInternalServerError
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
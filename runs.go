@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// FetchRun records the outcome of one source's collection pass for one
+// framework. runSource stores it together with the items it fetched, so
+// with the SQL backend a run and its data are committed or rolled back
+// as a unit; a run rolled back is then stored alone by saveFailedRun.
+// Bytes is the size of the response bodies the source read.
+type FetchRun struct {
+	ID         uint           `json:"id" gorm:"primaryKey"`
+	Source     string         `json:"source" gorm:"index"`
+	Framework  string         `json:"framework" gorm:"index"`
+	StartedAt  time.Time      `json:"started_at" gorm:"index"`
+	FinishedAt time.Time      `json:"finished_at"`
+	Items      int            `json:"items"`
+	Counts     map[string]int `json:"counts" gorm:"serializer:json"` // items by record type
+	Bytes      int64          `json:"bytes"`
+	Error      string         `json:"error,omitempty"`
+}
+
+func newFetchRun(source, framework string, started time.Time, items []Item, bytes int64, err error) FetchRun {
+	run := FetchRun{
+		Source:     source,
+		Framework:  framework,
+		StartedAt:  started,
+		FinishedAt: time.Now().UTC(),
+		Items:      len(items),
+		Counts:     map[string]int{},
+		Bytes:      bytes,
+	}
+	for _, item := range items {
+		run.Counts[reflect.TypeOf(item).Name()]++
+	}
+	if err != nil {
+		run.Error = err.Error()
+	}
+	return run
+}
+
+func (run FetchRun) Save(db *gorm.DB) error {
+	return db.Create(&run).Error
+}
+
+// saveFailedRun stores run on its own after the batch it was saved with
+// failed with err, taking the run down with it, so that the failure still
+// shows in the run history.
+func saveFailedRun(ctx context.Context, store Store, run FetchRun, err error) {
+	storing := "storing: " + err.Error()
+	if run.Error == "" {
+		run.Error = storing
+	} else {
+		run.Error += "; " + storing
+	}
+	if err := store.Save(ctx, run); err != nil {
+		logf(ctx, "Error recording %s run for %s: %v", run.Source, run.Framework, err)
+	}
+}
+
+type fetchBytesKey struct{}
+
+// withFetchBytes returns ctx counting into n the bytes of the responses
+// that doRequest reads on its behalf.
+func withFetchBytes(ctx context.Context, n *atomic.Int64) context.Context {
+	return context.WithValue(ctx, fetchBytesKey{}, n)
+}
+
+// countFetchBytes adds n bytes to the count of ctx, if it has one.
+func countFetchBytes(ctx context.Context, n int) {
+	if count, ok := ctx.Value(fetchBytesKey{}).(*atomic.Int64); ok {
+		count.Add(int64(n))
+	}
+}
+
+// Values of ?status= on GET /api/v1/runs.
+const (
+	runSucceeded = "succeeded"
+	runFailed    = "failed"
+)
+
+// RunFilter selects fetch runs for Store.ListRuns: those of Source and
+// Framework, matched exactly and case-insensitively respectively, with
+// Status succeeded or failed, started in TimeRange. Zero fields match
+// everything; a zero Limit means no limit.
+type RunFilter struct {
+	Source    string
+	Framework string
+	Status    string
+	TimeRange
+	Limit  int
+	Offset int
+}
+
+// ListRuns returns the matching fetch runs, most recently started first.
+func (s gormStore) ListRuns(ctx context.Context, filter RunFilter) ([]FetchRun, int64, error) {
+	query := s.db.WithContext(ctx).Model(&FetchRun{})
+	if filter.Source != "" {
+		query = query.Where("source = ?", filter.Source)
+	}
+	if filter.Framework != "" {
+		query = query.Where("LOWER(framework) = ?", strings.ToLower(filter.Framework))
+	}
+	switch filter.Status {
+	case runSucceeded:
+		query = query.Where("error = ''")
+	case runFailed:
+		query = query.Where("error <> ''")
+	}
+	query = filter.TimeRange.where(query, "started_at")
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var runs []FetchRun
+	if err := paginate(query.Order("started_at DESC, id DESC"), filter.Limit, filter.Offset).Find(&runs).Error; err != nil {
+		return nil, 0, err
+	}
+	return runs, total, nil
+}
+
+// listRunsHandler serves GET /api/v1/runs, the history of fetch runs,
+// most recently started first, optionally filtered by ?source=,
+// ?framework=, ?status= (succeeded or failed) and when they started,
+// ?from= and ?to=. It takes the same ?page= and ?per_page= as
+// /api/v1/posts.
+func listRunsHandler(records Store) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		page, perPage, err := pageParams(c)
+		if err != nil {
+			return err
+		}
+		status := c.Query("status")
+		if status != "" && status != runSucceeded && status != runFailed {
+			return fiber.NewError(fiber.StatusBadRequest, "status must be succeeded or failed")
+		}
+		period, err := timeRangeParams(c)
+		if err != nil {
+			return err
+		}
+
+		runs, total, err := records.ListRuns(c.UserContext(), RunFilter{
+			Source:    c.Query("source"),
+			Framework: c.Query("framework"),
+			Status:    status,
+			TimeRange: period,
+			Limit:     perPage,
+			Offset:    (page - 1) * perPage,
+		})
+		if err != nil {
+			return err
+		}
+		if runs == nil {
+			runs = []FetchRun{}
+		}
+		return c.JSON(fiber.Map{"total": total, "page": page, "per_page": perPage, "runs": runs})
+	}
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"log"
+	"maps"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// scheduleOff disables a fetch schedule.
+const scheduleOff = "off"
+
+// parseFetchSchedule parses a FETCH_SCHEDULE expression: five cron
+// fields, or a descriptor such as @hourly or @every 30m, in the server's
+// time zone. It returns nil for an empty or off schedule.
+func parseFetchSchedule(spec string) (cron.Schedule, error) {
+	if spec == "" || spec == scheduleOff {
+		return nil, nil
+	}
+	return cron.ParseStandard(spec)
+}
+
+// fetchSchedules returns the cron expression of every enabled source that
+// has a schedule, keyed by source name.
+func fetchSchedules(cfg *Config) map[string]string {
+	specs := map[string]string{}
+	for _, src := range newSources(cfg) {
+		if !cfg.Flags.Enabled(sourceFlag(src)) {
+			continue
+		}
+		spec, ok := cfg.SourceSchedules[src.Name()]
+		if !ok {
+			spec = cfg.FetchSchedule
+		}
+		if spec != "" && spec != scheduleOff {
+			specs[src.Name()] = spec
+		}
+	}
+	return specs
+}
+
+// scheduledSource is the state of one source's schedule. A run that comes
+// due while the previous one is still going is skipped.
+type scheduledSource struct {
+	name    string
+	spec    string
+	entry   cron.EntryID
+	running atomic.Bool
+
+	mu      sync.Mutex
+	lastRun *time.Time
+	lastJob string
+	skipped int
+}
+
+// scheduleStatus describes a source's schedule on GET /status.
+type scheduleStatus struct {
+	Source   string     `json:"source"`
+	Schedule string     `json:"schedule"`
+	NextRun  *time.Time `json:"next_run,omitempty"`
+	LastRun  *time.Time `json:"last_run,omitempty"`
+	LastJob  string     `json:"last_job,omitempty"`
+	Running  bool       `json:"running"`
+	Skipped  int        `json:"skipped"`
+}
+
+// fetchScheduler runs a collection pass for each source on its cron
+// schedule, FETCH_SCHEDULE_<SOURCE> or else FETCH_SCHEDULE. Each pass is
+// a job queued like those started through /api/v1/fetch-data.
+type fetchScheduler struct {
+	mu      sync.Mutex
+	store   *configStore
+	cron    *cron.Cron
+	specs   map[string]string
+	sources map[string]*scheduledSource
+}
+
+var fetchSchedule = &fetchScheduler{}
+
+// Start schedules the collection passes of store's configuration.
+func (s *fetchScheduler) Start(store *configStore) {
+	s.mu.Lock()
+	s.store = store
+	s.mu.Unlock()
+	s.Configure(store.Get())
+}
+
+// Configure replaces the schedules with those of cfg, if they changed.
+// Runs in progress are kept, and still block their source's next run.
+func (s *fetchScheduler) Configure(cfg *Config) {
+	specs := fetchSchedules(cfg)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.store == nil || (s.cron != nil && maps.Equal(specs, s.specs)) {
+		return
+	}
+	if s.cron != nil {
+		s.cron.Stop()
+	}
+	if s.sources == nil {
+		s.sources = map[string]*scheduledSource{}
+	}
+
+	s.cron = cron.New()
+	s.specs = specs
+	for name, spec := range specs {
+		schedule, err := parseFetchSchedule(spec)
+		if err != nil {
+			log.Printf("Error scheduling %s collection: %v", name, err)
+			continue
+		}
+		src := s.sources[name]
+		if src == nil {
+			src = &scheduledSource{name: name}
+			s.sources[name] = src
+		}
+		src.spec = spec
+		src.entry = s.cron.Schedule(schedule, cron.FuncJob(func() { s.run(src) }))
+	}
+	for name := range s.sources {
+		if _, ok := specs[name]; !ok {
+			delete(s.sources, name)
+		}
+	}
+	s.cron.Start()
+	if len(specs) > 0 {
+		log.Printf("Scheduled collection for %d sources", len(specs))
+	}
+}
+
+// run queues a pass collecting src's items for every framework and waits
+// for it, unless its previous run is still queued or going.
+func (s *fetchScheduler) run(src *scheduledSource) {
+	if !src.running.CompareAndSwap(false, true) {
+		log.Printf("Skipping scheduled %s collection: the previous run is still going", src.name)
+		src.mu.Lock()
+		src.skipped++
+		src.mu.Unlock()
+		return
+	}
+	defer src.running.Store(false)
+
+	job, err := fetchWorkers.Enqueue(newRequestID(), s.store.Get(), fetchScope{Source: src.name})
+	if err != nil {
+		log.Printf("Skipping scheduled %s collection: %v", src.name, err)
+		src.mu.Lock()
+		src.skipped++
+		src.mu.Unlock()
+		return
+	}
+	now := time.Now().UTC()
+	src.mu.Lock()
+	src.lastRun, src.lastJob = &now, job.ID
+	src.mu.Unlock()
+	<-job.Done()
+}
+
+// Status returns the state of every schedule, by source name.
+func (s *fetchScheduler) Status() []scheduleStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	statuses := []scheduleStatus{}
+	for _, src := range s.sources {
+		status := scheduleStatus{Source: src.name, Schedule: src.spec, Running: src.running.Load()}
+		if next := s.cron.Entry(src.entry).Next; !next.IsZero() {
+			status.NextRun = &next
+		}
+		src.mu.Lock()
+		status.LastRun, status.LastJob, status.Skipped = src.lastRun, src.lastJob, src.skipped
+		src.mu.Unlock()
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Source < statuses[j].Source })
+	return statuses
+}
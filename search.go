@@ -0,0 +1,204 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// searchResult is one post or issue matching a search query. Score and
+// Highlights are only filled in by Elasticsearch and Postgres full-text
+// search; highlighted terms are wrapped in <em> tags by both.
+type searchResult struct {
+	Kind       string              `json:"kind"`
+	Site       string              `json:"site,omitempty"`
+	Ref        int                 `json:"ref"`
+	Title      string              `json:"title"`
+	Score      float64             `json:"score,omitempty"`
+	Highlights map[string][]string `json:"highlights,omitempty"`
+}
+
+type searchResponse struct {
+	Engine  string         `json:"engine"`
+	Total   int64          `json:"total"`
+	Results []searchResult `json:"results"`
+}
+
+// searchHandler serves GET /api/v1/search?q=, optionally narrowed with
+// kind=post|issue. It queries Elasticsearch when one is configured and
+// the Store otherwise.
+func searchHandler(records Store, store *configStore) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		q := strings.TrimSpace(c.Query("q"))
+		if q == "" {
+			return fiber.NewError(fiber.StatusBadRequest, "q is required")
+		}
+		kind := c.Query("kind")
+		if kind != "" && kind != "post" && kind != "issue" {
+			return fiber.NewError(fiber.StatusBadRequest, "kind must be post or issue")
+		}
+		limit, err := strconv.Atoi(c.Query("limit", "20"))
+		if err != nil || limit < 1 || limit > 100 {
+			return fiber.NewError(fiber.StatusBadRequest, "limit must be between 1 and 100")
+		}
+
+		if es := store.Get().Elasticsearch; es.Enabled() {
+			resp, err := searchElasticsearch(c.UserContext(), es, q, kind, limit)
+			if err != nil {
+				return fiber.NewError(fiber.StatusBadGateway, err.Error())
+			}
+			return c.JSON(resp)
+		}
+		resp, err := records.Search(c.UserContext(), SearchFilter{Query: q, Kind: kind, Limit: limit})
+		if err != nil {
+			return err
+		}
+		return c.JSON(resp)
+	}
+}
+
+// searchElasticsearch runs a relevance-ranked match over titles and bodies,
+// weighting title matches higher, and returns highlighted fragments.
+func searchElasticsearch(ctx context.Context, es ElasticsearchConfig, q, kind string, limit int) (searchResponse, error) {
+	query := map[string]interface{}{
+		"multi_match": map[string]interface{}{"query": q, "fields": []string{"title^3", "body"}},
+	}
+	if kind != "" {
+		query = map[string]interface{}{"bool": map[string]interface{}{
+			"must":   query,
+			"filter": map[string]interface{}{"term": map[string]string{"kind": kind}},
+		}}
+	}
+	request := map[string]interface{}{
+		"size":    limit,
+		"query":   query,
+		"_source": []string{"kind", "site", "ref", "title"},
+		"highlight": map[string]interface{}{
+			"fields": map[string]interface{}{
+				"title": map[string]interface{}{"number_of_fragments": 0},
+				"body":  map[string]interface{}{"fragment_size": 160, "number_of_fragments": 3},
+			},
+		},
+	}
+	payload, err := json.Marshal(request)
+	if err != nil {
+		return searchResponse{}, err
+	}
+
+	var result struct {
+		Hits struct {
+			Total struct {
+				Value int64 `json:"value"`
+			} `json:"total"`
+			Hits []struct {
+				Score     float64             `json:"_score"`
+				Source    searchDocument      `json:"_source"`
+				Highlight map[string][]string `json:"highlight"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}
+	if err := esDo(ctx, es, http.MethodPost, "/"+es.Index+"/_search", "application/json", bytes.NewReader(payload), &result); err != nil {
+		return searchResponse{}, err
+	}
+
+	resp := searchResponse{Engine: "elasticsearch", Total: result.Hits.Total.Value, Results: []searchResult{}}
+	for _, hit := range result.Hits.Hits {
+		resp.Results = append(resp.Results, searchResult{
+			Kind:       hit.Source.Kind,
+			Site:       hit.Source.Site,
+			Ref:        hit.Source.Ref,
+			Title:      hit.Source.Title,
+			Score:      hit.Score,
+			Highlights: hit.Highlight,
+		})
+	}
+	return resp, nil
+}
+
+// searchPostgres ranks posts and issues by ts_rank over their
+// search_vector columns, which weight titles above bodies. The query
+// accepts web search syntax: quoted phrases, OR and -excluded words.
+// Highlights are only computed for the returned page of results.
+func searchPostgres(db *gorm.DB, filter SearchFilter) (searchResponse, error) {
+	resp := searchResponse{Engine: "postgres", Results: []searchResult{}}
+
+	var hits []string
+	if filter.Kind != "issue" {
+		hits = append(hits, `SELECT 'post' AS kind, site, question_id AS ref, title, body, ts_rank(search_vector, query) AS score
+FROM stack_overflow_posts, websearch_to_tsquery('english', @q) query
+WHERE deleted_at IS NULL AND search_vector @@ query`)
+	}
+	if filter.Kind != "post" {
+		hits = append(hits, `SELECT 'issue' AS kind, '' AS site, number AS ref, title, body, ts_rank(search_vector, query) AS score
+FROM git_hub_issues, websearch_to_tsquery('english', @q) query
+WHERE deleted_at IS NULL AND search_vector @@ query`)
+	}
+	args := map[string]interface{}{"q": filter.Query, "limit": filter.Limit}
+
+	if err := db.Raw("SELECT count(*) FROM ("+strings.Join(hits, " UNION ALL ")+") hits", args).Scan(&resp.Total).Error; err != nil {
+		return resp, err
+	}
+
+	var rows []struct {
+		Kind           string
+		Site           string
+		Ref            int
+		Title          string
+		Score          float64
+		TitleHighlight string
+		BodyHighlight  string
+	}
+	err := db.Raw(`SELECT kind, site, ref, title, score,
+    ts_headline('english', title, websearch_to_tsquery('english', @q), 'HighlightAll=true, StartSel=<em>, StopSel=</em>') AS title_highlight,
+    ts_headline('english', body, websearch_to_tsquery('english', @q), 'MaxFragments=3, MaxWords=30, MinWords=10, StartSel=<em>, StopSel=</em>, FragmentDelimiter=`+headlineDelimiter+`') AS body_highlight
+FROM (`+strings.Join(hits, " UNION ALL ")+` ORDER BY score DESC LIMIT @limit) hits
+ORDER BY score DESC`, args).Scan(&rows).Error
+	if err != nil {
+		return resp, err
+	}
+	for _, row := range rows {
+		result := searchResult{Kind: row.Kind, Site: row.Site, Ref: row.Ref, Title: row.Title, Score: row.Score}
+		result.Highlights = map[string][]string{"title": {row.TitleHighlight}}
+		if row.BodyHighlight != "" {
+			result.Highlights["body"] = strings.Split(row.BodyHighlight, headlineDelimiter)
+		}
+		resp.Results = append(resp.Results, result)
+	}
+	return resp, nil
+}
+
+// headlineDelimiter separates the body fragments returned by ts_headline.
+const headlineDelimiter = "|||"
+
+// searchByListing is the unranked substring match used by backends
+// without full-text search.
+func searchByListing(ctx context.Context, records Store, filter SearchFilter) (searchResponse, error) {
+	resp := searchResponse{Engine: "database", Results: []searchResult{}}
+
+	if filter.Kind != "issue" {
+		posts, _, err := records.ListPosts(ctx, PostFilter{Text: filter.Query, Limit: filter.Limit})
+		if err != nil {
+			return resp, err
+		}
+		for _, post := range posts {
+			resp.Results = append(resp.Results, searchResult{Kind: "post", Site: post.Site, Ref: post.QuestionID, Title: post.Title})
+		}
+	}
+	if filter.Kind != "post" && len(resp.Results) < filter.Limit {
+		issues, _, err := records.ListIssues(ctx, IssueFilter{Text: filter.Query, Limit: filter.Limit - len(resp.Results)})
+		if err != nil {
+			return resp, err
+		}
+		for _, issue := range issues {
+			resp.Results = append(resp.Results, searchResult{Kind: "issue", Ref: issue.Number, Title: issue.Title})
+		}
+	}
+	resp.Total = int64(len(resp.Results))
+	return resp, nil
+}
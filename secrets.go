@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"fmt"
+)
+
+// SecretBackend resolves service credentials from an external store. The
+// returned map is keyed by the secretKey* constants; absent keys leave the
+// corresponding environment value in place.
+type SecretBackend interface {
+	Secrets(ctx context.Context) (map[string]string, error)
+}
+
+// Keys looked up in a secret backend. github_token may hold several
+// comma-separated tokens to populate the token pool.
+const (
+	secretKeyGitHubToken      = "github_token"
+	secretKeyStackExchangeKey = "stackexchange_key"
+	secretKeyTeamsToken       = "stackoverflow_teams_token"
+	secretKeyDBPassword       = "db_password"
+	secretKeyDatabaseURL      = "database_url"
+	secretKeyReplicaURLs      = "database_replica_urls"
+	secretKeyMongoDBURI       = "mongodb_uri"
+	secretKeyElasticAPIKey    = "elasticsearch_api_key"
+	secretKeyClickHouseURL    = "clickhouse_url"
+	secretKeyMilvusToken      = "milvus_token"
+	secretKeyRedisURL         = "redis_url"
+	secretKeyEmbeddingsKey    = "embeddings_api_key"
+	secretKeyAdminToken       = "admin_token"
+	secretKeyJWTSecret        = "jwt_secret"
+	secretKeyGitLabToken      = "gitlab_token"
+	secretKeyJiraToken        = "jira_token"
+	secretKeyNVDAPIKey        = "nvd_api_key"
+	secretKeyLibrariesIOKey   = "libraries_io_key"
+	secretKeyBitbucketToken   = "bitbucket_app_password"
+	secretKeyYouTubeAPIKey    = "youtube_api_key"
+)
+
+// newSecretBackend returns the backend selected by SECRET_BACKEND, or nil
+// when credentials come straight from the environment.
+func newSecretBackend(ctx context.Context, cfg *Config) (SecretBackend, error) {
+	switch cfg.SecretBackend {
+	case "", "env":
+		return nil, nil
+	case "vault":
+		if !cfg.Vault.Enabled() {
+			return nil, fmt.Errorf("SECRET_BACKEND=vault requires VAULT_ADDR and VAULT_TOKEN")
+		}
+		return vaultBackend{cfg.Vault}, nil
+	case "aws-secretsmanager":
+		if cfg.AWS.SecretID == "" {
+			return nil, fmt.Errorf("SECRET_BACKEND=aws-secretsmanager requires AWS_SECRET_ID")
+		}
+		return newSecretsManagerBackend(ctx, cfg.AWS)
+	case "aws-ssm":
+		if cfg.AWS.SSMPath == "" {
+			return nil, fmt.Errorf("SECRET_BACKEND=aws-ssm requires AWS_SSM_PATH")
+		}
+		return newSSMBackend(ctx, cfg.AWS)
+	default:
+		return nil, fmt.Errorf("unknown SECRET_BACKEND %q", cfg.SecretBackend)
+	}
+}
+
+// applySecrets overrides the credentials in cfg with values from the
+// configured secret backend, if any.
+func applySecrets(ctx context.Context, cfg *Config) error {
+	backend, err := newSecretBackend(ctx, cfg)
+	if err != nil || backend == nil {
+		return err
+	}
+
+	values, err := backend.Secrets(ctx)
+	if err != nil {
+		return err
+	}
+
+	if v := values[secretKeyGitHubToken]; v != "" {
+		cfg.GitHubTokens = splitList(v)
+	}
+	if v := values[secretKeyStackExchangeKey]; v != "" {
+		cfg.StackExchangeKey = v
+	}
+	if v := values[secretKeyTeamsToken]; v != "" {
+		cfg.StackOverflowTeamsToken = v
+	}
+	if v := values[secretKeyDBPassword]; v != "" {
+		cfg.Database.Password = v
+	}
+	if v := values[secretKeyDatabaseURL]; v != "" {
+		cfg.Database.URL = v
+	}
+	if v := values[secretKeyReplicaURLs]; v != "" {
+		cfg.Database.ReplicaURLs = splitList(v)
+	}
+	if v := values[secretKeyMongoDBURI]; v != "" {
+		cfg.MongoDB.URI = v
+	}
+	if v := values[secretKeyElasticAPIKey]; v != "" {
+		cfg.Elasticsearch.APIKey = v
+	}
+	if v := values[secretKeyClickHouseURL]; v != "" {
+		cfg.ClickHouse.URL = v
+	}
+	if v := values[secretKeyRedisURL]; v != "" {
+		cfg.Cache.RedisURL = v
+	}
+	if v := values[secretKeyMilvusToken]; v != "" {
+		cfg.Milvus.Token = v
+	}
+	if v := values[secretKeyEmbeddingsKey]; v != "" {
+		cfg.Embedding.APIKey = v
+	}
+	if v := values[secretKeyAdminToken]; v != "" {
+		cfg.AdminToken = v
+	}
+	if v := values[secretKeyJWTSecret]; v != "" {
+		cfg.JWT.Secret = v
+	}
+	if v := values[secretKeyGitLabToken]; v != "" {
+		cfg.GitLab.Token = v
+	}
+	if v := values[secretKeyJiraToken]; v != "" {
+		cfg.Jira.Token = v
+	}
+	if v := values[secretKeyNVDAPIKey]; v != "" {
+		cfg.NVDAPIKey = v
+	}
+	if v := values[secretKeyLibrariesIOKey]; v != "" {
+		cfg.LibrariesIOKey = v
+	}
+	if v := values[secretKeyBitbucketToken]; v != "" {
+		cfg.Bitbucket.AppPassword = v
+	}
+	if v := values[secretKeyYouTubeAPIKey]; v != "" {
+		cfg.YouTubeAPIKey = v
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Item is a record produced by a Source. Save inserts it or updates the
+// stored copy.
+type Item interface {
+	Save(db *gorm.DB) error
+}
+
+// Source is a collector run by fetchDataAndStore for every framework in
+// the registry. Name identifies it in logs and selects its feature flag,
+// source.<name>. A source returns the items it collected even when it also
+// returns an error, so partial results are still stored.
+type Source interface {
+	Name() string
+	Fetch(ctx context.Context, framework Framework) ([]Item, error)
+}
+
+// sourceFactory builds a Source from the effective configuration.
+type sourceFactory func(cfg *Config) Source
+
+// sourceFactories holds every registered collector in registration order.
+var sourceFactories []sourceFactory
+
+// registerSource adds a collector to the registry. Collectors call it from
+// an init function in their own file.
+func registerSource(factory sourceFactory) {
+	sourceFactories = append(sourceFactories, factory)
+}
+
+// newSources builds every registered collector for cfg.
+func newSources(cfg *Config) []Source {
+	sources := make([]Source, 0, len(sourceFactories))
+	for _, factory := range sourceFactories {
+		sources = append(sources, factory(cfg))
+	}
+	return sources
+}
+
+func sourceFlag(src Source) string {
+	return "source." + src.Name()
+}
+
+type fetchScopeKey struct{}
+
+// fetchScope identifies the source and framework a collection pass is
+// running for. runSource attaches it to the context handed to the
+// source and to the Store. Passed to fetchDataAndStore, it limits the
+// pass to that source and framework, an empty field meaning all of them.
+type fetchScope struct {
+	Source    string
+	Framework string
+}
+
+func withFetchScope(ctx context.Context, source, framework string) context.Context {
+	return context.WithValue(ctx, fetchScopeKey{}, fetchScope{source, framework})
+}
+
+func fetchScopeFrom(ctx context.Context) fetchScope {
+	scope, _ := ctx.Value(fetchScopeKey{}).(fetchScope)
+	return scope
+}
+
+// asItems converts a slice of records to the []Item returned by Fetch.
+func asItems[T Item](records []T) []Item {
+	items := make([]Item, 0, len(records))
+	for _, record := range records {
+		items = append(items, record)
+	}
+	return items
+}
+
+// runSource fetches src's items for every framework and hands them to
+// store in one batch per framework, along with a FetchRun recording the
+// outcome, which is also added to job. Should the batch fail, the run is
+// stored on its own. Fetches go through the source's
+// circuit breaker in sourceBreakers. It stops once ctx is done.
+func runSource(ctx context.Context, store Store, src Source, frameworks []Framework, job *fetchJob) {
+	for _, framework := range frameworks {
+		if ctx.Err() != nil {
+			return
+		}
+		ctx := withFetchScope(ctx, src.Name(), framework.Name)
+		started := time.Now().UTC()
+		var bytes atomic.Int64
+		items, err := sourceBreakers.Fetch(withFetchBytes(ctx, &bytes), src, framework)
+		if err != nil {
+			logf(ctx, "Error fetching %s data for %s: %v", src.Name(), framework.Name, err)
+		}
+		run := newFetchRun(src.Name(), framework.Name, started, items, bytes.Load(), err)
+		err = store.Save(ctx, append(items, run)...)
+		if err != nil {
+			logf(ctx, "Error storing %s data for %s: %v", src.Name(), framework.Name, err)
+			saveFailedRun(ctx, store, run, err)
+		}
+		job.record(run, err)
+	}
+}
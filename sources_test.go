@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// stubSource returns the same items and error for every framework.
+type stubSource struct {
+	items []Item
+	err   error
+}
+
+func (stubSource) Name() string { return "stub" }
+
+func (s stubSource) Fetch(ctx context.Context, framework Framework) ([]Item, error) {
+	return s.items, s.err
+}
+
+// rejectedItem fails to store, rolling back the batch it is saved with.
+type rejectedItem struct{}
+
+func (rejectedItem) Save(db *gorm.DB) error {
+	return errors.New("constraint violated")
+}
+
+func openTestStore(t *testing.T) gormStore {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Each connection would get a database of its own.
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	if err := db.AutoMigrate(&FetchRun{}); err != nil {
+		t.Fatal(err)
+	}
+	return gormStore{db: db}
+}
+
+func TestRunSourceRecordsRunWhenSaveFails(t *testing.T) {
+	store := openTestStore(t)
+	job := newFetchJob("")
+	src := stubSource{items: []Item{rejectedItem{}}}
+
+	runSource(context.Background(), store, src, []Framework{{Name: "react"}}, job)
+
+	runs, total, err := store.ListRuns(context.Background(), RunFilter{Status: runFailed})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 1 || len(runs) != 1 {
+		t.Fatalf("got %d failed runs, want 1", total)
+	}
+	run := runs[0]
+	if run.Source != "stub" || run.Framework != "react" || run.Items != 1 {
+		t.Errorf("got run %+v, want the stub run of react with 1 item", run)
+	}
+	if !strings.Contains(run.Error, "constraint violated") {
+		t.Errorf("run error %q does not record the storage error", run.Error)
+	}
+	if errs := job.snapshot().Sources["stub"].Errors; len(errs) != 1 {
+		t.Errorf("job recorded errors %q, want the storage error once", errs)
+	}
+}
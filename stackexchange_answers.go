@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// stackExchangeBatchSize is the most IDs the API accepts in one vectorized
+// request.
+const stackExchangeBatchSize = 100
+
+type StackOverflowAnswer struct {
+	AnswerID     int       `json:"answer_id" gorm:"primaryKey;autoIncrement:false"`
+	QuestionID   int       `json:"question_id" gorm:"index"`
+	Site         string    `json:"site"`
+	Framework    string    `json:"framework" gorm:"index"` // framework the question was collected for
+	Body         string    `json:"body"`
+	Score        int       `json:"score"`
+	IsAccepted   bool      `json:"is_accepted"`
+	Owner        string    `json:"owner"`
+	CreationDate time.Time `json:"creation_date"`
+}
+
+// stackExchangeWrapper is the envelope common to every StackExchange API
+// response.
+type stackExchangeWrapper struct {
+	HasMore        bool `json:"has_more"`
+	QuotaRemaining int  `json:"quota_remaining"`
+	QuotaMax       int  `json:"quota_max"`
+	Backoff        int  `json:"backoff"`
+}
+
+// fetchStackOverflowAnswers returns the answers to the given questions on
+// site, fetched in batches of up to 100 question IDs.
+func fetchStackOverflowAnswers(ctx context.Context, cfg *Config, site string, questionIDs []int) ([]StackOverflowAnswer, error) {
+	var answers []StackOverflowAnswer
+	for start := 0; start < len(questionIDs); start += stackExchangeBatchSize {
+		end := start + stackExchangeBatchSize
+		if end > len(questionIDs) {
+			end = len(questionIDs)
+		}
+		ids := make([]string, 0, end-start)
+		for _, id := range questionIDs[start:end] {
+			ids = append(ids, strconv.Itoa(id))
+		}
+
+		for page := 1; ; page++ {
+			if seQuota.Exhausted(cfg.StackExchangeQuotaReserve) {
+				return answers, errors.New("StackExchange quota nearly exhausted")
+			}
+			seQuota.WaitBackoff()
+			stackoverflowAPICalls.Inc()
+
+			query := url.Values{
+				"filter":   {"withbody"},
+				"sort":     {"votes"},
+				"order":    {"desc"},
+				"pagesize": {"100"},
+				"page":     {strconv.Itoa(page)},
+			}
+
+			var result struct {
+				stackExchangeWrapper
+				Items []struct {
+					AnswerID     int    `json:"answer_id"`
+					QuestionID   int    `json:"question_id"`
+					Body         string `json:"body"`
+					Score        int    `json:"score"`
+					IsAccepted   bool   `json:"is_accepted"`
+					CreationDate int64  `json:"creation_date"`
+					Owner        struct {
+						DisplayName string `json:"display_name"`
+					} `json:"owner"`
+				} `json:"items"`
+			}
+			if err := stackExchangeGet(ctx, cfg, fmt.Sprintf("/questions/%s/answers", strings.Join(ids, ";")), site, query, &result); err != nil {
+				return answers, err
+			}
+			seQuota.Update(result.QuotaRemaining, result.QuotaMax, result.Backoff)
+
+			for _, item := range result.Items {
+				answers = append(answers, StackOverflowAnswer{
+					AnswerID:     item.AnswerID,
+					QuestionID:   item.QuestionID,
+					Site:         site,
+					Body:         item.Body,
+					Score:        item.Score,
+					IsAccepted:   item.IsAccepted,
+					Owner:        item.Owner.DisplayName,
+					CreationDate: time.Unix(item.CreationDate, 0).UTC(),
+				})
+			}
+			if !result.HasMore {
+				break
+			}
+		}
+	}
+	return answers, nil
+}
+
+// fetchAnswersForPosts groups posts by site and fetches their answers,
+// which take the framework of their question.
+func fetchAnswersForPosts(ctx context.Context, cfg *Config, posts []StackOverflowPost) ([]StackOverflowAnswer, error) {
+	bySite := map[string][]int{}
+	frameworks := map[string]map[int]string{}
+	var sites []string
+	for _, post := range posts {
+		if _, ok := bySite[post.Site]; !ok {
+			sites = append(sites, post.Site)
+			frameworks[post.Site] = map[int]string{}
+		}
+		bySite[post.Site] = append(bySite[post.Site], post.QuestionID)
+		frameworks[post.Site][post.QuestionID] = post.Framework
+	}
+
+	var answers []StackOverflowAnswer
+	for _, site := range sites {
+		siteAnswers, err := fetchStackOverflowAnswers(ctx, cfg, site, bySite[site])
+		for i := range siteAnswers {
+			siteAnswers[i].Framework = frameworks[site][siteAnswers[i].QuestionID]
+		}
+		answers = append(answers, siteAnswers...)
+		if err != nil {
+			return answers, err
+		}
+	}
+	return answers, nil
+}
+
+func (answer StackOverflowAnswer) Save(db *gorm.DB) error {
+	return upsert(db, &answer)
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	stackExchangeQuotaRemaining = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "myapp_stackexchange_quota_remaining",
+		Help: "Requests remaining in the current StackExchange API daily quota",
+	})
+	stackExchangeQuotaMax = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "myapp_stackexchange_quota_max",
+		Help: "Size of the StackExchange API daily quota",
+	})
+)
+
+// stackExchangeQuota records the quota and backoff fields returned in every
+// StackExchange API response wrapper. The quota resets at midnight UTC.
+type stackExchangeQuota struct {
+	mu           sync.Mutex
+	remaining    int
+	max          int
+	updatedAt    time.Time
+	backoffUntil time.Time
+}
+
+// QuotaStatus is the JSON view of the StackExchange quota.
+type QuotaStatus struct {
+	QuotaRemaining int        `json:"quota_remaining"`
+	QuotaMax       int        `json:"quota_max"`
+	UpdatedAt      *time.Time `json:"updated_at,omitempty"`
+	BackoffUntil   *time.Time `json:"backoff_until,omitempty"`
+	Exhausted      bool       `json:"exhausted"`
+}
+
+var seQuota = &stackExchangeQuota{}
+
+// Update stores the quota fields from a response. backoff is the number of
+// seconds the API asked clients to wait before the next request.
+func (q *stackExchangeQuota) Update(remaining, max, backoff int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := time.Now()
+	q.remaining, q.max, q.updatedAt = remaining, max, now
+	if backoff > 0 {
+		q.backoffUntil = now.Add(time.Duration(backoff) * time.Second)
+	}
+
+	stackExchangeQuotaRemaining.Set(float64(remaining))
+	stackExchangeQuotaMax.Set(float64(max))
+}
+
+// Exhausted reports whether no more than reserve requests remain in
+// today's quota. Until the first response of the day arrives the quota is
+// assumed to be available.
+func (q *stackExchangeQuota) Exhausted(reserve int) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.exhaustedLocked(reserve)
+}
+
+func (q *stackExchangeQuota) exhaustedLocked(reserve int) bool {
+	if q.updatedAt.IsZero() {
+		return false
+	}
+	last, now := q.updatedAt.UTC(), time.Now().UTC()
+	if last.YearDay() != now.YearDay() || last.Year() != now.Year() {
+		return false
+	}
+	return q.remaining <= reserve
+}
+
+// WaitBackoff sleeps until any backoff requested by the API has elapsed.
+func (q *stackExchangeQuota) WaitBackoff() {
+	q.mu.Lock()
+	wait := time.Until(q.backoffUntil)
+	q.mu.Unlock()
+	if wait > 0 {
+		time.Sleep(wait)
+	}
+}
+
+func (q *stackExchangeQuota) Status(reserve int) QuotaStatus {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	status := QuotaStatus{
+		QuotaRemaining: q.remaining,
+		QuotaMax:       q.max,
+		Exhausted:      q.exhaustedLocked(reserve),
+	}
+	if !q.updatedAt.IsZero() {
+		t := q.updatedAt
+		status.UpdatedAt = &t
+	}
+	if time.Now().Before(q.backoffUntil) {
+		t := q.backoffUntil
+		status.BackoffUntil = &t
+	}
+	return status
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// stackExchangeTeamPrefix marks a StackExchange "site" that is really a
+// private Stack Overflow for Teams instance, e.g. "teams:acme". Public API
+// site names never contain a colon.
+const stackExchangeTeamPrefix = "teams:"
+
+func stackExchangeTeamSite(slug string) string {
+	return stackExchangeTeamPrefix + slug
+}
+
+// stackExchangeGet calls the StackExchange API method at path (e.g.
+// "/search/advanced") for site. Public sites are selected with the site
+// parameter; Teams instances with the team parameter and the
+// X-API-Access-Token header. Transient failures are retried according to
+// apiRetry.
+func stackExchangeGet(ctx context.Context, cfg *Config, path, site string, query url.Values, out interface{}) error {
+	var header http.Header
+	if slug, ok := strings.CutPrefix(site, stackExchangeTeamPrefix); ok {
+		if cfg.StackOverflowTeamsToken == "" {
+			return fmt.Errorf("team %s: STACKOVERFLOW_TEAMS_TOKEN is not set", slug)
+		}
+		query.Set("team", "stackoverflow.com/c/"+slug)
+		header = http.Header{}
+		header.Set("X-API-Access-Token", cfg.StackOverflowTeamsToken)
+	} else {
+		query.Set("site", site)
+	}
+	if cfg.StackExchangeKey != "" {
+		query.Set("key", cfg.StackExchangeKey)
+	}
+	u := "https://api.stackexchange.com/2.3" + path + "?" + query.Encode()
+	return apiRetry.Do(ctx, "stackexchange", func() error { return getJSON(ctx, u, header, out) })
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// FrameworkStats summarizes what has been collected for a framework.
+// ItemsCollected is the number of items fetched across all collection
+// passes, refetches included.
+type FrameworkStats struct {
+	Framework      string     `json:"framework"`
+	Posts          int64      `json:"posts"`
+	Issues         int64      `json:"issues"`
+	Answers        int64      `json:"answers"`
+	LastFetchAt    *time.Time `json:"last_fetch_at"`
+	ItemsCollected int64      `json:"items_collected"`
+}
+
+// frameworkStats returns the stats of every framework found in db over
+// period, keyed by framework name.
+func frameworkStats(db *gorm.DB, period TimeRange) (map[string]*FrameworkStats, error) {
+	stats := map[string]*FrameworkStats{}
+	get := func(name string) *FrameworkStats {
+		if stats[name] == nil {
+			stats[name] = &FrameworkStats{Framework: name}
+		}
+		return stats[name]
+	}
+
+	var counts []struct {
+		Framework string
+		N         int64
+	}
+	for _, created := range []struct {
+		model  interface{}
+		column string
+	}{
+		{&StackOverflowPost{}, "creation_date"},
+		{&GitHubIssue{}, "issue_created_at"},
+		{&StackOverflowAnswer{}, "creation_date"},
+	} {
+		model := created.model
+		counts = nil
+		query := period.where(db.Model(model), created.column)
+		if err := query.Select("framework, COUNT(*) AS n").Group("framework").Scan(&counts).Error; err != nil {
+			return nil, err
+		}
+		for _, c := range counts {
+			switch model.(type) {
+			case *StackOverflowPost:
+				get(c.Framework).Posts = c.N
+			case *GitHubIssue:
+				get(c.Framework).Issues = c.N
+			default:
+				get(c.Framework).Answers = c.N
+			}
+		}
+	}
+
+	runs := func() *gorm.DB { return period.where(db.Model(&FetchRun{}), "started_at") }
+	counts = nil
+	if err := runs().Select("framework, SUM(items) AS n").Group("framework").Scan(&counts).Error; err != nil {
+		return nil, err
+	}
+	for _, c := range counts {
+		get(c.Framework).ItemsCollected = c.N
+	}
+
+	// The latest run is looked up by ID: aggregates over timestamps are
+	// returned as strings by sqlite.
+	var latest []FetchRun
+	err := db.Where("id IN (?)", runs().Select("MAX(id)").Group("framework")).Find(&latest).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, run := range latest {
+		finished := run.FinishedAt
+		get(run.Framework).LastFetchAt = &finished
+	}
+	return stats, nil
+}
+
+func (s gormStore) FrameworkStats(ctx context.Context, period TimeRange) (map[string]*FrameworkStats, error) {
+	return frameworkStats(s.db.WithContext(ctx), period)
+}
+
+// listFrameworkStats returns the stats over period of the frameworks in
+// the registry, even those nothing was collected for yet, followed by the
+// rest, and their totals.
+func listFrameworkStats(ctx context.Context, records Store, period TimeRange) ([]FrameworkStats, FrameworkStats, error) {
+	var totals FrameworkStats
+	stats, err := records.FrameworkStats(ctx, period)
+	if err != nil {
+		return nil, totals, err
+	}
+	frameworks, err := records.Frameworks(ctx)
+	if err != nil {
+		return nil, totals, err
+	}
+
+	result := make([]FrameworkStats, 0, len(stats))
+	seen := map[string]bool{}
+	for _, framework := range frameworks {
+		seen[framework.Name] = true
+		if s := stats[framework.Name]; s != nil {
+			result = append(result, *s)
+		} else {
+			result = append(result, FrameworkStats{Framework: framework.Name})
+		}
+	}
+	// Records of frameworks since removed from the registry, or collected
+	// before records carried their framework, come last.
+	var rest []string
+	for name := range stats {
+		if !seen[name] {
+			rest = append(rest, name)
+		}
+	}
+	sort.Slice(rest, func(i, j int) bool { return strings.ToLower(rest[i]) < strings.ToLower(rest[j]) })
+	for _, name := range rest {
+		result = append(result, *stats[name])
+	}
+
+	for _, s := range result {
+		totals.Posts += s.Posts
+		totals.Issues += s.Issues
+		totals.Answers += s.Answers
+		totals.ItemsCollected += s.ItemsCollected
+	}
+	return result, totals, nil
+}
+
+// statsHandler serves GET /api/v1/stats, the per-framework counts of stored
+// records and collection activity, along with their totals, optionally
+// only over ?from= and ?to=.
+func statsHandler(records Store) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		period, err := timeRangeParams(c)
+		if err != nil {
+			return err
+		}
+		result, totals, err := listFrameworkStats(c.UserContext(), records, period)
+		if err != nil {
+			return err
+		}
+		return c.JSON(fiber.Map{
+			"frameworks": result,
+			"totals": fiber.Map{
+				"posts":           totals.Posts,
+				"issues":          totals.Issues,
+				"answers":         totals.Answers,
+				"items_collected": totals.ItemsCollected,
+			},
+		})
+	}
+}
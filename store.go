@@ -0,0 +1,447 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Values of STORAGE_BACKEND, which selects where collected items are kept.
+const (
+	storageSQL     = "sql"
+	storageMongoDB = "mongodb"
+)
+
+// Store is the persistence layer used by the collection pipeline and the
+// read endpoints. gormStore is the relational implementation; the MongoDB
+// backend and the search, vector and event writers wrap another Store and
+// override Save to add their own handling.
+type Store interface {
+	// Frameworks returns the framework registry in insertion order.
+	Frameworks(ctx context.Context) ([]Framework, error)
+	// Save inserts the items or updates their stored copies. Backends
+	// that support transactions store the batch all or nothing.
+	Save(ctx context.Context, items ...Item) error
+	// ListPosts and ListIssues return one page of matching records along
+	// with the total number of matches.
+	ListPosts(ctx context.Context, filter PostFilter) ([]StackOverflowPost, int64, error)
+	ListIssues(ctx context.Context, filter IssueFilter) ([]GitHubIssue, int64, error)
+	// ListTags returns tags by the number of posts carrying them, most
+	// used first.
+	ListTags(ctx context.Context, filter TagFilter) ([]TagCount, error)
+	// FrameworkStats returns per-framework counts of stored records
+	// created and of fetch runs started in period, keyed by framework
+	// name.
+	FrameworkStats(ctx context.Context, period TimeRange) (map[string]*FrameworkStats, error)
+	// ListRuns returns one page of matching fetch runs along with the
+	// total number of matches.
+	ListRuns(ctx context.Context, filter RunFilter) ([]FetchRun, int64, error)
+	// Search returns the posts and issues matching a search query, best
+	// matches first where the backend ranks them.
+	Search(ctx context.Context, filter SearchFilter) (searchResponse, error)
+	// PurgePosts and PurgeIssues remove the posts or issues matching filter
+	// and return how many were removed.
+	PurgePosts(ctx context.Context, filter PurgeFilter) (int64, error)
+	PurgeIssues(ctx context.Context, filter PurgeFilter) (int64, error)
+	// Ping checks that the backend is reachable.
+	Ping(ctx context.Context) error
+	Close(ctx context.Context) error
+}
+
+// SortOrder orders a listing by Field, one of the keys accepted by the
+// listing's ?sort= parameter, descending unless Ascending. An empty Field
+// keeps the listing's default order.
+type SortOrder struct {
+	Field     string
+	Ascending bool
+}
+
+// TimeRange keeps the records created at or after From and before To,
+// at their source. A zero bound leaves that side open.
+type TimeRange struct {
+	From time.Time
+	To   time.Time
+}
+
+// where restricts query to the rows whose column falls in r.
+func (r TimeRange) where(query *gorm.DB, column string) *gorm.DB {
+	if !r.From.IsZero() {
+		query = query.Where(column+" >= ?", r.From)
+	}
+	if !r.To.IsZero() {
+		query = query.Where(column+" < ?", r.To)
+	}
+	return query
+}
+
+// PostFilter selects StackOverflow posts. Framework and Text match
+// case-insensitively, Text against the title or body, and TimeRange
+// against when the question was asked. A zero Limit means no limit.
+type PostFilter struct {
+	Site      string
+	Framework string
+	Tag       string
+	Text      string
+	TimeRange
+	Sort   SortOrder
+	Limit  int
+	Offset int
+	// After, which requires the default order, keeps the posts following
+	// it, for keyset pagination.
+	After *PostCursor
+}
+
+// PostCursor is the position of a post in the default order of the post
+// listing: newest stored first, then by site and question ID.
+type PostCursor struct {
+	CreatedAt  time.Time `json:"c"`
+	Site       string    `json:"s"`
+	QuestionID int       `json:"q"`
+}
+
+// IssueCursor is the position of an issue in the default order of the
+// issue listing, highest ID first.
+type IssueCursor struct {
+	ID int `json:"i"`
+}
+
+// errCursorUnsupported is returned by backends that cannot resume a
+// listing after a cursor.
+var errCursorUnsupported = errors.New("cursor pagination is not supported by this storage backend")
+
+// PurgeFilter selects the posts or issues to purge: those of Framework,
+// matched case-insensitively, created before Before, by StackExchange or
+// GitHub. Zero fields match everything.
+type PurgeFilter struct {
+	Framework string
+	Before    time.Time
+}
+
+// IssueFilter selects GitHub issues; see PostFilter. Repo matches
+// case-insensitively, State and Label exactly, and TimeRange against when
+// the issue was opened.
+type IssueFilter struct {
+	Repo  string
+	State string
+	Label string
+	Text  string
+	TimeRange
+	Sort   SortOrder
+	Limit  int
+	Offset int
+	After  *IssueCursor
+}
+
+// SearchFilter is a search query. Kind is post, issue or empty for both.
+type SearchFilter struct {
+	Query string
+	Kind  string
+	Limit int
+}
+
+// gormStore keeps everything in the relational database. partitions is
+// set when DB_PARTITION_BY is.
+type gormStore struct {
+	db         *gorm.DB
+	partitions *partitioner
+}
+
+func newGormStore(db *gorm.DB, cfg DatabaseConfig) (gormStore, error) {
+	store := gormStore{db: db}
+	if cfg.PartitionBy == "" {
+		return store, nil
+	}
+	if err := checkPartitioning(primary(db), cfg.PartitionBy); err != nil {
+		return store, err
+	}
+	store.partitions = &partitioner{scheme: cfg.PartitionBy, created: map[string]bool{}}
+	return store, nil
+}
+
+func (s gormStore) Frameworks(ctx context.Context) ([]Framework, error) {
+	return loadFrameworks(s.db.WithContext(ctx))
+}
+
+// Save stores the items in a single transaction, after creating any
+// partition they go into that does not exist yet.
+func (s gormStore) Save(ctx context.Context, items ...Item) error {
+	if len(items) == 0 {
+		return nil
+	}
+	if s.partitions != nil {
+		if err := s.partitions.ensure(s.db.WithContext(ctx), items); err != nil {
+			return err
+		}
+		ctx = withPartitioning(ctx, s.partitions.scheme)
+	}
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, item := range items {
+			if err := item.Save(tx); err != nil {
+				return fmt.Errorf("storing %T: %w", item, err)
+			}
+		}
+		return nil
+	})
+}
+
+// ListPosts returns the most recently collected posts first, with their
+// tags and their answers, highest scored first.
+func (s gormStore) ListPosts(ctx context.Context, filter PostFilter) ([]StackOverflowPost, int64, error) {
+	query := s.db.WithContext(ctx).Model(&StackOverflowPost{})
+	if filter.Site != "" {
+		query = query.Where("site = ?", filter.Site)
+	}
+	if filter.Framework != "" {
+		query = query.Where("LOWER(framework) = ?", strings.ToLower(filter.Framework))
+	}
+	query = filter.TimeRange.where(query, "creation_date")
+	if filter.Tag != "" {
+		query = query.Where("EXISTS (SELECT 1 FROM post_tags WHERE post_tags.question_id = stack_overflow_posts.question_id AND post_tags.site = stack_overflow_posts.site AND post_tags.tag_name = ?)", filter.Tag)
+	}
+	query = whereText(query, filter.Text)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	// The cursor applies after counting so that total covers every page.
+	if after := filter.After; after != nil {
+		query = query.Where("created_at < ? OR (created_at = ? AND (site > ? OR (site = ? AND question_id > ?)))",
+			after.CreatedAt, after.CreatedAt, after.Site, after.Site, after.QuestionID)
+	}
+	var posts []StackOverflowPost
+	answers := func(db *gorm.DB) *gorm.DB { return db.Order("score DESC") }
+	query = paginate(orderBy(query, postSortColumns, filter.Sort, "created_at", "site", "question_id"), filter.Limit, filter.Offset)
+	if err := query.Preload("Tags").Preload("Answers", answers).Find(&posts).Error; err != nil {
+		return nil, 0, err
+	}
+	return posts, total, nil
+}
+
+func (s gormStore) ListIssues(ctx context.Context, filter IssueFilter) ([]GitHubIssue, int64, error) {
+	query := whereText(s.db.WithContext(ctx).Model(&GitHubIssue{}), filter.Text)
+	if filter.Repo != "" {
+		query = query.Where("LOWER(repo) = ?", strings.ToLower(filter.Repo))
+	}
+	if filter.State != "" {
+		query = query.Where("state = ?", filter.State)
+	}
+	if filter.Label != "" {
+		// Labels are stored as a JSON array of strings.
+		label, _ := json.Marshal(filter.Label)
+		query = query.Where("labels LIKE ? ESCAPE '!'", "%"+likeEscaper.Replace(string(label))+"%")
+	}
+	query = filter.TimeRange.where(query, "issue_created_at")
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if filter.After != nil {
+		query = query.Where("id < ?", filter.After.ID)
+	}
+	var issues []GitHubIssue
+	if err := paginate(orderBy(query, issueSortColumns, filter.Sort, "id", "id"), filter.Limit, filter.Offset).Find(&issues).Error; err != nil {
+		return nil, 0, err
+	}
+	return issues, total, nil
+}
+
+// ListTags counts the posts carrying each tag, ignoring purged posts.
+func (s gormStore) ListTags(ctx context.Context, filter TagFilter) ([]TagCount, error) {
+	posts := s.db.Model(&StackOverflowPost{}).Select("1").
+		Where("stack_overflow_posts.question_id = post_tags.question_id AND stack_overflow_posts.site = post_tags.site")
+	if filter.Framework != "" {
+		posts = posts.Where("LOWER(stack_overflow_posts.framework) = ?", strings.ToLower(filter.Framework))
+	}
+	posts = filter.TimeRange.where(posts, "stack_overflow_posts.creation_date")
+	query := s.db.WithContext(ctx).Table("post_tags").
+		Select("tag_name AS name, COUNT(*) AS posts").
+		Where("EXISTS (?)", posts).
+		Group("tag_name").
+		Order("posts DESC, name")
+	if filter.Site != "" {
+		query = query.Where("post_tags.site = ?", filter.Site)
+	}
+
+	tags := []TagCount{}
+	if err := paginate(query, filter.Limit, 0).Scan(&tags).Error; err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+func (gormStore) Close(context.Context) error { return nil }
+
+var deletedAtType = reflect.TypeOf(gorm.DeletedAt{})
+
+// upsert inserts value, or overwrites the stored row when one with the
+// same conflict columns, by default the primary key, already exists. The
+// creation and soft-deletion timestamps of a stored row are kept.
+func upsert(db *gorm.DB, value interface{}, conflict ...string) error {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(value); err != nil {
+		return err
+	}
+
+	var target []clause.Column
+	for _, name := range conflict {
+		target = append(target, clause.Column{Name: name})
+	}
+	if len(target) == 0 {
+		for _, field := range stmt.Schema.PrimaryFields {
+			target = append(target, clause.Column{Name: field.DBName})
+		}
+	}
+
+	// Unique keys of a partitioned table include its partition column.
+	if column := partitionColumn(db.Statement.Context, stmt.Table); column != "" {
+		target = append(target, clause.Column{Name: column})
+		conflict = append(slices.Clip(conflict), column)
+	}
+
+	var columns []string
+	for _, field := range stmt.Schema.Fields {
+		if field.DBName == "" || !field.Updatable || field.PrimaryKey || field.AutoCreateTime != 0 ||
+			field.FieldType == deletedAtType || slices.Contains(conflict, field.DBName) {
+			continue
+		}
+		columns = append(columns, field.DBName)
+	}
+
+	return db.Clauses(clause.OnConflict{Columns: target, DoUpdates: clause.AssignmentColumns(columns)}).Create(value).Error
+}
+
+func whereText(query *gorm.DB, text string) *gorm.DB {
+	if text == "" {
+		return query
+	}
+	pattern := "%" + strings.ToLower(text) + "%"
+	return query.Where("LOWER(title) LIKE ? OR LOWER(body) LIKE ?", pattern, pattern)
+}
+
+// PurgePosts soft-deletes the matching posts, so that collecting them
+// again does not bring them back.
+func (s gormStore) PurgePosts(ctx context.Context, filter PurgeFilter) (int64, error) {
+	return purge(s.db.WithContext(ctx), &StackOverflowPost{}, "creation_date", filter)
+}
+
+// PurgeIssues soft-deletes the matching issues; see PurgePosts.
+func (s gormStore) PurgeIssues(ctx context.Context, filter PurgeFilter) (int64, error) {
+	return purge(s.db.WithContext(ctx), &GitHubIssue{}, "issue_created_at", filter)
+}
+
+// purge soft-deletes the rows of model matching filter, where createdColumn
+// holds when the source created them.
+func purge(db *gorm.DB, model interface{}, createdColumn string, filter PurgeFilter) (int64, error) {
+	query := db.Session(&gorm.Session{AllowGlobalUpdate: true})
+	if filter.Framework != "" {
+		query = query.Where("LOWER(framework) = ?", strings.ToLower(filter.Framework))
+	}
+	if !filter.Before.IsZero() {
+		query = query.Where(createdColumn+" < ?", filter.Before)
+	}
+	result := query.Delete(model)
+	return result.RowsAffected, result.Error
+}
+
+func (s gormStore) Ping(ctx context.Context) error {
+	sqlDB, err := s.db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+// Search uses Postgres full-text search, and falls back to substring
+// matching on the other drivers.
+func (s gormStore) Search(ctx context.Context, filter SearchFilter) (searchResponse, error) {
+	if s.db.Dialector.Name() == driverPostgres {
+		return searchPostgres(s.db.WithContext(ctx), filter)
+	}
+	return searchByListing(ctx, s, filter)
+}
+
+// likeEscaper escapes the LIKE wildcards for use with ESCAPE '!'.
+var likeEscaper = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
+
+// Columns the listings sort by, keyed by the names of the ?sort= values,
+// which are those of the JSON fields.
+var (
+	postSortColumns  = map[string]string{"score": "score", "created_at": "created_at", "updated_at": "updated_at", "creation_date": "creation_date"}
+	issueSortColumns = map[string]string{"score": "reactions", "created_at": "issue_created_at", "updated_at": "issue_updated_at"}
+)
+
+// orderBy orders query by the column of order, or by defaultColumn
+// descending when order has no field, then by the key columns so that
+// pages stay stable among equal values.
+func orderBy(query *gorm.DB, columns map[string]string, order SortOrder, defaultColumn string, keys ...string) *gorm.DB {
+	column, ok := columns[order.Field]
+	if !ok {
+		column, order.Ascending = defaultColumn, false
+	}
+	query = query.Order(clause.OrderByColumn{Column: clause.Column{Name: column}, Desc: !order.Ascending})
+	for _, key := range keys {
+		if key != column {
+			query = query.Order(clause.OrderByColumn{Column: clause.Column{Name: key}})
+		}
+	}
+	return query
+}
+
+func paginate(query *gorm.DB, limit, offset int) *gorm.DB {
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if offset > 0 {
+		query = query.Offset(offset)
+	}
+	return query
+}
+
+// newStore returns the backend selected by STORAGE_BACKEND, notifying the
+// registered webhooks, indexing posts and issues into Elasticsearch and
+// Milvus and recording fetch events in ClickHouse as well when those are
+// configured. The framework registry
+// always lives in db, and so do the records a document backend does not
+// handle.
+func newStore(ctx context.Context, db *gorm.DB, cfg *Config) (Store, error) {
+	relational, err := newGormStore(db, cfg.Database)
+	if err != nil {
+		return nil, err
+	}
+
+	var store Store
+	switch cfg.StorageBackend {
+	case storageSQL:
+		store = relational
+	case storageMongoDB:
+		mongo, err := newMongoStore(ctx, cfg.MongoDB, relational)
+		if err != nil {
+			return nil, err
+		}
+		store = mongo
+	default:
+		return nil, fmt.Errorf("unknown STORAGE_BACKEND %q", cfg.StorageBackend)
+	}
+	store = newWebhookDispatcher(db, store)
+
+	if cfg.Elasticsearch.Enabled() {
+		store = newSearchIndexer(cfg.Elasticsearch, store)
+	}
+	if cfg.Milvus.Enabled() {
+		store = newVectorIndexer(cfg.Milvus, cfg.Embedding, store)
+	}
+	if cfg.ClickHouse.Enabled() {
+		store = newEventWriter(cfg.ClickHouse, store)
+	}
+	return newItemNotifier(storedItems, store), nil
+}
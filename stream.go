@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// streamKeepAlive is how often GET /stream writes a comment line to keep
+// idle connections open through proxies.
+const streamKeepAlive = 15 * time.Second
+
+// streamBuffer is the number of events buffered per subscriber; events for
+// a subscriber that falls further behind are dropped.
+const streamBuffer = 64
+
+// streamEvent is a post or issue stored by a collection pass in this
+// process.
+type streamEvent struct {
+	Kind      string // post or issue
+	Framework string
+	Data      interface{}
+}
+
+type streamSubscriber struct {
+	framework string
+	events    chan streamEvent
+}
+
+// itemHub fans stored posts and issues out to the GET /stream clients.
+type itemHub struct {
+	mu   sync.Mutex
+	subs map[*streamSubscriber]struct{}
+}
+
+var storedItems = &itemHub{}
+
+// Subscribe registers a subscriber to the events of framework, matched
+// case-insensitively, or of every framework when it is empty.
+func (h *itemHub) Subscribe(framework string) *streamSubscriber {
+	sub := &streamSubscriber{framework: framework, events: make(chan streamEvent, streamBuffer)}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.subs == nil {
+		h.subs = map[*streamSubscriber]struct{}{}
+	}
+	h.subs[sub] = struct{}{}
+	return sub
+}
+
+func (h *itemHub) Unsubscribe(sub *streamSubscriber) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.subs, sub)
+}
+
+func (h *itemHub) Publish(ev streamEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for sub := range h.subs {
+		if sub.framework != "" && !strings.EqualFold(sub.framework, ev.Framework) {
+			continue
+		}
+		select {
+		case sub.events <- ev:
+		default:
+		}
+	}
+}
+
+// itemNotifier wraps a Store and publishes the posts and issues it stores
+// to storedItems.
+type itemNotifier struct {
+	Store
+	hub *itemHub
+}
+
+func newItemNotifier(hub *itemHub, next Store) *itemNotifier {
+	return &itemNotifier{Store: next, hub: hub}
+}
+
+func (n *itemNotifier) Save(ctx context.Context, items ...Item) error {
+	if err := n.Store.Save(ctx, items...); err != nil {
+		return err
+	}
+	for _, item := range items {
+		switch v := item.(type) {
+		case StackOverflowPost:
+			n.hub.Publish(streamEvent{Kind: "post", Framework: v.Framework, Data: v})
+		case GitHubIssue:
+			n.hub.Publish(streamEvent{Kind: "issue", Framework: v.Framework, Data: v})
+		}
+	}
+	return nil
+}
+
+// streamHandler serves GET /api/v1/stream, a server-sent event stream with a
+// post or issue event, carrying the record as JSON, each time one is
+// stored, optionally only for ?framework=. Records refreshed by a later
+// pass are sent again.
+func streamHandler(hub *itemHub) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		sub := hub.Subscribe(c.Query("framework"))
+
+		c.Set(fiber.HeaderContentType, "text/event-stream")
+		c.Set(fiber.HeaderCacheControl, "no-cache")
+		c.Set(fiber.HeaderConnection, "keep-alive")
+		c.Set("X-Accel-Buffering", "no")
+		c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+			defer hub.Unsubscribe(sub)
+			keepAlive := time.NewTicker(streamKeepAlive)
+			defer keepAlive.Stop()
+
+			// Flushing fails once the client has gone away.
+			fmt.Fprint(w, ": connected\n\n")
+			if w.Flush() != nil {
+				return
+			}
+			for {
+				select {
+				case ev := <-sub.events:
+					data, err := json.Marshal(ev.Data)
+					if err != nil {
+						continue
+					}
+					fmt.Fprintf(w, "event: %s\ndata: %s\n\n", ev.Kind, data)
+				case <-keepAlive.C:
+					fmt.Fprint(w, ": keep-alive\n\n")
+				}
+				if w.Flush() != nil {
+					return
+				}
+			}
+		})
+		return nil
+	}
+}
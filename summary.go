@@ -0,0 +1,77 @@
+package main
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// summaryTopTags is the number of tags listed by GET /frameworks/:name/summary.
+const summaryTopTags = 10
+
+// frameworkSummary is a framework's activity at a glance, as compared side
+// by side on a dashboard.
+type frameworkSummary struct {
+	Framework     string         `json:"framework"`
+	Questions     questionVolume `json:"questions"`
+	TopTags       []TagCount     `json:"top_tags"`
+	OpenIssues    int64          `json:"open_issues"`
+	LatestRelease *GitHubRelease `json:"latest_release"`
+}
+
+// questionVolume counts the stored questions asked in the last Days days
+// and overall.
+type questionVolume struct {
+	Days   int   `json:"days"`
+	Recent int64 `json:"recent"`
+	Total  int64 `json:"total"`
+}
+
+// frameworkSummaryHandler serves GET /api/v1/frameworks/:name/summary: the
+// questions asked in the last ?days= (default 30), the most used tags, the
+// number of open issues and the latest stable release of a framework.
+func frameworkSummaryHandler(db *gorm.DB, records Store) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		days, err := strconv.Atoi(c.Query("days", "30"))
+		if err != nil || days < 1 || days > 365 {
+			return fiber.NewError(fiber.StatusBadRequest, "days must be between 1 and 365")
+		}
+		framework, err := findFramework(db, c.Params("name"))
+		if err != nil {
+			return err
+		}
+
+		ctx := c.UserContext()
+		summary := frameworkSummary{Framework: framework.Name, Questions: questionVolume{Days: days}}
+		// Only the totals are wanted, so a single post or issue is loaded.
+		if _, summary.Questions.Total, err = records.ListPosts(ctx, PostFilter{Framework: framework.Name, Limit: 1}); err != nil {
+			return err
+		}
+		from := time.Now().UTC().AddDate(0, 0, -days)
+		if _, summary.Questions.Recent, err = records.ListPosts(ctx, PostFilter{Framework: framework.Name, TimeRange: TimeRange{From: from}, Limit: 1}); err != nil {
+			return err
+		}
+		if summary.TopTags, err = records.ListTags(ctx, TagFilter{Framework: framework.Name, Limit: summaryTopTags}); err != nil {
+			return err
+		}
+		if framework.GitHubRepo != "" {
+			if _, summary.OpenIssues, err = records.ListIssues(ctx, IssueFilter{Repo: framework.GitHubRepo, State: "open", Limit: 1}); err != nil {
+				return err
+			}
+		}
+
+		var release GitHubRelease
+		err = db.WithContext(ctx).Where("framework = ? AND NOT draft AND NOT prerelease AND published_at IS NOT NULL", framework.Name).
+			Order("published_at DESC").First(&release).Error
+		switch {
+		case err == nil:
+			summary.LatestRelease = &release
+		case !errors.Is(err, gorm.ErrRecordNotFound):
+			return err
+		}
+		return c.JSON(summary)
+	}
+}
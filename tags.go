@@ -0,0 +1,100 @@
+package main
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Tag is a StackExchange tag. Posts and tags are joined through post_tags
+// on the post's site and question ID. Tags appear as plain strings in JSON,
+// as they do in the StackExchange API.
+type Tag struct {
+	Name string `gorm:"primaryKey;size:191"`
+}
+
+func (t Tag) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.Name)
+}
+
+func (t *Tag) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &t.Name)
+}
+
+func (post StackOverflowPost) tagNames() []string {
+	names := make([]string, len(post.Tags))
+	for i, tag := range post.Tags {
+		names[i] = tag.Name
+	}
+	return names
+}
+
+// TagFilter selects the tags counted by Store.ListTags, optionally only
+// on one framework's posts, matched case-insensitively, or those asked in
+// TimeRange. A zero Limit means no limit.
+type TagFilter struct {
+	Site      string
+	Framework string
+	TimeRange
+	Limit int
+}
+
+// TagCount is the number of stored posts carrying a tag.
+type TagCount struct {
+	Name  string `json:"name"`
+	Posts int64  `json:"posts"`
+}
+
+// listTagsHandler serves GET /api/v1/tags, the most used tags across stored
+// posts, optionally restricted to one StackExchange site.
+func listTagsHandler(records Store) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		limit, err := strconv.Atoi(c.Query("limit", "50"))
+		if err != nil || limit < 1 || limit > 500 {
+			return fiber.NewError(fiber.StatusBadRequest, "limit must be between 1 and 500")
+		}
+
+		period, err := timeRangeParams(c)
+		if err != nil {
+			return err
+		}
+
+		tags, err := records.ListTags(c.UserContext(), TagFilter{Site: c.Query("site"), TimeRange: period, Limit: limit})
+		if err != nil {
+			return err
+		}
+		return c.JSON(tags)
+	}
+}
+
+// listTaggedPostsHandler serves GET /api/v1/tags/:name/posts, the stored posts
+// carrying a tag, most recently collected first.
+func listTaggedPostsHandler(records Store) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		limit, err := strconv.Atoi(c.Query("limit", "50"))
+		if err != nil || limit < 1 || limit > 500 {
+			return fiber.NewError(fiber.StatusBadRequest, "limit must be between 1 and 500")
+		}
+		offset, err := strconv.Atoi(c.Query("offset", "0"))
+		if err != nil || offset < 0 {
+			return fiber.NewError(fiber.StatusBadRequest, "offset must not be negative")
+		}
+		period, err := timeRangeParams(c)
+		if err != nil {
+			return err
+		}
+
+		posts, total, err := records.ListPosts(c.UserContext(), PostFilter{
+			Site:      c.Query("site"),
+			Tag:       c.Params("name"),
+			TimeRange: period,
+			Limit:     limit,
+			Offset:    offset,
+		})
+		if err != nil {
+			return err
+		}
+		return c.JSON(fiber.Map{"total": total, "posts": posts})
+	}
+}
@@ -0,0 +1,6 @@
+//go:build tools
+
+package main
+
+// Imported for `go generate`, which runs gqlgen at the version in go.mod.
+import _ "github.com/99designs/gqlgen"
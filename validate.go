@@ -0,0 +1,471 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/mod/module"
+)
+
+// ValidationError lists every problem found in a configuration so they can
+// all be fixed in one go.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+var (
+	// Classic 40-hex tokens and the prefixed fine-grained/OAuth/app formats.
+	githubTokenPattern   = regexp.MustCompile(`^([0-9a-f]{40}|(ghp|gho|ghu|ghs|ghr)_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})$`)
+	stackExchangeKeyRule = regexp.MustCompile(`^[A-Za-z0-9()*._-]+$`)
+	githubRepoPattern    = regexp.MustCompile(`^[A-Za-z0-9_.-]+/[A-Za-z0-9_.-]+$`)
+	subredditPattern     = regexp.MustCompile(`^[A-Za-z0-9_]{2,21}$`)
+	stackExchangeSite    = regexp.MustCompile(`^[a-z0-9.-]+$`)
+	hostnamePattern      = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9-]*[A-Za-z0-9])?(\.[A-Za-z0-9]([A-Za-z0-9-]*[A-Za-z0-9])?)+$`)
+	teamSlugPattern      = regexp.MustCompile(`^[a-z0-9-]+$`)
+	lobstersTagPattern   = regexp.MustCompile(`^[a-z0-9.-]+$`)
+	hashtagPattern       = regexp.MustCompile(`^[\pL\pN_]+$`)
+	pypiPackagePattern   = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9._-]*[A-Za-z0-9])?$`)
+	dockerImagePattern   = regexp.MustCompile(`^([a-z0-9]+([._-][a-z0-9]+)*/)?[a-z0-9]+([._-][a-z0-9]+)*$`)
+	npmPackagePattern    = regexp.MustCompile(`^(@[a-z0-9-~][a-z0-9-._~]*/)?[a-z0-9-~][a-z0-9-._~]*$`)
+	// esIndexPattern is a conservative subset of the allowed index names.
+	esIndexPattern    = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+	milvusNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]{0,254}$`)
+	s3BucketPattern   = regexp.MustCompile(`^[a-z0-9][a-z0-9.-]{1,61}[a-z0-9]$`)
+)
+
+// Validate checks the configuration before any server starts or external
+// API is called, returning a *ValidationError describing every problem.
+func (c *Config) Validate() error {
+	var problems []string
+	addf := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	switch c.LogLevel {
+	case logLevelDebug, logLevelInfo, logLevelWarn:
+	default:
+		addf("LOG_LEVEL %q must be one of %s, %s, %s", c.LogLevel, logLevelDebug, logLevelInfo, logLevelWarn)
+	}
+	if c.FetchLimit < 1 || c.FetchLimit > 100 {
+		addf("FETCH_LIMIT must be between 1 and 100, got %d", c.FetchLimit)
+	}
+	if c.FetchWorkers < 1 {
+		addf("FETCH_WORKERS must be at least 1, got %d", c.FetchWorkers)
+	}
+	if c.FetchQueueSize < 0 {
+		addf("FETCH_QUEUE_SIZE must not be negative, got %d", c.FetchQueueSize)
+	}
+	if c.FetchJobTimeout < 0 {
+		addf("FETCH_JOB_TIMEOUT must not be negative, got %s", c.FetchJobTimeout)
+	}
+	if c.FetchMaxAttempts < 1 {
+		addf("FETCH_MAX_ATTEMPTS must be at least 1, got %d", c.FetchMaxAttempts)
+	}
+	if c.FetchRetryDelay < 0 {
+		addf("FETCH_RETRY_DELAY must not be negative, got %s", c.FetchRetryDelay)
+	}
+	if c.BreakerFailures < 0 {
+		addf("BREAKER_FAILURES must not be negative, got %d", c.BreakerFailures)
+	}
+	if c.BreakerFailures > 0 && c.BreakerCooldown <= 0 {
+		addf("BREAKER_COOLDOWN must be positive, got %s", c.BreakerCooldown)
+	}
+	if c.CommentLimit < 0 {
+		addf("COMMENT_LIMIT must not be negative, got %d", c.CommentLimit)
+	}
+	if c.StackExchangeQuotaReserve < 0 {
+		addf("STACKEXCHANGE_QUOTA_RESERVE must not be negative, got %d", c.StackExchangeQuotaReserve)
+	}
+
+	problems = append(problems, c.Database.validate()...)
+	switch c.StorageBackend {
+	case storageSQL:
+	case storageMongoDB:
+		if !strings.HasPrefix(c.MongoDB.URI, "mongodb://") && !strings.HasPrefix(c.MongoDB.URI, "mongodb+srv://") {
+			addf("STORAGE_BACKEND=mongodb requires a mongodb:// or mongodb+srv:// MONGODB_URI")
+		}
+		if c.MongoDB.Database == "" {
+			addf("MONGODB_DATABASE must not be empty")
+		}
+	default:
+		addf("STORAGE_BACKEND %q must be %s or %s", c.StorageBackend, storageSQL, storageMongoDB)
+	}
+	if c.Elasticsearch.Enabled() {
+		if !validURL(c.Elasticsearch.URL) {
+			addf("ELASTICSEARCH_URL %q is not a valid URL", redactURL(c.Elasticsearch.URL))
+		}
+		if !esIndexPattern.MatchString(c.Elasticsearch.Index) {
+			addf("ELASTICSEARCH_INDEX %q is not a valid index name", c.Elasticsearch.Index)
+		}
+	}
+	if c.Archive.Enabled() {
+		if !s3BucketPattern.MatchString(c.Archive.Bucket) {
+			addf("ARCHIVE_BUCKET %q is not a valid bucket name", c.Archive.Bucket)
+		}
+		if c.Archive.Endpoint != "" && !validURL(c.Archive.Endpoint) {
+			addf("ARCHIVE_ENDPOINT %q is not a valid URL", c.Archive.Endpoint)
+		}
+	}
+	if c.Cache.Enabled() {
+		if _, err := redis.ParseURL(c.Cache.RedisURL); err != nil {
+			addf("REDIS_URL is invalid: %v", err)
+		}
+		if c.Cache.TTL < 0 {
+			addf("CACHE_TTL must not be negative, got %s", c.Cache.TTL)
+		}
+	}
+	if c.Milvus.Enabled() {
+		if !validURL(c.Milvus.URL) {
+			addf("MILVUS_URL %q is not a valid URL", redactURL(c.Milvus.URL))
+		}
+		if !milvusNamePattern.MatchString(c.Milvus.Collection) {
+			addf("MILVUS_COLLECTION %q is not a valid collection name", c.Milvus.Collection)
+		}
+		if !validURL(c.Embedding.URL) {
+			addf("EMBEDDINGS_URL %q is not a valid URL", c.Embedding.URL)
+		}
+		if c.Embedding.Model == "" {
+			addf("EMBEDDINGS_MODEL must not be empty")
+		}
+		if c.Embedding.Dimensions < 1 || c.Embedding.Dimensions > 32768 {
+			addf("EMBEDDINGS_DIMENSIONS must be between 1 and 32768, got %d", c.Embedding.Dimensions)
+		}
+	}
+	if c.JWT.PublicKey != "" {
+		if _, err := jwt.ParseRSAPublicKeyFromPEM([]byte(c.JWT.PublicKey)); err != nil {
+			addf("JWT_PUBLIC_KEY_FILE is not a PEM-encoded RSA public key: %v", err)
+		}
+	}
+	if c.JWT.Secret != "" && len(c.JWT.Secret) < 32 {
+		addf("JWT_SECRET must be at least 32 characters long")
+	}
+	if _, ok := compressionLevels[c.CompressionLevel]; !ok {
+		addf("COMPRESSION_LEVEL must be off, speed, default or best, got %q", c.CompressionLevel)
+	}
+	for _, origin := range c.CORS.AllowedOrigins {
+		switch {
+		case origin == "*":
+			if c.CORS.AllowCredentials {
+				addf("CORS_ALLOWED_ORIGINS must list origins instead of * when CORS_ALLOW_CREDENTIALS is set")
+			}
+		case !validOrigin(origin):
+			addf("CORS_ALLOWED_ORIGINS entry %q must be * or an origin such as https://dashboard.example.com", origin)
+		}
+	}
+	if c.CORS.MaxAge < 0 {
+		addf("CORS_MAX_AGE must not be negative, got %s", c.CORS.MaxAge)
+	}
+	if c.RateLimit.Requests < 0 {
+		addf("RATE_LIMIT must not be negative, got %d", c.RateLimit.Requests)
+	}
+	if c.RateLimit.Requests > 0 && c.RateLimit.Window <= 0 {
+		addf("RATE_LIMIT_WINDOW must be positive, got %s", c.RateLimit.Window)
+	}
+	if c.RateLimit.FetchRequests < 0 {
+		addf("FETCH_RATE_LIMIT must not be negative, got %d", c.RateLimit.FetchRequests)
+	}
+	if c.RateLimit.FetchRequests > 0 && c.RateLimit.FetchWindow <= 0 {
+		addf("FETCH_RATE_LIMIT_WINDOW must be positive, got %s", c.RateLimit.FetchWindow)
+	}
+	var sources []string
+	for _, src := range newSources(c) {
+		sources = append(sources, src.Name())
+	}
+	if _, err := parseFetchSchedule(c.FetchSchedule); err != nil {
+		addf("FETCH_SCHEDULE %q is not a valid cron expression: %v", c.FetchSchedule, err)
+	}
+	for source, spec := range c.SourceSchedules {
+		if !slices.Contains(sources, source) {
+			addf("FETCH_SCHEDULE_%s: unknown source %q", strings.ToUpper(source), source)
+		} else if _, err := parseFetchSchedule(spec); err != nil {
+			addf("FETCH_SCHEDULE_%s %q is not a valid cron expression: %v", strings.ToUpper(source), spec, err)
+		}
+	}
+	if c.ExportInterval < 0 {
+		addf("EXPORT_INTERVAL must not be negative, got %s", c.ExportInterval)
+	}
+	if bucket, ok := strings.CutPrefix(c.ExportDest, "s3://"); ok {
+		bucket, _, _ = strings.Cut(bucket, "/")
+		if !s3BucketPattern.MatchString(bucket) {
+			addf("EXPORT_DEST bucket %q is not a valid bucket name", bucket)
+		}
+	} else if c.ExportDest == "" {
+		addf("EXPORT_DEST must not be empty")
+	}
+	if c.ClickHouse.Enabled() {
+		if !validURL(c.ClickHouse.URL) {
+			addf("CLICKHOUSE_URL %q is not a valid URL", redactURL(c.ClickHouse.URL))
+		}
+		if c.ClickHouse.Database == "" {
+			addf("CLICKHOUSE_DATABASE must not be empty")
+		}
+	}
+
+	if len(c.GitHubTokens) == 0 && c.usesGitHub() {
+		addf("GITHUB_TOKEN or GITHUB_TOKENS is required")
+	}
+	switch c.GitHubAPI {
+	case githubAPIREST, githubAPIGraphQL:
+	default:
+		addf("GITHUB_API %q must be %s or %s", c.GitHubAPI, githubAPIREST, githubAPIGraphQL)
+	}
+	for i, token := range c.GitHubTokens {
+		if !githubTokenPattern.MatchString(token) {
+			addf("GitHub token %d does not look like a GitHub personal access token", i)
+		}
+	}
+	if c.StackExchangeKey != "" && !stackExchangeKeyRule.MatchString(c.StackExchangeKey) {
+		addf("STACKEXCHANGE_KEY contains invalid characters")
+	}
+
+	httpPort, httpOK := validatePort(c.HTTPPort)
+	if !httpOK {
+		addf("PORT %q is not a valid TCP port", c.HTTPPort)
+	}
+	if !c.MetricsOnAppPort {
+		metricsPort, metricsOK := validatePort(c.MetricsPort)
+		if !metricsOK {
+			addf("METRICS_PORT %q is not a valid TCP port", c.MetricsPort)
+		}
+		if httpOK && metricsOK && httpPort == metricsPort {
+			addf("PORT and METRICS_PORT must differ (or set METRICS_SINGLE_PORT), both are %d", httpPort)
+		}
+	}
+	if c.GRPCPort != "" {
+		grpcPort, grpcOK := validatePort(c.GRPCPort)
+		metricsPort, _ := validatePort(c.MetricsPort)
+		switch {
+		case !grpcOK:
+			addf("GRPC_PORT %q is not a valid TCP port", c.GRPCPort)
+		case grpcPort == httpPort || (!c.MetricsOnAppPort && grpcPort == metricsPort):
+			addf("GRPC_PORT %d must differ from PORT and METRICS_PORT", grpcPort)
+		}
+	}
+
+	if c.SecretBackend == "vault" {
+		if !validURL(c.Vault.Addr) {
+			addf("VAULT_ADDR %q is not a valid URL", c.Vault.Addr)
+		}
+	}
+
+	if c.Flags.Enabled(flagSourceGitLab) && !validURL(c.GitLab.URL) {
+		addf("GITLAB_URL %q is not a valid URL", c.GitLab.URL)
+	}
+
+	if c.Jira.Enabled() {
+		if !validURL(c.Jira.URL) {
+			addf("JIRA_URL %q is not a valid URL", c.Jira.URL)
+		}
+		if c.Jira.Email == "" || c.Jira.Token == "" {
+			addf("JIRA_EMAIL and JIRA_TOKEN are required when JIRA_URL is set")
+		}
+	}
+
+	if c.Bitbucket.AppPassword != "" && c.Bitbucket.Username == "" {
+		addf("BITBUCKET_USERNAME is required when a Bitbucket app password is set")
+	}
+
+	for _, instance := range c.MastodonInstances {
+		if !hostnamePattern.MatchString(instance) {
+			addf("MASTODON_INSTANCES entry %q must be a host name such as mastodon.social", instance)
+		}
+	}
+
+	for _, name := range c.Flags.unknown() {
+		addf("unknown feature flag %q", name)
+	}
+
+	seen := map[string]bool{}
+	for i, f := range c.Frameworks {
+		label := fmt.Sprintf("framework %d", i+1)
+		if f.Name != "" {
+			label = fmt.Sprintf("framework %q", f.Name)
+			if seen[strings.ToLower(f.Name)] {
+				addf("%s: duplicate name", label)
+			}
+			seen[strings.ToLower(f.Name)] = true
+		}
+		for _, problem := range f.validate() {
+			addf("%s: %s", label, problem)
+		}
+		if f.StackOverflowTeam != "" && c.StackOverflowTeamsToken == "" {
+			addf("%s: STACKOVERFLOW_TEAMS_TOKEN is required for stackoverflow_team", label)
+		}
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// validate checks a single framework entry, whether it comes from the
+// config file or the framework registry API.
+func (f Framework) validate() []string {
+	var problems []string
+	if strings.TrimSpace(f.Name) == "" {
+		problems = append(problems, "name must not be empty")
+	}
+	if !f.hasSource() {
+		problems = append(problems, "at least one source setting, such as stackoverflow_tag or github_repo, must be set")
+	}
+	if f.GitHubRepo != "" && !githubRepoPattern.MatchString(f.GitHubRepo) {
+		problems = append(problems, fmt.Sprintf("github_repo %q must be in owner/name form", f.GitHubRepo))
+	}
+	if f.BitbucketRepo != "" && !githubRepoPattern.MatchString(f.BitbucketRepo) {
+		problems = append(problems, fmt.Sprintf("bitbucket_repo %q must be in workspace/repo form", f.BitbucketRepo))
+	}
+	if f.DiscourseURL != "" && !validURL(f.DiscourseURL) {
+		problems = append(problems, fmt.Sprintf("discourse_url %q is not a valid URL", f.DiscourseURL))
+	}
+	for _, feed := range f.Feeds {
+		if !validURL(feed) {
+			problems = append(problems, fmt.Sprintf("feed %q is not a valid URL", feed))
+		}
+	}
+	if f.NpmPackage != "" && !npmPackagePattern.MatchString(f.NpmPackage) {
+		problems = append(problems, fmt.Sprintf("npm_package %q is not a valid npm package name", f.NpmPackage))
+	}
+	if f.PyPIPackage != "" && !pypiPackagePattern.MatchString(f.PyPIPackage) {
+		problems = append(problems, fmt.Sprintf("pypi_package %q is not a valid PyPI project name", f.PyPIPackage))
+	}
+	if f.GoModule != "" {
+		if err := module.CheckPath(f.GoModule); err != nil {
+			problems = append(problems, fmt.Sprintf("go_module: %v", err))
+		}
+	}
+	for _, image := range f.DockerImages {
+		if !dockerImagePattern.MatchString(image) {
+			problems = append(problems, fmt.Sprintf("docker image %q must be a Docker Hub repository such as prom/prometheus", image))
+		}
+	}
+	for _, tag := range f.MastodonHashtags {
+		if !hashtagPattern.MatchString(tag) {
+			problems = append(problems, fmt.Sprintf("mastodon hashtag %q must be letters, digits or underscores without the leading #", tag))
+		}
+	}
+	for _, tag := range f.LobstersTags {
+		if !lobstersTagPattern.MatchString(tag) {
+			problems = append(problems, fmt.Sprintf("lobsters tag %q must be lowercase letters, digits, dots or dashes", tag))
+		}
+	}
+	if f.StackOverflowTeam != "" && !teamSlugPattern.MatchString(f.StackOverflowTeam) {
+		problems = append(problems, fmt.Sprintf("stackoverflow_team %q must be a Teams slug such as acme", f.StackOverflowTeam))
+	}
+	for _, site := range f.StackExchangeSites {
+		if !stackExchangeSite.MatchString(site) {
+			problems = append(problems, fmt.Sprintf("stackexchange site %q must be an API site parameter such as serverfault", site))
+		}
+	}
+	for _, sub := range f.Subreddits {
+		if !subredditPattern.MatchString(sub) {
+			problems = append(problems, fmt.Sprintf("subreddit %q is not a valid subreddit name", sub))
+		}
+	}
+	return problems
+}
+
+func (d DatabaseConfig) validate() []string {
+	var problems []string
+	// database/sql lowers MaxIdleConns to MaxOpenConns by itself.
+	if d.MaxOpenConns < 0 || d.MaxIdleConns < 0 {
+		problems = append(problems, "DB_MAX_OPEN_CONNS and DB_MAX_IDLE_CONNS must not be negative")
+	}
+	if d.ConnMaxLifetime < 0 || d.ConnMaxIdleTime < 0 {
+		problems = append(problems, "DB_CONN_MAX_LIFETIME and DB_CONN_MAX_IDLE_TIME must not be negative")
+	}
+	switch d.PartitionBy {
+	case "":
+	case partitionByMonth, partitionByFramework:
+		if d.Driver != driverPostgres {
+			problems = append(problems, "DB_PARTITION_BY requires the postgres driver")
+		}
+	default:
+		problems = append(problems, fmt.Sprintf("DB_PARTITION_BY %q must be %s or %s", d.PartitionBy, partitionByMonth, partitionByFramework))
+	}
+	switch d.Driver {
+	case driverSQLite:
+		if d.SQLitePath == "" {
+			problems = append(problems, "SQLITE_PATH must not be empty")
+		}
+		if len(d.ReplicaURLs) > 0 {
+			problems = append(problems, "DATABASE_REPLICA_URLS is not supported with sqlite")
+		}
+	case driverPostgres, driverMySQL:
+		if d.URL == "" && d.Password == "" {
+			problems = append(problems, "DB_PASSWORD is required")
+		}
+		if d.URL == "" {
+			if d.Host == "" || d.User == "" || d.Name == "" {
+				problems = append(problems, "DB_HOST, DB_USER and DB_NAME must not be empty")
+			}
+			if _, ok := validatePort(d.Port); !ok {
+				problems = append(problems, fmt.Sprintf("DB_PORT %q is not a valid TCP port", d.Port))
+			}
+		}
+		if d.Driver == driverMySQL {
+			// Without parseTime, DATETIME columns cannot be scanned into time.Time.
+			if mc, err := mysql.ParseDSN(d.DSN()); err != nil {
+				problems = append(problems, fmt.Sprintf("database DSN is invalid: %v", err))
+			} else if !mc.ParseTime {
+				problems = append(problems, "MySQL DATABASE_URL must set parseTime=true")
+			}
+		} else if _, err := pgconn.ParseConfig(d.DSN()); err != nil {
+			problems = append(problems, fmt.Sprintf("database DSN is invalid: %v", err))
+		}
+		for i, replica := range d.ReplicaURLs {
+			if d.Driver == driverMySQL {
+				if mc, err := mysql.ParseDSN(replica); err != nil {
+					problems = append(problems, fmt.Sprintf("DATABASE_REPLICA_URLS entry %d is invalid: %v", i+1, err))
+				} else if !mc.ParseTime {
+					problems = append(problems, fmt.Sprintf("MySQL DATABASE_REPLICA_URLS entry %d must set parseTime=true", i+1))
+				}
+			} else if _, err := pgconn.ParseConfig(replica); err != nil {
+				problems = append(problems, fmt.Sprintf("DATABASE_REPLICA_URLS entry %d is invalid: %v", i+1, err))
+			}
+		}
+	default:
+		problems = append(problems, fmt.Sprintf("DB_DRIVER %q must be %s, %s or %s", d.Driver, driverPostgres, driverMySQL, driverSQLite))
+	}
+	return problems
+}
+
+func validatePort(value string) (int, bool) {
+	port, err := strconv.Atoi(value)
+	return port, err == nil && port > 0 && port <= 65535
+}
+
+// usesGitHub reports whether any enabled source calls the GitHub API.
+func (c *Config) usesGitHub() bool {
+	for _, flag := range []string{flagSourceGitHub, flagSourceDiscussions, flagSourcePullRequests, flagSourceReleases, flagSourceSnapshots, flagSourceCommitStats, flagSourceAdvisories} {
+		if c.Flags.Enabled(flag) {
+			return true
+		}
+	}
+	return false
+}
+
+func validURL(value string) bool {
+	u, err := url.Parse(value)
+	return err == nil && u.Scheme != "" && u.Host != ""
+}
+
+// validOrigin reports whether value is a scheme and host, with an optional
+// port and no path, as sent in the Origin header. The host may start
+// with *. to match its subdomains.
+func validOrigin(value string) bool {
+	u, err := url.Parse(value)
+	return err == nil && u.Scheme != "" && u.Host != "" && u.Path == "" && u.RawQuery == "" && u.User == nil
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// VaultConfig points at a HashiCorp Vault KV secret holding the service
+// credentials. It is used when SECRET_BACKEND=vault, or when Addr and Token
+// are both set and no other backend is selected.
+type VaultConfig struct {
+	Addr       string
+	Token      string
+	SecretPath string
+}
+
+func (v VaultConfig) Enabled() bool {
+	return v.Addr != "" && v.Token != ""
+}
+
+var vaultHTTPClient = &http.Client{Timeout: 10 * time.Second}
+
+// vaultBackend is the SecretBackend for SECRET_BACKEND=vault.
+type vaultBackend struct {
+	vc VaultConfig
+}
+
+func (b vaultBackend) Secrets(ctx context.Context) (map[string]string, error) {
+	return readVaultSecret(ctx, b.vc)
+}
+
+// readVaultSecret fetches a secret from either a KV v1 or KV v2 mount.
+// KV v2 nests the values one level deeper under data.data.
+func readVaultSecret(ctx context.Context, vc VaultConfig) (map[string]string, error) {
+	var payload struct {
+		Data map[string]json.RawMessage `json:"data"`
+	}
+	if err := vaultRequest(ctx, vc, http.MethodGet, "/v1/"+strings.TrimPrefix(vc.SecretPath, "/"), &payload); err != nil {
+		return nil, fmt.Errorf("reading vault secret %s: %w", vc.SecretPath, err)
+	}
+
+	raw := payload.Data
+	if nested, ok := raw["data"]; ok {
+		if err := json.Unmarshal(nested, &raw); err != nil {
+			return nil, fmt.Errorf("decoding vault secret %s: %w", vc.SecretPath, err)
+		}
+	}
+
+	values := make(map[string]string, len(raw))
+	for k, v := range raw {
+		var s string
+		if err := json.Unmarshal(v, &s); err == nil {
+			values[k] = s
+		}
+	}
+	return values, nil
+}
+
+// renewVaultToken keeps the Vault token alive until ctx is cancelled,
+// renewing it when two thirds of its TTL have elapsed. It returns early if
+// the token is not renewable.
+func renewVaultToken(ctx context.Context, vc VaultConfig) {
+	for {
+		var payload struct {
+			Auth struct {
+				LeaseDuration int  `json:"lease_duration"`
+				Renewable     bool `json:"renewable"`
+			} `json:"auth"`
+		}
+
+		wait := time.Minute
+		if err := vaultRequest(ctx, vc, http.MethodPost, "/v1/auth/token/renew-self", &payload); err != nil {
+			log.Printf("Error renewing vault token: %v", err)
+		} else if !payload.Auth.Renewable || payload.Auth.LeaseDuration == 0 {
+			log.Printf("Vault token is not renewable; stopping renewal")
+			return
+		} else {
+			wait = time.Duration(payload.Auth.LeaseDuration) * time.Second * 2 / 3
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+func vaultRequest(ctx context.Context, vc VaultConfig, method, path string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(vc.Addr, "/")+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Vault-Token", vc.Token)
+
+	resp, err := vaultHTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("vault returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return json.Unmarshal(body, out)
+}
@@ -0,0 +1,329 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.mongodb.org/mongo-driver/bson"
+	"gorm.io/gorm"
+)
+
+// Events a webhook can subscribe to.
+const (
+	eventNewPost     = "new_post"
+	eventNewIssue    = "new_issue"
+	eventFetchFailed = "fetch_failed"
+)
+
+var webhookEvents = []string{eventNewPost, eventNewIssue, eventFetchFailed}
+
+// webhookSignatureHeader carries the hex HMAC-SHA256 of the request body,
+// keyed by the webhook's secret, for webhooks that have one.
+const webhookSignatureHeader = "X-Webhook-Signature"
+
+// webhookAttempts bounds the deliveries of an event to one webhook. The
+// wait between attempts starts at webhookRetryDelay and doubles.
+const (
+	webhookAttempts   = 5
+	webhookRetryDelay = 2 * time.Second
+)
+
+var webhookDeliveries = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "myapp_webhook_deliveries_total",
+	Help: "Total number of webhook events delivered or given up on",
+}, []string{"event", "result"})
+
+// Webhook is a subscriber URL that collection passes POST their events
+// to: the posts and issues stored for the first time, and the fetch runs
+// that failed.
+type Webhook struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	URL       string    `json:"url" gorm:"not null"`
+	Events    []string  `json:"events" gorm:"serializer:json"`
+	Secret    string    `json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// webhookEvent is the JSON body POSTed to a webhook.
+type webhookEvent struct {
+	Event     string      `json:"event"`
+	Framework string      `json:"framework"`
+	Time      time.Time   `json:"time"`
+	Data      interface{} `json:"data"`
+}
+
+// unseenFinder is implemented by the storage backends, which can tell
+// which posts and issues of a batch they have not stored yet.
+type unseenFinder interface {
+	unseen(ctx context.Context, items []Item) ([]Item, error)
+}
+
+// unseen returns the posts and issues among items that are not stored,
+// counting those removed by a purge as stored.
+func (s gormStore) unseen(ctx context.Context, items []Item) ([]Item, error) {
+	db := primary(s.db.WithContext(ctx)).Unscoped()
+	var fresh []Item
+	for _, item := range items {
+		var query *gorm.DB
+		switch v := item.(type) {
+		case StackOverflowPost:
+			query = db.Model(&StackOverflowPost{}).Where("site = ? AND question_id = ?", v.Site, v.QuestionID)
+		case GitHubIssue:
+			query = db.Model(&GitHubIssue{}).Where("id = ?", v.ID)
+		default:
+			continue
+		}
+		var n int64
+		if err := query.Count(&n).Error; err != nil {
+			return nil, err
+		}
+		if n == 0 {
+			fresh = append(fresh, item)
+		}
+	}
+	return fresh, nil
+}
+
+// unseen returns the posts and issues among items that have no document
+// yet.
+func (s *mongoStore) unseen(ctx context.Context, items []Item) ([]Item, error) {
+	var fresh []Item
+	for _, item := range items {
+		switch item.(type) {
+		case StackOverflowPost, GitHubIssue:
+		default:
+			continue
+		}
+		collection, id, _ := item.(document).mongoDocument()
+		n, err := s.db.Collection(collection).CountDocuments(ctx, bson.M{"_id": id})
+		if err != nil {
+			return nil, err
+		}
+		if n == 0 {
+			fresh = append(fresh, item)
+		}
+	}
+	return fresh, nil
+}
+
+// webhookDispatcher wraps a storage backend and sends the events of each
+// batch it stores to the webhooks subscribed to them, which are kept in
+// db.
+type webhookDispatcher struct {
+	Store
+	db *gorm.DB
+}
+
+func newWebhookDispatcher(db *gorm.DB, next Store) *webhookDispatcher {
+	return &webhookDispatcher{Store: next, db: db}
+}
+
+// Save stores the items and then notifies the subscribers. A batch with a
+// FetchRun is a fetch run, which failed if the source reported an error or
+// the batch could not be stored.
+func (d *webhookDispatcher) Save(ctx context.Context, items ...Item) error {
+	var hooks []Webhook
+	if err := d.db.WithContext(ctx).Order("id").Find(&hooks).Error; err != nil {
+		logf(ctx, "Error loading webhooks: %v", err)
+		return d.Store.Save(ctx, items...)
+	}
+	subscribed := func(event string) bool {
+		return slices.ContainsFunc(hooks, func(hook Webhook) bool { return slices.Contains(hook.Events, event) })
+	}
+
+	var fresh []Item
+	if finder, ok := d.Store.(unseenFinder); ok && (subscribed(eventNewPost) || subscribed(eventNewIssue)) {
+		var err error
+		if fresh, err = finder.unseen(ctx, items); err != nil {
+			logf(ctx, "Error finding new items for webhooks: %v", err)
+		}
+	}
+
+	saveErr := d.Store.Save(ctx, items...)
+	now := time.Now().UTC()
+	var events []webhookEvent
+	if saveErr == nil {
+		for _, item := range fresh {
+			switch v := item.(type) {
+			case StackOverflowPost:
+				events = append(events, webhookEvent{Event: eventNewPost, Framework: v.Framework, Time: now, Data: v})
+			case GitHubIssue:
+				events = append(events, webhookEvent{Event: eventNewIssue, Framework: v.Framework, Time: now, Data: v})
+			}
+		}
+	}
+	for _, item := range items {
+		run, ok := item.(FetchRun)
+		if !ok {
+			continue
+		}
+		if run.Error == "" && saveErr != nil {
+			run.Error = "storing: " + saveErr.Error()
+		}
+		if run.Error != "" {
+			events = append(events, webhookEvent{Event: eventFetchFailed, Framework: run.Framework, Time: now, Data: run})
+		}
+	}
+
+	for _, ev := range events {
+		for _, hook := range hooks {
+			if slices.Contains(hook.Events, ev.Event) {
+				go deliverWebhook(context.WithoutCancel(ctx), hook, ev)
+			}
+		}
+	}
+	return saveErr
+}
+
+// deliverWebhook POSTs ev to hook, retrying with exponential backoff on
+// network errors, 429 and 5xx responses.
+func deliverWebhook(ctx context.Context, hook Webhook, ev webhookEvent) {
+	body, err := json.Marshal(ev)
+	if err != nil {
+		logf(ctx, "Error encoding %s event for webhook %d: %v", ev.Event, hook.ID, err)
+		return
+	}
+	delay := webhookRetryDelay
+	for attempt := 1; ; attempt++ {
+		retry, err := postWebhook(ctx, hook, body)
+		if err == nil {
+			webhookDeliveries.WithLabelValues(ev.Event, "delivered").Inc()
+			return
+		}
+		if !retry || attempt == webhookAttempts {
+			logf(ctx, "Error delivering %s event to webhook %d after %d attempts: %v", ev.Event, hook.ID, attempt, err)
+			webhookDeliveries.WithLabelValues(ev.Event, "failed").Inc()
+			return
+		}
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+// postWebhook makes one delivery attempt and reports whether a failure is
+// worth retrying.
+func postWebhook(ctx context.Context, hook Webhook, body []byte) (retry bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+	if id := requestIDFrom(ctx); id != "" {
+		req.Header.Set(requestIDHeader, id)
+	}
+	if hook.Secret != "" {
+		mac := hmac.New(sha256.New, []byte(hook.Secret))
+		mac.Write(body)
+		req.Header.Set(webhookSignatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return true, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		retry := resp.StatusCode == fiber.StatusTooManyRequests || resp.StatusCode >= 500
+		return retry, fmt.Errorf("status %d", resp.StatusCode)
+	}
+	return false, nil
+}
+
+// findWebhook looks a webhook up by its ID.
+func findWebhook(db *gorm.DB, id string) (*Webhook, error) {
+	n, err := strconv.ParseUint(id, 10, 0)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusNotFound, fmt.Sprintf("webhook %q not found", id))
+	}
+	var hook Webhook
+	err = db.First(&hook, n).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fiber.NewError(fiber.StatusNotFound, fmt.Sprintf("webhook %q not found", id))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &hook, nil
+}
+
+// listWebhooksHandler serves GET /api/v1/webhooks. Secrets are not
+// returned.
+func listWebhooksHandler(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var hooks []Webhook
+		if err := db.Order("id").Find(&hooks).Error; err != nil {
+			return err
+		}
+		if hooks == nil {
+			hooks = []Webhook{}
+		}
+		return c.JSON(hooks)
+	}
+}
+
+// createWebhookHandler serves POST /api/v1/webhooks, registering the url
+// of the JSON body for its events, a list of new_post, new_issue and
+// fetch_failed. With a secret, deliveries are signed in the
+// X-Webhook-Signature header.
+func createWebhookHandler(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var req struct {
+			URL    string   `json:"url"`
+			Events []string `json:"events"`
+			Secret string   `json:"secret"`
+		}
+		if err := c.BodyParser(&req); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "invalid webhook JSON: "+err.Error())
+		}
+		var problems []string
+		if !validURL(req.URL) || (!strings.HasPrefix(req.URL, "http://") && !strings.HasPrefix(req.URL, "https://")) {
+			problems = append(problems, "url must be an http or https URL")
+		}
+		if len(req.Events) == 0 {
+			problems = append(problems, "events must not be empty")
+		}
+		for _, event := range req.Events {
+			if !slices.Contains(webhookEvents, event) {
+				problems = append(problems, fmt.Sprintf("event %q must be one of %s", event, strings.Join(webhookEvents, ", ")))
+			}
+		}
+		if len(problems) > 0 {
+			return fiber.NewError(fiber.StatusBadRequest, strings.Join(problems, "; "))
+		}
+
+		slices.Sort(req.Events)
+		hook := Webhook{URL: req.URL, Events: slices.Compact(req.Events), Secret: req.Secret}
+		if err := db.Create(&hook).Error; err != nil {
+			return err
+		}
+		return c.Status(fiber.StatusCreated).JSON(hook)
+	}
+}
+
+func deleteWebhookHandler(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		hook, err := findWebhook(db, c.Params("id"))
+		if err != nil {
+			return err
+		}
+		if err := db.Delete(hook).Error; err != nil {
+			return err
+		}
+		return c.SendStatus(fiber.StatusNoContent)
+	}
+}
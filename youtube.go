@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"gorm.io/gorm"
+)
+
+var youtubeAPICalls = promauto.NewCounter(prometheus.CounterOpts{
+	Name: "myapp_youtube_api_calls_total",
+	Help: "Total number of API calls to the YouTube Data API",
+})
+
+const youtubeAPIURL = "https://www.googleapis.com/youtube/v3"
+
+// YouTubeVideo is a video whose metadata matches a framework's search term.
+type YouTubeVideo struct {
+	VideoID      string    `json:"video_id" gorm:"primaryKey"`
+	Framework    string    `json:"framework" gorm:"index"`
+	Title        string    `json:"title"`
+	Description  string    `json:"description"`
+	ChannelID    string    `json:"channel_id"`
+	ChannelTitle string    `json:"channel_title"`
+	ViewCount    int64     `json:"view_count"`
+	LikeCount    int64     `json:"like_count"`
+	CommentCount int64     `json:"comment_count"`
+	PublishedAt  time.Time `json:"published_at"`
+}
+
+// youtubeQuery is the search term used for a framework. Short or ambiguous
+// names (e.g. "Go") can be overridden in the registry.
+func youtubeQuery(framework Framework) string {
+	if framework.YouTubeQuery != "" {
+		return framework.YouTubeQuery
+	}
+	return framework.Name
+}
+
+func init() {
+	registerSource(func(cfg *Config) Source { return youtubeSource{cfg} })
+}
+
+// youtubeSource collects videos matching a framework. It requires
+// YOUTUBE_API_KEY.
+type youtubeSource struct{ cfg *Config }
+
+func (youtubeSource) Name() string { return "youtube" }
+
+func (s youtubeSource) Fetch(ctx context.Context, framework Framework) ([]Item, error) {
+	videos, err := fetchYouTubeVideos(ctx, s.cfg, framework)
+	return asItems(videos), err
+}
+
+// fetchYouTubeVideos returns the newest videos matching the framework's
+// search term, with their view, like and comment counts. A search costs
+// 100 units of the daily API quota; the statistics lookup costs one.
+func fetchYouTubeVideos(ctx context.Context, cfg *Config, framework Framework) ([]YouTubeVideo, error) {
+	if cfg.YouTubeAPIKey == "" {
+		return nil, nil
+	}
+
+	limit := cfg.FetchLimit
+	if limit > 50 {
+		limit = 50
+	}
+	youtubeAPICalls.Inc()
+	var search struct {
+		Items []struct {
+			ID struct {
+				VideoID string `json:"videoId"`
+			} `json:"id"`
+		} `json:"items"`
+	}
+	query := url.Values{
+		"part":       {"id"},
+		"type":       {"video"},
+		"order":      {"date"},
+		"q":          {youtubeQuery(framework)},
+		"maxResults": {strconv.Itoa(limit)},
+		"key":        {cfg.YouTubeAPIKey},
+	}
+	if err := getJSON(ctx, youtubeAPIURL+"/search?"+query.Encode(), nil, &search); err != nil {
+		return nil, err
+	}
+	if len(search.Items) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]string, 0, len(search.Items))
+	for _, item := range search.Items {
+		ids = append(ids, item.ID.VideoID)
+	}
+
+	youtubeAPICalls.Inc()
+	var details struct {
+		Items []struct {
+			ID      string `json:"id"`
+			Snippet struct {
+				Title        string    `json:"title"`
+				Description  string    `json:"description"`
+				ChannelID    string    `json:"channelId"`
+				ChannelTitle string    `json:"channelTitle"`
+				PublishedAt  time.Time `json:"publishedAt"`
+			} `json:"snippet"`
+			// The API returns counts as strings; hidden counts are omitted.
+			Statistics struct {
+				ViewCount    int64 `json:"viewCount,string"`
+				LikeCount    int64 `json:"likeCount,string"`
+				CommentCount int64 `json:"commentCount,string"`
+			} `json:"statistics"`
+		} `json:"items"`
+	}
+	query = url.Values{
+		"part": {"snippet,statistics"},
+		"id":   {strings.Join(ids, ",")},
+		"key":  {cfg.YouTubeAPIKey},
+	}
+	if err := getJSON(ctx, youtubeAPIURL+"/videos?"+query.Encode(), nil, &details); err != nil {
+		return nil, err
+	}
+
+	videos := make([]YouTubeVideo, 0, len(details.Items))
+	for _, v := range details.Items {
+		videos = append(videos, YouTubeVideo{
+			VideoID:      v.ID,
+			Framework:    framework.Name,
+			Title:        v.Snippet.Title,
+			Description:  v.Snippet.Description,
+			ChannelID:    v.Snippet.ChannelID,
+			ChannelTitle: v.Snippet.ChannelTitle,
+			ViewCount:    v.Statistics.ViewCount,
+			LikeCount:    v.Statistics.LikeCount,
+			CommentCount: v.Statistics.CommentCount,
+			PublishedAt:  v.Snippet.PublishedAt,
+		})
+	}
+	return videos, nil
+}
+
+func (video YouTubeVideo) Save(db *gorm.DB) error {
+	return upsert(db, &video)
+}